@@ -0,0 +1,14 @@
+package asset
+
+// Attachment is a document of a car stored off-chain, the ledger only keeps
+// its metadata and the hex SHA-256 of the content
+type Attachment struct {
+	ID          string `json:"id"`
+	CarID       string `json:"carId"`
+	Name        string `json:"name"`
+	ContentType string `json:"contentType"`
+	Size        int64  `json:"size"`
+	SHA256      string `json:"sha256"`
+	AddedBy     string `json:"addedBy"`
+	AddedAt     string `json:"addedAt"`
+}
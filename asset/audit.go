@@ -0,0 +1,70 @@
+package asset
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// AuditOutcomeSuccess is the outcome of a committed transaction, the failed
+// ones are rejected by the peers and never reach the ledger
+const AuditOutcomeSuccess = "success"
+
+// AuditRecord is written by every submitted transaction of the named
+// contracts. Caller is the account of the client, the SHA-256 of its client
+// ID. Only the committed transactions leave a record, so Outcome is always
+// AuditOutcomeSuccess.
+type AuditRecord struct {
+	DocType   string   `json:"docType"`
+	TxID      string   `json:"txId"`
+	Function  string   `json:"function"`
+	Args      []string `json:"args"`
+	CarID     string   `json:"carId,omitempty" metadata:",optional"`
+	CallerMSP string   `json:"callerMsp"`
+	Caller    string   `json:"caller"`
+	Timestamp string   `json:"timestamp"`
+	Outcome   string   `json:"outcome,omitempty" metadata:",optional"`
+}
+
+// AuditQuery filters the audit records, From and To are RFC 3339 times and
+// both are inclusive. Actor is the account of the caller.
+type AuditQuery struct {
+	From  string `json:"from,omitempty" metadata:",optional"`
+	To    string `json:"to,omitempty" metadata:",optional"`
+	Actor string `json:"actor,omitempty" metadata:",optional"`
+	MSP   string `json:"msp,omitempty" metadata:",optional"`
+	CarID string `json:"carId,omitempty" metadata:",optional"`
+}
+
+// Validate ...
+func (q AuditQuery) Validate() error {
+	var from, to time.Time
+	for _, bound := range []struct {
+		name  string
+		value string
+		time  *time.Time
+	}{{"from", q.From, &from}, {"to", q.To, &to}} {
+		if bound.value == "" {
+			continue
+		}
+
+		t, err := time.Parse(time.RFC3339, bound.value)
+		if err != nil {
+			return fmt.Errorf("invalid %s %s, use RFC 3339", bound.name, bound.value)
+		}
+		*bound.time = t
+	}
+
+	if q.From != "" && q.To != "" && from.After(to) {
+		return errors.New("from is after to")
+	}
+
+	return nil
+}
+
+// AuditPage is a page of audit records, oldest first, an empty bookmark
+// means there are no more pages
+type AuditPage struct {
+	Records  []*AuditRecord `json:"records"`
+	Bookmark string         `json:"bookmark"`
+}
@@ -2,8 +2,50 @@ package asset
 
 // CarAsset ...
 type Car struct {
-	ID             string `json:"id"`
-	Brand          string `json:"brand"`
-	Owner          string `json:"owner"`
-	TransfersCount int    `json:"transfersCount"`
+	ID             string  `json:"id"`
+	Brand          string  `json:"brand"`
+	Owner          string  `json:"owner"`
+	Owners         []Share `json:"owners,omitempty" metadata:",optional"`
+	TransfersCount int     `json:"transfersCount"`
+	Year           int     `json:"year,omitempty" metadata:",optional"`
+	Type           string  `json:"type,omitempty" metadata:",optional"`
+	EngineCC       int     `json:"engineCC,omitempty" metadata:",optional"`
+	Axles          int     `json:"axles,omitempty" metadata:",optional"`
+	PayloadKg      int     `json:"payloadKg,omitempty" metadata:",optional"`
+	ActiveLiens    int     `json:"activeLiens,omitempty" metadata:",optional"`
+	Stolen         bool    `json:"stolen,omitempty" metadata:",optional"`
+	StolenReport   string  `json:"stolenReport,omitempty" metadata:",optional"`
+	Revision       int     `json:"revision,omitempty" metadata:",optional"`
+}
+
+// BatchItem reports the validation result of one car in a batch, Exists
+// tells the error is an ID already taken
+type BatchItem struct {
+	Index  int    `json:"index"`
+	ID     string `json:"id"`
+	Error  string `json:"error,omitempty" metadata:",optional"`
+	Exists bool   `json:"exists,omitempty" metadata:",optional"`
+}
+
+// BatchReport reports the result of a batch registration, cars are created
+// only when every item of the batch is valid
+type BatchReport struct {
+	Created bool         `json:"created"`
+	Items   []*BatchItem `json:"items"`
+}
+
+// CarsPage is a page of cars and the bookmark to fetch the next one, an
+// empty bookmark means there are no more pages
+type CarsPage struct {
+	Cars     []*Car `json:"cars"`
+	Bookmark string `json:"bookmark"`
+}
+
+// CarRecord is the car with the ledger transaction that last modified it,
+// BlockNumber is zero when the backend can not resolve it
+type CarRecord struct {
+	Car         *Car   `json:"car"`
+	TxID        string `json:"txId"`
+	Timestamp   string `json:"timestamp"`
+	BlockNumber uint64 `json:"blockNumber,omitempty" metadata:",optional"`
 }
@@ -0,0 +1,22 @@
+package asset
+
+// Import job statuses
+const (
+	JobPending   = "pending"
+	JobRunning   = "running"
+	JobCompleted = "completed"
+	JobFailed    = "failed"
+)
+
+// ImportJob is the status resource of a car import
+type ImportJob struct {
+	ID        string         `json:"id"`
+	Status    string         `json:"status"`
+	Total     int            `json:"total"`
+	Batches   int            `json:"batches"`
+	Processed int            `json:"processed"`
+	Created   int            `json:"created"`
+	Rejected  int            `json:"rejected"`
+	Reports   []*BatchReport `json:"reports"`
+	Error     string         `json:"error,omitempty"`
+}
@@ -0,0 +1,19 @@
+package asset
+
+// Lien statuses
+const (
+	LienActive   = "active"
+	LienReleased = "released"
+)
+
+// Lien is a financing encumbrance placed by a lender on a car, the amount is
+// in the smallest currency unit
+type Lien struct {
+	ID         string `json:"id"`
+	CarID      string `json:"carId"`
+	Lender     string `json:"lender"`
+	Amount     int64  `json:"amount"`
+	Status     string `json:"status"`
+	PlacedAt   string `json:"placedAt"`
+	ReleasedAt string `json:"releasedAt,omitempty" metadata:",optional"`
+}
@@ -0,0 +1,11 @@
+package asset
+
+// OdometerReading is a mileage reading of a car, the readings of a car never
+// decrease
+type OdometerReading struct {
+	CarID      string `json:"carId"`
+	Km         int    `json:"km"`
+	Source     string `json:"source"`
+	RecordedAt string `json:"recordedAt"`
+	TxID       string `json:"txId"`
+}
@@ -0,0 +1,67 @@
+package asset
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Owner types
+const (
+	OwnerPerson  = "person"
+	OwnerCompany = "company"
+)
+
+// Owner is a registered party cars can belong to. Only the pseudonymous ID
+// is public, the personal data is kept in the OwnerPII private data
+// collection. IdentityHash is the salted hash of the client ID of the Fabric
+// identity allowed to update the owner, empty when the owner is not linked
+// to one.
+type Owner struct {
+	ID           string `json:"id"`
+	Type         string `json:"type"`
+	IdentityHash string `json:"identityHash,omitempty" metadata:",optional"`
+	Erased       bool   `json:"erased,omitempty" metadata:",optional"`
+}
+
+// OwnerPII is the personal data of an owner, Identity is the client ID the
+// owner is linked to
+type OwnerPII struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Identity string `json:"identity,omitempty" metadata:",optional"`
+}
+
+// OwnerRegistration is the public and the personal data of an owner, as
+// received to create or update it
+type OwnerRegistration struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Identity string `json:"identity,omitempty" metadata:",optional"`
+}
+
+// Validate ...
+func (o Owner) Validate() error {
+	if strings.TrimSpace(o.ID) == "" {
+		return fmt.Errorf("the owner id is required")
+	}
+
+	return validOwnerType(o.Type)
+}
+
+// Validate ...
+func (o OwnerRegistration) Validate() error {
+	if strings.TrimSpace(o.ID) == "" || strings.TrimSpace(o.Name) == "" {
+		return fmt.Errorf("the owner id and name are required")
+	}
+
+	return validOwnerType(o.Type)
+}
+
+func validOwnerType(ownerType string) error {
+	if ownerType != OwnerPerson && ownerType != OwnerCompany {
+		return fmt.Errorf("unknown owner type %q, use %s or %s", ownerType, OwnerPerson, OwnerCompany)
+	}
+
+	return nil
+}
@@ -0,0 +1,74 @@
+package asset
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Share is the percentage of a car held by a co-owner
+type Share struct {
+	Owner   string `json:"owner"`
+	Percent int    `json:"percent"`
+}
+
+// TransferApproval is the consent of a co-owner to transfer the car to
+// NewOwner, it is only valid at the car revision it was given
+type TransferApproval struct {
+	CarID      string `json:"carId"`
+	Owner      string `json:"owner"`
+	NewOwner   string `json:"newOwner"`
+	Revision   int    `json:"revision"`
+	ApprovedBy string `json:"approvedBy,omitempty" metadata:",optional"`
+	ApprovedAt string `json:"approvedAt"`
+}
+
+// ValidateShares checks the shares of a co-owned car sum 100% and include
+// the owner, a car without shares has a single owner
+func (c Car) ValidateShares() error {
+	if len(c.Owners) == 0 {
+		return nil
+	}
+
+	total := 0
+	seen := map[string]bool{}
+	for _, share := range c.Owners {
+		if strings.TrimSpace(share.Owner) == "" {
+			return fmt.Errorf("every share requires an owner")
+		}
+
+		if share.Percent <= 0 || share.Percent > 100 {
+			return fmt.Errorf("the share of %s must be between 1 and 100 percent", share.Owner)
+		}
+
+		if seen[share.Owner] {
+			return fmt.Errorf("%s holds more than one share", share.Owner)
+		}
+		seen[share.Owner] = true
+		total += share.Percent
+	}
+
+	if total != 100 {
+		return fmt.Errorf("the shares sum %d%%, they must sum 100%%", total)
+	}
+
+	if !seen[c.Owner] {
+		return fmt.Errorf("the owner %s does not hold a share", c.Owner)
+	}
+
+	return nil
+}
+
+// HeldBy reports whether the party owns the car or holds a share of it
+func (c Car) HeldBy(party string) bool {
+	if c.Owner == party {
+		return true
+	}
+
+	for _, share := range c.Owners {
+		if share.Owner == party {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,48 @@
+package asset
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// CarQuery holds the criteria accepted to search cars, only these fields can
+// be turned into a CouchDB selector
+type CarQuery struct {
+	Brand        string `json:"brand,omitempty" metadata:",optional"`
+	Owner        string `json:"owner,omitempty" metadata:",optional"`
+	Type         string `json:"type,omitempty" metadata:",optional"`
+	MinTransfers *int   `json:"minTransfers,omitempty" metadata:",optional"`
+	MaxTransfers *int   `json:"maxTransfers,omitempty" metadata:",optional"`
+	MinYear      *int   `json:"minYear,omitempty" metadata:",optional"`
+	MaxYear      *int   `json:"maxYear,omitempty" metadata:",optional"`
+}
+
+// Validate ...
+func (q CarQuery) Validate() error {
+	if strings.TrimSpace(q.Brand) == "" && strings.TrimSpace(q.Owner) == "" && q.Type == "" &&
+		q.MinTransfers == nil && q.MaxTransfers == nil &&
+		q.MinYear == nil && q.MaxYear == nil {
+		return errors.New("supply at least one search criterion")
+	}
+
+	if q.Type != "" && !ValidVehicleType(q.Type) {
+		return fmt.Errorf("unknown vehicle type %q", q.Type)
+	}
+
+	for _, v := range []*int{q.MinTransfers, q.MaxTransfers, q.MinYear, q.MaxYear} {
+		if v != nil && *v < 0 {
+			return errors.New("ranges can not be negative")
+		}
+	}
+
+	if q.MinTransfers != nil && q.MaxTransfers != nil && *q.MinTransfers > *q.MaxTransfers {
+		return errors.New("minTransfers is greater than maxTransfers")
+	}
+
+	if q.MinYear != nil && q.MaxYear != nil && *q.MinYear > *q.MaxYear {
+		return errors.New("minYear is greater than maxYear")
+	}
+
+	return nil
+}
@@ -0,0 +1,49 @@
+package asset
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Service record types
+const (
+	RecordMaintenance = "maintenance"
+	RecordRepair      = "repair"
+	RecordInspection  = "inspection"
+)
+
+// RecordDateLayout is the layout of the service record dates
+const RecordDateLayout = "2006-01-02"
+
+// ServiceRecord is a service or inspection logged by a workshop. The document
+// itself stays off-chain, DocumentHash is its hex SHA-256.
+type ServiceRecord struct {
+	ID           string `json:"id"`
+	CarID        string `json:"carId"`
+	Workshop     string `json:"workshop"`
+	Date         string `json:"date"`
+	Type         string `json:"type"`
+	DocumentHash string `json:"documentHash"`
+	RecordedAt   string `json:"recordedAt,omitempty" metadata:",optional"`
+}
+
+// Validate checks the fields supplied by the workshop
+func (r ServiceRecord) Validate() error {
+	switch r.Type {
+	case RecordMaintenance, RecordRepair, RecordInspection:
+	default:
+		return fmt.Errorf("unknown record type %q, use %s, %s or %s", r.Type, RecordMaintenance, RecordRepair, RecordInspection)
+	}
+
+	if _, err := time.Parse(RecordDateLayout, r.Date); err != nil {
+		return fmt.Errorf("the date %q is not in the YYYY-MM-DD format", r.Date)
+	}
+
+	if hash, err := hex.DecodeString(r.DocumentHash); err != nil || len(hash) != 32 {
+		return errors.New("the document hash must be a hex SHA-256")
+	}
+
+	return nil
+}
@@ -0,0 +1,21 @@
+package asset
+
+// Sale is a car purchased with tokens, the payment and the transfer are in
+// the same transaction
+type Sale struct {
+	CarID  string `json:"carId"`
+	Seller string `json:"seller"`
+	Buyer  string `json:"buyer"`
+	Price  int64  `json:"price"`
+	TxID   string `json:"txId"`
+	SoldAt string `json:"soldAt"`
+}
+
+// Offer is a car put on sale by its owner, an empty buyer accepts any buyer
+type Offer struct {
+	CarID     string `json:"carId"`
+	Seller    string `json:"seller"`
+	Buyer     string `json:"buyer,omitempty" metadata:",optional"`
+	Price     int64  `json:"price"`
+	OfferedAt string `json:"offeredAt"`
+}
@@ -0,0 +1,68 @@
+package asset
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Vehicle types, a Car without type is a car so the records written before
+// the types existed keep their meaning
+const (
+	VehicleCar        = "car"
+	VehicleMotorcycle = "motorcycle"
+	VehicleTruck      = "truck"
+	VehicleTrailer    = "trailer"
+)
+
+// ValidVehicleType ...
+func ValidVehicleType(vehicleType string) bool {
+	switch vehicleType {
+	case VehicleCar, VehicleMotorcycle, VehicleTruck, VehicleTrailer:
+		return true
+	}
+	return false
+}
+
+// VehicleType returns the type of the vehicle, car when it is not set
+func (c Car) VehicleType() string {
+	if c.Type == "" {
+		return VehicleCar
+	}
+	return c.Type
+}
+
+// ValidateAttributes checks the attributes of the vehicle type are set and
+// the ones of the other types are not
+func (c Car) ValidateAttributes() error {
+	switch c.VehicleType() {
+	case VehicleCar:
+		if c.EngineCC != 0 || c.Axles != 0 || c.PayloadKg != 0 {
+			return errors.New("a car does not take engineCC, axles or payloadKg")
+		}
+	case VehicleMotorcycle:
+		if c.EngineCC <= 0 {
+			return errors.New("a motorcycle requires a positive engineCC")
+		}
+		if c.Axles != 0 || c.PayloadKg != 0 {
+			return errors.New("a motorcycle does not take axles or payloadKg")
+		}
+	case VehicleTruck, VehicleTrailer:
+		minAxles := 2
+		if c.Type == VehicleTrailer {
+			minAxles = 1
+		}
+		if c.Axles < minAxles {
+			return fmt.Errorf("a %s requires at least %d axles", c.Type, minAxles)
+		}
+		if c.PayloadKg <= 0 {
+			return fmt.Errorf("a %s requires a positive payloadKg", c.Type)
+		}
+		if c.EngineCC != 0 {
+			return fmt.Errorf("a %s does not take engineCC", c.Type)
+		}
+	default:
+		return fmt.Errorf("unknown vehicle type %q, use %s, %s, %s or %s", c.Type, VehicleCar, VehicleMotorcycle, VehicleTruck, VehicleTrailer)
+	}
+
+	return nil
+}
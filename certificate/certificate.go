@@ -0,0 +1,155 @@
+// Package certificate issues and verifies ownership certificates for cars as
+// JSON-LD verifiable credentials signed with Ed25519.
+package certificate
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/yimialmonte/chaincode-cars/asset"
+)
+
+// ProofType is the type of the proof attached to the certificates. It is
+// specific to this project, the payload is not canonicalized like the W3C
+// Ed25519Signature2020 proofs nor with JCS, see Proof.
+const ProofType = "ChaincodeCarsEd25519Signature"
+
+// Context is the JSON-LD context of the certificates
+var Context = []interface{}{
+	"https://www.w3.org/2018/credentials/v1",
+	map[string]interface{}{
+		"OwnershipCertificate": "urn:chaincode-cars:OwnershipCertificate",
+		"car":                  "urn:chaincode-cars:car",
+		"ledger":               "urn:chaincode-cars:ledger",
+		ProofType:              "urn:chaincode-cars:" + ProofType,
+	},
+}
+
+// Subject is the car the certificate is about
+type Subject struct {
+	ID  string     `json:"id"`
+	Car *asset.Car `json:"car"`
+}
+
+// Ledger identifies the ledger transaction backing the certificate
+type Ledger struct {
+	TxID        string `json:"txId"`
+	BlockNumber uint64 `json:"blockNumber"`
+	Timestamp   string `json:"timestamp,omitempty"`
+}
+
+// Proof is the signature of the certificate. The value signs the
+// certificate without the proof as re-encoded by encoding/json: compact,
+// object keys sorted by bytes, numbers kept verbatim, and <, >, &, U+2028
+// and U+2029 escaped as \u003c, \u003e, \u0026, \u2028 and \u2029. It can
+// be checked without knowing the Go types.
+type Proof struct {
+	Type               string `json:"type"`
+	Created            string `json:"created"`
+	VerificationMethod string `json:"verificationMethod"`
+	ProofPurpose       string `json:"proofPurpose"`
+	ProofValue         string `json:"proofValue"`
+}
+
+// Certificate is an ownership verifiable credential
+type Certificate struct {
+	Context           []interface{} `json:"@context"`
+	ID                string        `json:"id"`
+	Type              []string      `json:"type"`
+	Issuer            string        `json:"issuer"`
+	IssuanceDate      string        `json:"issuanceDate"`
+	CredentialSubject Subject       `json:"credentialSubject"`
+	Ledger            Ledger        `json:"ledger"`
+	Proof             *Proof        `json:"proof,omitempty"`
+}
+
+// Issuer signs certificates with its private key
+type Issuer struct {
+	ID    string
+	KeyID string
+	Key   ed25519.PrivateKey
+	Now   func() time.Time
+}
+
+// NewIssuer ...
+func NewIssuer(id string, key ed25519.PrivateKey) *Issuer {
+	return &Issuer{ID: id, KeyID: KeyID(key.Public().(ed25519.PublicKey)), Key: key, Now: time.Now}
+}
+
+// KeyID derives the identifier of a public key from its hash
+func KeyID(pub ed25519.PublicKey) string {
+	sum := sha256.Sum256(pub)
+	return "ed25519:" + base64.RawURLEncoding.EncodeToString(sum[:12])
+}
+
+// Issue builds and signs the certificate of the car record
+func (i *Issuer) Issue(record *asset.CarRecord) (*Certificate, error) {
+	if record == nil || record.Car == nil {
+		return nil, errors.New("the car record is required")
+	}
+
+	now := i.Now().UTC().Format(time.RFC3339)
+	cert := &Certificate{
+		Context:      Context,
+		ID:           "urn:chaincode-cars:certificate:" + record.Car.ID + ":" + record.TxID,
+		Type:         []string{"VerifiableCredential", "OwnershipCertificate"},
+		Issuer:       i.ID,
+		IssuanceDate: now,
+		CredentialSubject: Subject{
+			ID:  "urn:chaincode-cars:car:" + record.Car.ID,
+			Car: record.Car,
+		},
+		Ledger: Ledger{
+			TxID:        record.TxID,
+			BlockNumber: record.BlockNumber,
+			Timestamp:   record.Timestamp,
+		},
+	}
+
+	payload, err := signingPayload(cert)
+	if err != nil {
+		return nil, err
+	}
+
+	cert.Proof = &Proof{
+		Type:               ProofType,
+		Created:            now,
+		VerificationMethod: i.ID + "#" + i.KeyID,
+		ProofPurpose:       "assertionMethod",
+		ProofValue:         base64.RawURLEncoding.EncodeToString(ed25519.Sign(i.Key, payload)),
+	}
+
+	return cert, nil
+}
+
+// signingPayload is the canonical serialization of the certificate without
+// the proof
+func signingPayload(cert *Certificate) ([]byte, error) {
+	unsigned := *cert
+	unsigned.Proof = nil
+
+	certJSON, err := json.Marshal(unsigned)
+	if err != nil {
+		return nil, err
+	}
+
+	return canonical(certJSON)
+}
+
+// canonical re-encodes a JSON document the way Proof describes
+func canonical(doc []byte) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(doc))
+	decoder.UseNumber()
+
+	var v interface{}
+	if err := decoder.Decode(&v); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(v)
+}
@@ -0,0 +1,147 @@
+package certificate
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yimialmonte/chaincode-cars/asset"
+)
+
+func testIssuer(t *testing.T) *Issuer {
+	seed := bytes.Repeat([]byte{7}, ed25519.SeedSize)
+	issuer := NewIssuer("https://cars.example.com", ed25519.NewKeyFromSeed(seed))
+	issuer.Now = func() time.Time { return time.Date(2021, 9, 21, 15, 0, 0, 0, time.UTC) }
+	return issuer
+}
+
+func TestIssue(t *testing.T) {
+	issuer := testIssuer(t)
+	record := &asset.CarRecord{
+		Car:         &asset.Car{ID: "12", Brand: "Toyota", Owner: "Juan (senior)", TransfersCount: 1, Revision: 1},
+		TxID:        "abc",
+		Timestamp:   "2021-09-20T10:00:00Z",
+		BlockNumber: 9007199254740993,
+	}
+
+	cert, err := issuer.Issue(record)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"VerifiableCredential", "OwnershipCertificate"}, cert.Type)
+	assert.Equal(t, "urn:chaincode-cars:car:12", cert.CredentialSubject.ID)
+	assert.Equal(t, "2021-09-21T15:00:00Z", cert.IssuanceDate)
+	assert.Equal(t, uint64(9007199254740993), cert.Ledger.BlockNumber)
+	assert.Equal(t, "https://cars.example.com#"+issuer.KeyID, cert.Proof.VerificationMethod)
+	assert.Equal(t, "ChaincodeCarsEd25519Signature", cert.Proof.Type)
+
+	certJSON, err := json.Marshal(cert)
+	require.NoError(t, err)
+
+	var doc map[string]interface{}
+	decoder := json.NewDecoder(bytes.NewReader(certJSON))
+	decoder.UseNumber()
+	require.NoError(t, decoder.Decode(&doc))
+	delete(doc, "proof")
+	payload, err := json.Marshal(doc)
+	require.NoError(t, err)
+
+	sig, err := base64.RawURLEncoding.DecodeString(cert.Proof.ProofValue)
+	require.NoError(t, err)
+	assert.True(t, ed25519.Verify(issuer.Key.Public().(ed25519.PublicKey), payload, sig))
+
+	_, err = issuer.Issue(&asset.CarRecord{})
+	assert.EqualError(t, err, "the car record is required")
+}
+
+func TestCanonical(t *testing.T) {
+	doc, err := canonical([]byte(`{"owner": "Ana <b> & co\u2028", "year": 2018.0, "brand": "Kia", "Brand": "K"}`))
+	require.NoError(t, err)
+	assert.Equal(t, `{"Brand":"K","brand":"Kia","owner":"Ana \u003cb\u003e \u0026 co\u2028","year":2018.0}`, string(doc))
+}
+
+func TestRenderPDF(t *testing.T) {
+	cert, err := testIssuer(t).Issue(&asset.CarRecord{
+		Car:  &asset.Car{ID: "12", Brand: "Toyota", Owner: "Juan (senior) Núñez"},
+		TxID: "abc",
+	})
+	require.NoError(t, err)
+
+	var doc bytes.Buffer
+	require.NoError(t, RenderPDF(&doc, cert))
+
+	pdf := doc.String()
+	assert.True(t, len(pdf) > 0)
+	assert.Contains(t, pdf, "%PDF-1.4")
+	assert.Contains(t, pdf, `(Owner: Juan \(senior\) N??ez) Tj`)
+	assert.Contains(t, pdf, "(Ledger transaction: abc) Tj")
+	assert.Contains(t, pdf, "%%EOF")
+}
+
+func TestVerify(t *testing.T) {
+	issuer := testIssuer(t)
+	cert, err := issuer.Issue(&asset.CarRecord{
+		Car:         &asset.Car{ID: "12", Brand: "Toyota", Owner: "Juan", TransfersCount: 1},
+		TxID:        "abc",
+		BlockNumber: 9007199254740993,
+	})
+	require.NoError(t, err)
+
+	certJSON, err := json.Marshal(cert)
+	require.NoError(t, err)
+
+	other := ed25519.NewKeyFromSeed(bytes.Repeat([]byte{9}, ed25519.SeedSize))
+	pub := issuer.Key.Public().(ed25519.PublicKey)
+
+	tests := []struct {
+		name        string
+		doc         []byte
+		key         ed25519.PublicKey
+		expectedErr error
+	}{
+		{"valid", certJSON, pub, nil},
+		{"tampered owner", bytes.Replace(certJSON, []byte(`"owner":"Juan"`), []byte(`"owner":"Max"`), 1), pub, ErrInvalidSignature},
+		{"tampered block", bytes.Replace(certJSON, []byte(`9007199254740993`), []byte(`9007199254740992`), 1), pub, ErrInvalidSignature},
+		{"added field", bytes.Replace(certJSON, []byte(`"issuer":`), []byte(`"extra":1,"issuer":`), 1), pub, ErrInvalidSignature},
+		{"other key", certJSON, other.Public().(ed25519.PublicKey), ErrUnknownKey},
+		{"no proof", bytes.Replace(certJSON, []byte(`"proof"`), []byte(`"proof_"`), 1), pub, ErrNoProof},
+		{"not json", []byte(`{`), pub, ErrMalformedDocument},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			verified, err := Verify(test.doc, test.key)
+			if test.expectedErr != nil {
+				assert.True(t, errors.Is(err, test.expectedErr), "got %v", err)
+				assert.Nil(t, verified)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, cert.CredentialSubject.Car, verified.CredentialSubject.Car)
+		})
+	}
+}
+
+func TestCompare(t *testing.T) {
+	cert := &Certificate{CredentialSubject: Subject{Car: &asset.Car{ID: "12", Owner: "Juan", TransfersCount: 1}}}
+
+	tests := []struct {
+		car      *asset.Car
+		expected Status
+	}{
+		{&asset.Car{ID: "12", Owner: "Juan", TransfersCount: 1, Revision: 3}, Status{true, true, true}},
+		{&asset.Car{ID: "12", Owner: "Max", TransfersCount: 2}, Status{false, false, false}},
+		{&asset.Car{ID: "12", Owner: "Juan", TransfersCount: 3}, Status{false, true, false}},
+		{nil, Status{}},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.expected, Compare(cert, test.car))
+	}
+}
@@ -0,0 +1,46 @@
+package certificate
+
+import (
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+)
+
+// LoadKey reads an Ed25519 private key from a PKCS #8 PEM file
+func LoadKey(path string) (ed25519.PrivateKey, error) {
+	keyPEM, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(keyPEM)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM block in %s", path)
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	edKey, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, errors.New("the signing key is not an Ed25519 key")
+	}
+
+	return edKey, nil
+}
+
+// EncodePublicKey returns the PKIX PEM encoding of a public key, to be
+// shared with the parties verifying the certificates
+func EncodePublicKey(pub ed25519.PublicKey) ([]byte, error) {
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return nil, err
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), nil
+}
@@ -0,0 +1,107 @@
+package certificate
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// RenderPDF writes a one page printable version of the certificate
+func RenderPDF(w io.Writer, cert *Certificate) error {
+	car := cert.CredentialSubject.Car
+	if car == nil {
+		return fmt.Errorf("the certificate has no car")
+	}
+
+	lines := []string{
+		"Certificate of Ownership",
+		"",
+		"Car ID: " + car.ID,
+		"Brand: " + car.Brand,
+		"Owner: " + car.Owner,
+		"Transfers: " + strconv.Itoa(car.TransfersCount),
+	}
+
+	if car.Year > 0 {
+		lines = append(lines, "Year: "+strconv.Itoa(car.Year))
+	}
+
+	lines = append(lines,
+		"",
+		"Ledger transaction: "+cert.Ledger.TxID,
+		"Block number: "+strconv.FormatUint(cert.Ledger.BlockNumber, 10),
+		"Recorded at: "+cert.Ledger.Timestamp,
+		"",
+		"Issuer: "+cert.Issuer,
+		"Issued at: "+cert.IssuanceDate,
+		"Certificate: "+cert.ID,
+	)
+
+	if cert.Proof != nil {
+		lines = append(lines, "Key: "+cert.Proof.VerificationMethod, "Signature:")
+		for sig := cert.Proof.ProofValue; sig != ""; {
+			n := 64
+			if len(sig) < n {
+				n = len(sig)
+			}
+			lines = append(lines, "  "+sig[:n])
+			sig = sig[n:]
+		}
+	}
+
+	var content bytes.Buffer
+	content.WriteString("BT\n/F1 18 Tf\n50 780 Td\n22 TL\n")
+	for i, line := range lines {
+		if i == 1 {
+			content.WriteString("/F1 10 Tf\n14 TL\n")
+		}
+		fmt.Fprintf(&content, "(%s) Tj T*\n", pdfEscape(line))
+	}
+	content.WriteString("ET\n")
+
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 595 842] /Contents 4 0 R /Resources << /Font << /F1 5 0 R >> >> >>",
+		fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", content.Len(), content.String()),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>",
+	}
+
+	var doc bytes.Buffer
+	doc.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = doc.Len()
+		fmt.Fprintf(&doc, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+
+	xref := doc.Len()
+	fmt.Fprintf(&doc, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&doc, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&doc, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+
+	_, err := w.Write(doc.Bytes())
+	return err
+}
+
+// pdfEscape escapes a string literal, characters out of ASCII are replaced
+// since the standard font has no encoding for them
+func pdfEscape(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteRune('\\')
+			b.WriteRune(r)
+		case r < 32 || r > 126:
+			b.WriteRune('?')
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
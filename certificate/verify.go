@@ -0,0 +1,96 @@
+package certificate
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/yimialmonte/chaincode-cars/asset"
+)
+
+// Verification errors
+var (
+	ErrNoProof           = errors.New("the certificate has no proof")
+	ErrUnsupportedProof  = errors.New("unsupported proof type")
+	ErrUnknownKey        = errors.New("the certificate was signed with an unknown key")
+	ErrInvalidSignature  = errors.New("invalid certificate signature")
+	ErrMalformedDocument = errors.New("malformed certificate")
+)
+
+// Verify checks the signature of a certificate as received, the document is
+// canonicalized from its raw JSON so fields unknown to this package are
+// covered by the signature too
+func Verify(certJSON []byte, pub ed25519.PublicKey) (*Certificate, error) {
+	var cert Certificate
+	if err := json.Unmarshal(certJSON, &cert); err != nil {
+		return nil, fmt.Errorf("%w, %v", ErrMalformedDocument, err)
+	}
+
+	if cert.Proof == nil {
+		return nil, ErrNoProof
+	}
+
+	if cert.Proof.Type != ProofType {
+		return nil, fmt.Errorf("%w %s", ErrUnsupportedProof, cert.Proof.Type)
+	}
+
+	if !strings.HasSuffix(cert.Proof.VerificationMethod, "#"+KeyID(pub)) {
+		return nil, ErrUnknownKey
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(cert.Proof.ProofValue)
+	if err != nil {
+		return nil, ErrInvalidSignature
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(certJSON))
+	decoder.UseNumber()
+
+	var doc map[string]interface{}
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("%w, %v", ErrMalformedDocument, err)
+	}
+	delete(doc, "proof")
+
+	payload, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	if !ed25519.Verify(pub, payload, sig) {
+		return nil, ErrInvalidSignature
+	}
+
+	if cert.CredentialSubject.Car == nil {
+		return nil, fmt.Errorf("%w, the certificate has no car", ErrMalformedDocument)
+	}
+
+	return &cert, nil
+}
+
+// Status compares a verified certificate with the current state of the car
+type Status struct {
+	Current               bool `json:"current"`
+	OwnerMatches          bool `json:"ownerMatches"`
+	TransfersCountMatches bool `json:"transfersCountMatches"`
+}
+
+// Compare tells whether the certificate still describes the car, a nil car
+// means it no longer exists
+func Compare(cert *Certificate, car *asset.Car) Status {
+	certified := cert.CredentialSubject.Car
+	if car == nil || certified == nil || car.ID != certified.ID {
+		return Status{}
+	}
+
+	status := Status{
+		OwnerMatches:          car.Owner == certified.Owner,
+		TransfersCountMatches: car.TransfersCount == certified.TransfersCount,
+	}
+	status.Current = status.OwnerMatches && status.TransfersCountMatches
+	return status
+}
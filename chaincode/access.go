@@ -0,0 +1,64 @@
+package chaincode
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// roleAttribute is the identity attribute, set when enrolling with the
+// Fabric CA, holding the role of the caller
+const roleAttribute = "role"
+
+// ErrAccessDenied is returned when the caller is not allowed to submit the
+// transaction
+var ErrAccessDenied = errors.New("access denied")
+
+// callerMSP returns the MSP ID of the client submitting the transaction
+func callerMSP(ctx contractapi.TransactionContextInterface) (string, error) {
+	if ctx.GetClientIdentity() == nil {
+		return "", fmt.Errorf("%w, the caller identity is unknown", ErrAccessDenied)
+	}
+
+	return ctx.GetClientIdentity().GetMSPID()
+}
+
+// requireMSP checks the caller belongs to one of the organizations
+func requireMSP(ctx contractapi.TransactionContextInterface, allowed []string) (string, error) {
+	mspID, err := callerMSP(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	for _, id := range allowed {
+		if id == mspID {
+			return mspID, nil
+		}
+	}
+
+	return "", fmt.Errorf("%w, organization %s is not allowed", ErrAccessDenied, mspID)
+}
+
+// requireRole checks the role attribute of the caller and returns its MSP ID
+func requireRole(ctx contractapi.TransactionContextInterface, role string) (string, error) {
+	mspID, err := callerMSP(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	if err := ctx.GetClientIdentity().AssertAttributeValue(roleAttribute, role); err != nil {
+		return "", fmt.Errorf("%w, the caller does not have the %s role", ErrAccessDenied, role)
+	}
+
+	return mspID, nil
+}
+
+// callerID returns the client ID of the identity submitting the transaction
+func callerID(ctx contractapi.TransactionContextInterface) (string, error) {
+	if ctx.GetClientIdentity() == nil {
+		return "", fmt.Errorf("%w, the caller identity is unknown", ErrAccessDenied)
+	}
+
+	return ctx.GetClientIdentity().GetID()
+}
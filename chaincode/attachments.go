@@ -0,0 +1,122 @@
+package chaincode
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/yimialmonte/chaincode-cars/asset"
+)
+
+// attachmentIndex stores the attachments under car~attachment composite keys
+const attachmentIndex = "car~attachment"
+
+// AnchorAttachment records the hash of a document stored off-chain, so its
+// integrity can be checked against the ledger
+func (s *SmartContract) AnchorAttachment(ctx contractapi.TransactionContextInterface, carID, name, contentType string, size int64, sha256 string) (*asset.Attachment, error) {
+	mspID, err := callerMSP(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.TrimSpace(name) == "" {
+		return nil, fmt.Errorf("the attachment name is required")
+	}
+
+	if size < 0 {
+		return nil, fmt.Errorf("the attachment size can not be negative")
+	}
+
+	if hash, err := hex.DecodeString(sha256); err != nil || len(hash) != 32 {
+		return nil, fmt.Errorf("the attachment hash must be a hex SHA-256")
+	}
+
+	exist, err := s.ExistCar(ctx, carID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !exist {
+		return nil, fmt.Errorf("car does not exist ID: %s", carID)
+	}
+
+	now, err := txTime(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	attachment := &asset.Attachment{
+		ID:          ctx.GetStub().GetTxID(),
+		CarID:       carID,
+		Name:        name,
+		ContentType: contentType,
+		Size:        size,
+		SHA256:      strings.ToLower(sha256),
+		AddedBy:     mspID,
+		AddedAt:     now,
+	}
+
+	key, err := ctx.GetStub().CreateCompositeKey(attachmentIndex, []string{carID, attachment.ID})
+	if err != nil {
+		return nil, err
+	}
+
+	attachmentJSON, err := json.Marshal(attachment)
+	if err != nil {
+		return nil, err
+	}
+
+	return attachment, ctx.GetStub().PutState(key, attachmentJSON)
+}
+
+// GetAttachment ...
+func (s *SmartContract) GetAttachment(ctx contractapi.TransactionContextInterface, carID, id string) (*asset.Attachment, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(attachmentIndex, []string{carID, id})
+	if err != nil {
+		return nil, err
+	}
+
+	attachmentJSON, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if attachmentJSON == nil {
+		return nil, fmt.Errorf("attachment does not exist ID: %s", id)
+	}
+
+	var attachment asset.Attachment
+	if err := json.Unmarshal(attachmentJSON, &attachment); err != nil {
+		return nil, err
+	}
+
+	return &attachment, nil
+}
+
+// GetAttachments ...
+func (s *SmartContract) GetAttachments(ctx contractapi.TransactionContextInterface, carID string) ([]*asset.Attachment, error) {
+	res, err := ctx.GetStub().GetStateByPartialCompositeKey(attachmentIndex, []string{carID})
+	if err != nil {
+		return nil, err
+	}
+	defer res.Close()
+
+	attachments := []*asset.Attachment{}
+	for res.HasNext() {
+		kv, err := res.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		var attachment asset.Attachment
+		if err := json.Unmarshal(kv.Value, &attachment); err != nil {
+			return nil, err
+		}
+
+		attachments = append(attachments, &attachment)
+	}
+
+	return attachments, nil
+}
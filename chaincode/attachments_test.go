@@ -0,0 +1,60 @@
+package chaincode
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yimialmonte/chaincode-cars/asset"
+	"github.com/yimialmonte/chaincode-cars/chaincode/memstub"
+)
+
+func TestAttachments(t *testing.T) {
+	ledger := memstub.New(memstub.WithCreator(identity(t, "Org1MSP", nil)))
+	sc := &SmartContract{}
+	require.NoError(t, ledger.Run(sc.InitLedger))
+
+	tests := []struct {
+		carID       string
+		name        string
+		size        int64
+		hash        string
+		expectedErr string
+	}{
+		{"99", "invoice.pdf", 10, invoiceHash, "car does not exist ID: 99"},
+		{"12", " ", 10, invoiceHash, "the attachment name is required"},
+		{"12", "invoice.pdf", -1, invoiceHash, "the attachment size can not be negative"},
+		{"12", "invoice.pdf", 10, "zz", "the attachment hash must be a hex SHA-256"},
+	}
+
+	for _, test := range tests {
+		err := ledger.Run(func(ctx contractapi.TransactionContextInterface) error {
+			_, err := sc.AnchorAttachment(ctx, test.carID, test.name, "application/pdf", test.size, test.hash)
+			return err
+		})
+		assert.EqualError(t, err, test.expectedErr)
+	}
+
+	var anchored *asset.Attachment
+	require.NoError(t, ledger.Run(func(ctx contractapi.TransactionContextInterface) (err error) {
+		anchored, err = sc.AnchorAttachment(ctx, "12", "invoice.pdf", "application/pdf", 10, invoiceHash)
+		return err
+	}))
+	assert.Equal(t, "Org1MSP", anchored.AddedBy)
+	assert.Equal(t, "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08", anchored.SHA256)
+
+	require.NoError(t, ledger.Run(func(ctx contractapi.TransactionContextInterface) error {
+		attachment, err := sc.GetAttachment(ctx, "12", anchored.ID)
+		require.NoError(t, err)
+		assert.Equal(t, anchored, attachment)
+
+		_, err = sc.GetAttachment(ctx, "22", anchored.ID)
+		assert.EqualError(t, err, "attachment does not exist ID: "+anchored.ID)
+
+		attachments, err := sc.GetAttachments(ctx, "12")
+		require.NoError(t, err)
+		assert.Equal(t, []*asset.Attachment{anchored}, attachments)
+		return nil
+	}))
+}
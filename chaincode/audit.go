@@ -0,0 +1,254 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/yimialmonte/chaincode-cars/asset"
+)
+
+// auditIndex stores the audit records under audit~txid composite keys
+const auditIndex = "audit~txid"
+
+// auditDocType tells the audit records apart in rich queries
+const auditDocType = "audit"
+
+// auditorRole is the role attribute of the callers allowed to query the
+// audit records
+const auditorRole = "auditor"
+
+// maxAuditPageSize caps the audit records returned per query
+const maxAuditPageSize = 1000
+
+// auditCarFunctions are the functions taking the car ID as first argument,
+// their audit records are indexed by car
+var auditCarFunctions = map[string]bool{
+	"GetCar": true, "CreateCar": true, "ExistCar": true, "GetCarRecord": true, "GetCarHistory": true,
+	"TransferCart": true, "TransferCarIfMatch": true, "ApproveTransfer": true, "ApproveTransferIfMatch": true, "GetTransferApprovals": true, "OfferCar": true, "PurchaseCar": true,
+	"PlaceLien": true, "PlaceLienIfMatch": true, "ReleaseLien": true, "ReleaseLienIfMatch": true, "GetLien": true, "GetLiens": true,
+	"ReportStolen": true, "ReportRecovered": true,
+	"RecordOdometer": true, "GetOdometerReadings": true,
+	"AddServiceRecord": true, "GetServiceRecords": true,
+	"AnchorAttachment": true, "GetAttachment": true, "GetAttachments": true,
+	"ReadCar": true, "CarExists": true, "IsTransferable": true,
+}
+
+// TransactionContextInterface is the context of the named contracts
+type TransactionContextInterface interface {
+	contractapi.TransactionContextInterface
+	GetAuditRecord() *asset.AuditRecord
+	SetAuditRecord(record *asset.AuditRecord)
+}
+
+// TransactionContext carries the audit record of the transaction from
+// BeforeTransaction to AfterTransaction
+type TransactionContext struct {
+	contractapi.TransactionContext
+	audit *asset.AuditRecord
+}
+
+// GetAuditRecord ...
+func (t *TransactionContext) GetAuditRecord() *asset.AuditRecord {
+	return t.audit
+}
+
+// SetAuditRecord ...
+func (t *TransactionContext) SetAuditRecord(record *asset.AuditRecord) {
+	t.audit = record
+}
+
+// auditContract sets the context and the audit handlers of a named contract,
+// the evaluated transactions are not audited since they are never ordered
+// and a write would make a read submitted by mistake change the ledger
+func auditContract(contract *contractapi.Contract, transactions, evaluate []string) {
+	evaluated := make(map[string]bool, len(evaluate))
+	for _, function := range evaluate {
+		evaluated[function] = true
+	}
+
+	contract.TransactionContextHandler = new(TransactionContext)
+	contract.BeforeTransaction = BeforeTransaction
+	contract.AfterTransaction = func(ctx TransactionContextInterface, result interface{}) error {
+		function, _ := ctx.GetStub().GetFunctionAndParameters()
+		if evaluated[function[strings.LastIndex(function, ":")+1:]] {
+			return nil
+		}
+
+		return AfterTransaction(ctx, result)
+	}
+	contract.UnknownTransaction = func(ctx TransactionContextInterface) error {
+		return unknownTransaction(ctx, contract.Name, transactions)
+	}
+}
+
+// BeforeTransaction starts the audit record with the caller, the function
+// and its arguments
+func BeforeTransaction(ctx TransactionContextInterface) error {
+	mspID, err := callerMSP(ctx)
+	if err != nil {
+		return err
+	}
+
+	account, err := callerAccount(ctx)
+	if err != nil {
+		return err
+	}
+
+	now, err := txTime(ctx)
+	if err != nil {
+		return err
+	}
+
+	function, args := ctx.GetStub().GetFunctionAndParameters()
+	var carID string
+	if auditCarFunctions[function[strings.LastIndex(function, ":")+1:]] && len(args) > 0 {
+		carID = args[0]
+	}
+
+	ctx.SetAuditRecord(&asset.AuditRecord{
+		DocType:   auditDocType,
+		TxID:      ctx.GetStub().GetTxID(),
+		Function:  function,
+		Args:      args,
+		CarID:     carID,
+		CallerMSP: mspID,
+		Caller:    account,
+		Timestamp: now,
+	})
+
+	return nil
+}
+
+// AfterTransaction writes the audit record, it is only called when the
+// transaction succeeds. A failed transaction is rejected by the peers with
+// its writes, so only the committed transactions are audited and the
+// outcome is always AuditOutcomeSuccess.
+func AfterTransaction(ctx TransactionContextInterface, result interface{}) error {
+	record := ctx.GetAuditRecord()
+	if record == nil {
+		return fmt.Errorf("the transaction %s has no audit record", ctx.GetStub().GetTxID())
+	}
+
+	record.Outcome = asset.AuditOutcomeSuccess
+	recordJSON, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	key, err := ctx.GetStub().CreateCompositeKey(auditIndex, []string{record.TxID})
+	if err != nil {
+		return err
+	}
+
+	return ctx.GetStub().PutState(key, recordJSON)
+}
+
+// unknownTransaction rejects a function the contract does not have and
+// lists the ones it has
+func unknownTransaction(ctx TransactionContextInterface, contract string, transactions []string) error {
+	function, _ := ctx.GetStub().GetFunctionAndParameters()
+	if i := strings.LastIndex(function, ":"); i >= 0 {
+		function = function[i+1:]
+	}
+
+	return fmt.Errorf("unknown function %s in contract %s, the functions are %s", function, contract, strings.Join(transactions, ", "))
+}
+
+// QueryAuditRecords returns a page of the audit records matching the query,
+// a JSON asset.AuditQuery, oldest first. Only the auditors can query them.
+func (s *SmartContract) QueryAuditRecords(ctx contractapi.TransactionContextInterface, query string, pageSize int32, bookmark string) (*asset.AuditPage, error) {
+	if _, err := requireRole(ctx, auditorRole); err != nil {
+		return nil, err
+	}
+
+	if pageSize <= 0 || pageSize > maxAuditPageSize {
+		return nil, fmt.Errorf("page size must be between 1 and %d", maxAuditPageSize)
+	}
+
+	var auditQuery asset.AuditQuery
+	decoder := json.NewDecoder(strings.NewReader(query))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&auditQuery); err != nil {
+		return nil, fmt.Errorf("invalid query, %v", err)
+	}
+
+	selector, err := buildAuditSelector(auditQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	res, meta, err := ctx.GetStub().GetQueryResultWithPagination(selector, pageSize, bookmark)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Close()
+
+	page := &asset.AuditPage{Records: []*asset.AuditRecord{}}
+	for res.HasNext() {
+		kv, err := res.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		var record asset.AuditRecord
+		if err := json.Unmarshal(kv.Value, &record); err != nil {
+			return nil, err
+		}
+
+		page.Records = append(page.Records, &record)
+	}
+
+	if meta != nil && int32(len(page.Records)) == pageSize {
+		page.Bookmark = meta.Bookmark
+	}
+
+	return page, nil
+}
+
+// buildAuditSelector turns the query into a CouchDB query sorted by time,
+// the bounds are formatted like the timestamps of the records so they
+// compare as strings
+func buildAuditSelector(q asset.AuditQuery) (string, error) {
+	if err := q.Validate(); err != nil {
+		return "", err
+	}
+
+	// CouchDB sorts by the fields of the selector, without bounds any
+	// timestamp matches
+	timestamp := map[string]string{"$gt": ""}
+	for operator, bound := range map[string]string{"$gte": q.From, "$lte": q.To} {
+		if bound == "" {
+			continue
+		}
+
+		t, err := time.Parse(time.RFC3339, bound)
+		if err != nil {
+			return "", err
+		}
+		timestamp[operator] = t.UTC().Format(time.RFC3339)
+	}
+	if len(timestamp) > 1 {
+		delete(timestamp, "$gt")
+	}
+
+	selector := map[string]interface{}{"docType": auditDocType, "timestamp": timestamp}
+	for field, value := range map[string]string{"caller": q.Actor, "callerMsp": q.MSP, "carId": q.CarID} {
+		if value = strings.TrimSpace(value); value != "" {
+			selector[field] = value
+		}
+	}
+
+	queryJSON, err := json.Marshal(map[string]interface{}{
+		"selector":  selector,
+		"sort":      []map[string]string{{"docType": "asc"}, {"timestamp": "asc"}},
+		"use_index": []string{"_design/indexAuditDoc", "indexAudit"},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return string(queryJSON), nil
+}
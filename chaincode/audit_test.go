@@ -0,0 +1,207 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yimialmonte/chaincode-cars/asset"
+	"github.com/yimialmonte/chaincode-cars/chaincode/memstub"
+)
+
+func TestAuditRecords(t *testing.T) {
+	cc, err := NewChaincode(nil)
+	require.NoError(t, err)
+
+	creator, err := memstub.NewIdentity("Org1MSP", "dealer", map[string]string{"role": "registrar"})
+	require.NoError(t, err)
+	ledger := memstub.New(memstub.WithCreator(creator))
+
+	invoke := func(function string, args ...string) (*memstub.Stub, int32, string) {
+		stub := ledger.NewTransaction(function, args...)
+		response := cc.Invoke(stub)
+		if response.Status == shim.OK {
+			require.NoError(t, stub.Commit())
+		}
+		return stub, response.Status, response.Message
+	}
+
+	init, status, message := invoke("admin:InitLedger")
+	require.Equal(t, int32(shim.OK), status, message)
+	marcos := seedOwnerID(init.GetTxID(), 1)
+
+	transfer, status, message := invoke("transfer:TransferCart", "12", marcos)
+	require.Equal(t, int32(shim.OK), status, message)
+
+	_, status, _ = invoke("transfer:TransferCart", "99", marcos)
+	assert.Equal(t, int32(shim.ERROR), status)
+
+	read, status, message := invoke("registry:GetCar", "12")
+	require.Equal(t, int32(shim.OK), status, message)
+
+	failed, status, message := invoke("registry:RegisterCar", "31")
+	assert.Equal(t, int32(shim.ERROR), status)
+	assert.Contains(t, message, "unknown function RegisterCar in contract registry, the functions are CreateCar, CreateCars, CreateVehicle")
+
+	stub := ledger.NewTransaction("")
+	key, err := stub.CreateCompositeKey(auditIndex, []string{transfer.GetTxID()})
+	require.NoError(t, err)
+	recordJSON, err := stub.GetState(key)
+	require.NoError(t, err)
+	require.NotNil(t, recordJSON)
+
+	var record asset.AuditRecord
+	require.NoError(t, json.Unmarshal(recordJSON, &record))
+
+	assert.Equal(t, "audit", record.DocType)
+	assert.Equal(t, transfer.GetTxID(), record.TxID)
+	assert.Equal(t, "transfer:TransferCart", record.Function)
+	assert.Equal(t, []string{"12", marcos}, record.Args)
+	assert.Equal(t, "12", record.CarID)
+	assert.Equal(t, "Org1MSP", record.CallerMSP)
+	assert.Len(t, record.Caller, 64)
+	assert.NotEmpty(t, record.Timestamp)
+	assert.Equal(t, asset.AuditOutcomeSuccess, record.Outcome)
+
+	key, err = stub.CreateCompositeKey(auditIndex, []string{read.GetTxID()})
+	require.NoError(t, err)
+	recordJSON, err = stub.GetState(key)
+	require.NoError(t, err)
+	assert.Nil(t, recordJSON, "the evaluated transactions are not audited")
+
+	key, err = stub.CreateCompositeKey(auditIndex, []string{failed.GetTxID()})
+	require.NoError(t, err)
+	recordJSON, err = stub.GetState(key)
+	require.NoError(t, err)
+	assert.Nil(t, recordJSON, "the failed transactions are not committed")
+
+	it, err := stub.GetStateByPartialCompositeKey(auditIndex, nil)
+	require.NoError(t, err)
+	count := 0
+	for it.HasNext() {
+		_, err := it.Next()
+		require.NoError(t, err)
+		count++
+	}
+	require.NoError(t, it.Close())
+	assert.Equal(t, 2, count)
+}
+
+func TestQueryAuditRecords(t *testing.T) {
+	cc, err := NewChaincode(nil)
+	require.NoError(t, err)
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	dealer, err := memstub.NewIdentity("Org1MSP", "dealer", map[string]string{"role": "registrar"})
+	require.NoError(t, err)
+	ledger := memstub.New(memstub.WithCreator(dealer), memstub.WithClock(func() time.Time {
+		now = now.Add(time.Minute)
+		return now
+	}))
+
+	submit := func(function string, args ...string) *memstub.Stub {
+		stub := ledger.NewTransaction(function, args...)
+		response := cc.Invoke(stub)
+		require.Equal(t, int32(shim.OK), response.Status, response.Message)
+		require.NoError(t, stub.Commit())
+		return stub
+	}
+
+	init := submit("admin:InitLedger") // 00:01
+	juan, marcos := seedOwnerID(init.GetTxID(), 0), seedOwnerID(init.GetTxID(), 1)
+	submit("transfer:TransferCart", "12", marcos) // 00:02
+	submit("registry:GetCar", "22")               // 00:03
+	submit("transfer:TransferCart", "22", juan)   // 00:04
+
+	bank, err := memstub.NewIdentity("BankMSP", "bank", map[string]string{"role": "lender"})
+	require.NoError(t, err)
+	ledger.SetCreator(bank)
+	submit("registry:PlaceLien", "12", "5000") // 00:05
+
+	auditor, err := memstub.NewIdentity("Org1MSP", "auditor", map[string]string{"role": "auditor"})
+	require.NoError(t, err)
+	ledger.SetCreator(auditor)
+
+	var bankAccount string
+	stub := ledger.NewTransaction("")
+	it, err := stub.GetStateByPartialCompositeKey(auditIndex, nil)
+	require.NoError(t, err)
+	for it.HasNext() {
+		kv, err := it.Next()
+		require.NoError(t, err)
+		var record asset.AuditRecord
+		require.NoError(t, json.Unmarshal(kv.Value, &record))
+		if record.CallerMSP == "BankMSP" {
+			bankAccount = record.Caller
+		}
+	}
+	require.NoError(t, it.Close())
+
+	query := func(q asset.AuditQuery, pageSize int32, bookmark string) (*asset.AuditPage, string) {
+		queryJSON, err := json.Marshal(q)
+		require.NoError(t, err)
+
+		response := cc.Invoke(ledger.NewTransaction("admin:QueryAuditRecords", string(queryJSON), fmt.Sprint(pageSize), bookmark))
+		if response.Status != shim.OK {
+			return nil, response.Message
+		}
+
+		var page asset.AuditPage
+		require.NoError(t, json.Unmarshal(response.Payload, &page))
+		return &page, ""
+	}
+
+	functions := func(page *asset.AuditPage) []string {
+		var names []string
+		for _, record := range page.Records {
+			names = append(names, record.Function)
+		}
+		return names
+	}
+
+	tests := []struct {
+		name              string
+		query             asset.AuditQuery
+		expectedFunctions []string
+		expectedErr       string
+	}{
+		{"all", asset.AuditQuery{}, []string{"admin:InitLedger", "transfer:TransferCart", "transfer:TransferCart", "registry:PlaceLien"}, ""},
+		{"time range", asset.AuditQuery{From: "2024-01-01T00:02:00Z", To: "2024-01-01T01:04:00+01:00"}, []string{"transfer:TransferCart", "transfer:TransferCart"}, ""},
+		{"car", asset.AuditQuery{CarID: "12"}, []string{"transfer:TransferCart", "registry:PlaceLien"}, ""},
+		{"actor", asset.AuditQuery{Actor: bankAccount}, []string{"registry:PlaceLien"}, ""},
+		{"msp and car", asset.AuditQuery{MSP: "Org1MSP", CarID: "22"}, []string{"transfer:TransferCart"}, ""},
+		{"no match", asset.AuditQuery{From: "2025-01-01T00:00:00Z"}, nil, ""},
+		{"invalid time", asset.AuditQuery{From: "yesterday"}, nil, "invalid from yesterday, use RFC 3339"},
+		{"inverted range", asset.AuditQuery{From: "2024-01-02T00:00:00Z", To: "2024-01-01T00:00:00Z"}, nil, "from is after to"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			page, message := query(test.query, 10, "")
+			if test.expectedErr != "" {
+				assert.Equal(t, test.expectedErr, message)
+				return
+			}
+
+			require.Empty(t, message)
+			assert.Equal(t, test.expectedFunctions, functions(page))
+			assert.Empty(t, page.Bookmark)
+		})
+	}
+
+	first, _ := query(asset.AuditQuery{}, 2, "")
+	require.NotEmpty(t, first.Bookmark)
+	second, _ := query(asset.AuditQuery{}, 2, first.Bookmark)
+	assert.Empty(t, second.Bookmark)
+	assert.Equal(t, []string{"admin:InitLedger", "transfer:TransferCart"}, functions(first))
+	assert.Equal(t, []string{"transfer:TransferCart", "registry:PlaceLien"}, functions(second))
+	assert.Equal(t, "22", second.Records[0].CarID)
+
+	ledger.SetCreator(dealer)
+	_, message := query(asset.AuditQuery{}, 10, "")
+	assert.Equal(t, "access denied, the caller does not have the auditor role", message)
+}
@@ -2,26 +2,50 @@ package chaincode
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
+	"time"
 
+	"github.com/golang/protobuf/ptypes"
 	"github.com/hyperledger/fabric-contract-api-go/contractapi"
 	"github.com/yimialmonte/chaincode-cars/asset"
 )
 
+// ErrRevisionMismatch is returned when a write expects a revision of the car
+// that is no longer the current one
+var ErrRevisionMismatch = errors.New("revision mismatch")
+
+// maxBatchSize is the maximum number of cars registered in one transaction
+const maxBatchSize = 100
+
 // SmartContract ...
 type SmartContract struct {
 	contractapi.Contract
+
+	// TransferHooks are the external chaincodes clearing every transfer
+	TransferHooks []TransferHook
 }
 
-// InitLedger
+// InitLedger seeds two cars, their owners are registered under ids
+// generated from the transaction so no name is written in clear text
 func (s *SmartContract) InitLedger(ctx contractapi.TransactionContextInterface) error {
-	cars := []asset.Car{
-		{Brand: "Toyota", ID: "12", Owner: "Juan", TransfersCount: 0},
-		{Brand: "Honda", ID: "22", Owner: "Marcos", TransfersCount: 0},
+	seeds := []struct {
+		car  asset.Car
+		name string
+	}{
+		{asset.Car{Brand: "Toyota", ID: "12"}, "Juan"},
+		{asset.Car{Brand: "Honda", ID: "22"}, "Marcos"},
 	}
 
-	for _, car := range cars {
+	for i, seed := range seeds {
+		id := seedOwnerID(ctx.GetStub().GetTxID(), i)
+		if err := putOwner(ctx, &asset.Owner{ID: id, Type: asset.OwnerPerson}, &ownerPrivate{ID: id, Name: seed.name}); err != nil {
+			return fmt.Errorf("failed operation, %v", err)
+		}
+
+		car := seed.car
+		car.Owner = id
 		carJSON, err := json.Marshal(car)
 		if err != nil {
 			return err
@@ -64,6 +88,41 @@ func (s *SmartContract) GetCars(ctx contractapi.TransactionContextInterface) ([]
 	return cars, nil
 }
 
+// GetCarsPage returns a page of cars starting at the bookmark
+func (s *SmartContract) GetCarsPage(ctx contractapi.TransactionContextInterface, pageSize int32, bookmark string) (*asset.CarsPage, error) {
+	if pageSize <= 0 {
+		return nil, fmt.Errorf("page size must be greater than zero")
+	}
+
+	res, meta, err := ctx.GetStub().GetStateByRangeWithPagination("", "", pageSize, bookmark)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Close()
+
+	page := &asset.CarsPage{Cars: []*asset.Car{}}
+	for res.HasNext() {
+		car, err := res.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		var carAsset asset.Car
+		err = json.Unmarshal(car.Value, &carAsset)
+		if err != nil {
+			return nil, err
+		}
+
+		page.Cars = append(page.Cars, &carAsset)
+	}
+
+	if meta != nil && int32(len(page.Cars)) == pageSize {
+		page.Bookmark = meta.Bookmark
+	}
+
+	return page, nil
+}
+
 // GetCarsByOwner
 func (s *SmartContract) GetCarsByOwner(ctx contractapi.TransactionContextInterface, owner string) ([]*asset.Car, error) {
 	cars, err := s.GetCars(ctx)
@@ -77,7 +136,7 @@ func (s *SmartContract) GetCarsByOwner(ctx contractapi.TransactionContextInterfa
 
 	var ownerCars []*asset.Car
 	for _, car := range cars {
-		if car.Owner == owner {
+		if car.HeldBy(owner) {
 			ownerCars = append(ownerCars, car)
 		}
 	}
@@ -85,19 +144,60 @@ func (s *SmartContract) GetCarsByOwner(ctx contractapi.TransactionContextInterfa
 	return ownerCars, nil
 }
 
-// TransferCart ...
+// TransferCart moves the car to the new owner. A car with a single owner is
+// transferred by the identity linked to its owner or by a registrar, a
+// co-owned car once every co-owner approved.
 func (s *SmartContract) TransferCart(ctx contractapi.TransactionContextInterface, id, newOwner string) error {
 	car, err := s.GetCar(ctx, id)
 	if err != nil {
 		return err
 	}
 
+	if len(car.Owners) == 0 {
+		if _, err := requireRole(ctx, registrarRole); err != nil {
+			if err := requireOwnerIdentity(ctx, car.Owner); err != nil {
+				return err
+			}
+		}
+	}
+
+	return s.transferCar(ctx, car, newOwner)
+}
+
+// transferCar checks and applies the transfer, the caller is already allowed
+// to transfer the car
+func (s *SmartContract) transferCar(ctx contractapi.TransactionContextInterface, car *asset.Car, newOwner string) error {
+	id := car.ID
 	if ok, errTran := s.IsAbleToTransfer(car, newOwner); !ok {
 		return errTran
 	}
 
+	if err := requireOwner(ctx, newOwner); err != nil {
+		return err
+	}
+
+	if err := s.checkTransferHooks(ctx, car, newOwner); err != nil {
+		return err
+	}
+
+	if len(car.Owners) > 0 {
+		if err := s.checkApprovals(ctx, car, newOwner); err != nil {
+			return err
+		}
+
+		if err := s.clearApprovals(ctx, car); err != nil {
+			return err
+		}
+		car.Owners = nil
+	}
+
+	if err := clearOffer(ctx, id); err != nil {
+		return err
+	}
+
 	car.Owner = newOwner
 	car.TransfersCount++
+	car.Revision++
 
 	carJSON, err := json.Marshal(car)
 	if err != nil {
@@ -107,13 +207,38 @@ func (s *SmartContract) TransferCart(ctx contractapi.TransactionContextInterface
 	return ctx.GetStub().PutState(id, carJSON)
 }
 
+// TransferCarIfMatch transfers the car only when its current revision is the
+// expected one, so concurrent writers do not overwrite each other
+func (s *SmartContract) TransferCarIfMatch(ctx contractapi.TransactionContextInterface, id, newOwner string, revision int) error {
+	car, err := s.GetCar(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := checkRevision(car, revision); err != nil {
+		return err
+	}
+
+	return s.TransferCart(ctx, id, newOwner)
+}
+
+// checkRevision fails unless the car is at the expected revision
+func checkRevision(car *asset.Car, revision int) error {
+	if car.Revision != revision {
+		return fmt.Errorf("%w, car %s is at revision %d, expected %d", ErrRevisionMismatch, car.ID, car.Revision, revision)
+	}
+
+	return nil
+}
+
 // IsAbleToTransfer ...
 func (s *SmartContract) IsAbleToTransfer(car *asset.Car, newOwner string) (bool, error) {
 	if car == nil {
 		return false, fmt.Errorf("unable to process transaction, car does not exist")
 	}
 
-	if car.Owner == newOwner {
+	// a co-owner can buy the shares of the others
+	if len(car.Owners) == 0 && car.Owner == newOwner {
 		return false, fmt.Errorf("unable to process transaction, car owner %s is equal to %s", car.Owner, newOwner)
 	}
 
@@ -122,6 +247,14 @@ func (s *SmartContract) IsAbleToTransfer(car *asset.Car, newOwner string) (bool,
 		return false, fmt.Errorf("unable to process, total car transaction %d exceed the limit", car.TransfersCount)
 	}
 
+	if car.Stolen {
+		return false, fmt.Errorf("unable to process transaction, car %s is reported stolen", car.ID)
+	}
+
+	if car.ActiveLiens > 0 {
+		return false, fmt.Errorf("unable to process transaction, car %s has %d active liens", car.ID, car.ActiveLiens)
+	}
+
 	return true, nil
 }
 
@@ -156,16 +289,181 @@ func (s *SmartContract) CreateCar(ctx contractapi.TransactionContextInterface, i
 		return fmt.Errorf("the car with id %s already exist", id)
 	}
 
+	if err := validateCarFields(id, brand, owner); err != nil {
+		return err
+	}
+
+	car := asset.Car{ID: id, Brand: brand, Owner: owner}
+	if err := requireOwners(ctx, car); err != nil {
+		return err
+	}
+
+	return putNewCar(ctx, car)
+}
+
+// CreateCars registers a batch of cars with all-or-nothing semantics, when
+// any item is invalid nothing is written and the report tells which ones
+func (s *SmartContract) CreateCars(ctx contractapi.TransactionContextInterface, cars []asset.Car) (*asset.BatchReport, error) {
+	if len(cars) == 0 {
+		return nil, fmt.Errorf("the batch is empty")
+	}
+
+	if len(cars) > maxBatchSize {
+		return nil, fmt.Errorf("the batch has %d cars, the limit is %d", len(cars), maxBatchSize)
+	}
+
+	report := &asset.BatchReport{Created: true}
+	seen := make(map[string]bool, len(cars))
+	for i, car := range cars {
+		item := &asset.BatchItem{Index: i, ID: car.ID}
+		report.Items = append(report.Items, item)
+
+		if err := validateCarFields(car.ID, car.Brand, car.Owner); err != nil {
+			item.Error = err.Error()
+			report.Created = false
+			continue
+		}
+
+		if car.Year < 0 {
+			item.Error = "the year can not be negative"
+			report.Created = false
+			continue
+		}
+
+		if err := car.ValidateAttributes(); err != nil {
+			item.Error = err.Error()
+			report.Created = false
+			continue
+		}
+
+		if err := car.ValidateShares(); err != nil {
+			item.Error = err.Error()
+			report.Created = false
+			continue
+		}
+
+		if seen[car.ID] {
+			item.Error = fmt.Sprintf("the car with id %s is repeated in the batch", car.ID)
+			report.Created = false
+			continue
+		}
+		seen[car.ID] = true
+
+		exist, err := s.ExistCar(ctx, car.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		if exist {
+			item.Error = fmt.Sprintf("the car with id %s already exist", car.ID)
+			item.Exists = true
+			report.Created = false
+			continue
+		}
+
+		if err := requireOwners(ctx, car); err != nil {
+			item.Error = err.Error()
+			report.Created = false
+		}
+	}
+
+	if !report.Created {
+		return report, nil
+	}
+
+	for _, car := range cars {
+		if err := putNewCar(ctx, car); err != nil {
+			return nil, err
+		}
+	}
+
+	return report, nil
+}
+
+// CreateVehicle registers a vehicle of any type with its type attributes
+func (s *SmartContract) CreateVehicle(ctx contractapi.TransactionContextInterface, vehicle asset.Car) error {
+	if err := validateCarFields(vehicle.ID, vehicle.Brand, vehicle.Owner); err != nil {
+		return err
+	}
+
+	if vehicle.Year < 0 {
+		return fmt.Errorf("the year can not be negative")
+	}
+
+	if err := vehicle.ValidateAttributes(); err != nil {
+		return err
+	}
+
+	if err := vehicle.ValidateShares(); err != nil {
+		return err
+	}
+
+	exist, err := s.ExistCar(ctx, vehicle.ID)
+	if err != nil {
+		return err
+	}
+
+	if exist {
+		return fmt.Errorf("the car with id %s already exist", vehicle.ID)
+	}
+
+	if err := requireOwners(ctx, vehicle); err != nil {
+		return err
+	}
+
+	return putNewCar(ctx, vehicle)
+}
+
+// GetCarsByType returns the vehicles of the type, the ones without type are
+// cars
+func (s *SmartContract) GetCarsByType(ctx contractapi.TransactionContextInterface, vehicleType string) ([]*asset.Car, error) {
+	if !asset.ValidVehicleType(vehicleType) {
+		return nil, fmt.Errorf("unknown vehicle type %q", vehicleType)
+	}
+
+	cars, err := s.GetCars(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	found := []*asset.Car{}
+	for _, car := range cars {
+		if car.VehicleType() == vehicleType {
+			found = append(found, car)
+		}
+	}
+
+	return found, nil
+}
+
+// validateCarFields checks the fields required to register a car
+func validateCarFields(id, brand, owner string) error {
 	if strings.TrimSpace(brand) == "" ||
 		strings.TrimSpace(id) == "" ||
 		strings.TrimSpace(owner) == "" {
 		return fmt.Errorf("All fields are required")
 	}
 
+	return nil
+}
+
+// putNewCar writes a car without transfers to the world state
+func putNewCar(ctx contractapi.TransactionContextInterface, car asset.Car) error {
 	newCar := asset.Car{
-		Brand: brand,
-		ID:    id,
-		Owner: owner,
+		Brand:     car.Brand,
+		ID:        car.ID,
+		Owner:     car.Owner,
+		Owners:    car.Owners,
+		Year:      car.Year,
+		Type:      car.Type,
+		EngineCC:  car.EngineCC,
+		Axles:     car.Axles,
+		PayloadKg: car.PayloadKg,
+	}
+
+	// cars are stored without type, like the ones registered before types
+	if newCar.Type == asset.VehicleCar {
+		newCar.Type = ""
 	}
 
 	carJSON, err := json.Marshal(newCar)
@@ -173,7 +471,7 @@ func (s *SmartContract) CreateCar(ctx contractapi.TransactionContextInterface, i
 		return err
 	}
 
-	return ctx.GetStub().PutState(id, carJSON)
+	return ctx.GetStub().PutState(newCar.ID, carJSON)
 }
 
 // ExistCar ...
@@ -185,3 +483,107 @@ func (s *SmartContract) ExistCar(ctx contractapi.TransactionContextInterface, id
 
 	return carJSON != nil, nil
 }
+
+// GetCarRecord returns the car with the transaction that last modified it
+func (s *SmartContract) GetCarRecord(ctx contractapi.TransactionContextInterface, id string) (*asset.CarRecord, error) {
+	car, err := s.GetCar(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := ctx.GetStub().GetHistoryForKey(id)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Close()
+
+	if !res.HasNext() {
+		return nil, fmt.Errorf("car %s has no history", id)
+	}
+
+	mod, err := res.Next()
+	if err != nil {
+		return nil, err
+	}
+
+	record := &asset.CarRecord{Car: car, TxID: mod.TxId}
+	if mod.Timestamp != nil {
+		ts, err := ptypes.Timestamp(mod.Timestamp)
+		if err != nil {
+			return nil, err
+		}
+		record.Timestamp = ts.UTC().Format(time.RFC3339)
+	}
+
+	return record, nil
+}
+
+// GetCarHistory returns every version of the car with the transaction that
+// wrote it, newest first
+func (s *SmartContract) GetCarHistory(ctx contractapi.TransactionContextInterface, id string) ([]*asset.CarRecord, error) {
+	if _, err := s.GetCar(ctx, id); err != nil {
+		return nil, err
+	}
+
+	res, err := ctx.GetStub().GetHistoryForKey(id)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Close()
+
+	records := []*asset.CarRecord{}
+	for res.HasNext() {
+		mod, err := res.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		if mod.IsDelete {
+			continue
+		}
+
+		var car asset.Car
+		if err := json.Unmarshal(mod.Value, &car); err != nil {
+			return nil, err
+		}
+
+		record := &asset.CarRecord{Car: &car, TxID: mod.TxId}
+		if mod.Timestamp != nil {
+			ts, err := ptypes.Timestamp(mod.Timestamp)
+			if err != nil {
+				return nil, err
+			}
+			record.Timestamp = ts.UTC().Format(time.RFC3339)
+		}
+
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+// putCar writes the car as it is to the world state
+func putCar(ctx contractapi.TransactionContextInterface, car *asset.Car) error {
+	carJSON, err := json.Marshal(car)
+	if err != nil {
+		return err
+	}
+
+	return ctx.GetStub().PutState(car.ID, carJSON)
+}
+
+// txTime returns the transaction timestamp, the same on every endorsing
+// peer unlike the local clock
+func txTime(ctx contractapi.TransactionContextInterface) (string, error) {
+	ts, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return "", err
+	}
+
+	t, err := ptypes.Timestamp(ts)
+	if err != nil {
+		return "", err
+	}
+
+	return t.UTC().Format(time.RFC3339), nil
+}
@@ -4,14 +4,19 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/hyperledger/fabric-chaincode-go/pkg/cid"
 	"github.com/hyperledger/fabric-chaincode-go/shim"
 	"github.com/hyperledger/fabric-contract-api-go/contractapi"
 	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+	"github.com/hyperledger/fabric-protos-go/peer"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/yimialmonte/chaincode-cars/asset"
+	"github.com/yimialmonte/chaincode-cars/chaincode/memstub"
 	"github.com/yimialmonte/chaincode-cars/chaincode/mocks"
 )
 
@@ -35,6 +40,38 @@ type stateReturn struct {
 	err   error
 }
 
+// registrar is an identity with the registrar role, transferring the cars of
+// the owners not linked to an identity
+func registrar(t *testing.T) []byte {
+	return identity(t, "Org1MSP", map[string]string{"role": "registrar"})
+}
+
+// asRegistrar makes the mock context submit as a registrar
+func asRegistrar(t *testing.T, tctx *mocks.TransactionContext, stub *mocks.ChaincodeStub) {
+	stub.GetCreatorReturns(registrar(t), nil)
+	client, err := cid.New(stub)
+	require.NoError(t, err)
+	tctx.GetClientIdentityReturns(client)
+}
+
+// registerOwners makes the mock stub serve the owners, the other keys return
+// the state
+func registerOwners(stub *mocks.ChaincodeStub, state stateReturn, owners ...string) {
+	registered := map[string][]byte{}
+	for _, id := range owners {
+		key, _ := shim.CreateCompositeKey(ownerIndex, []string{id})
+		registered[key], _ = json.Marshal(asset.Owner{ID: id, Type: asset.OwnerPerson})
+	}
+
+	stub.CreateCompositeKeyStub = shim.CreateCompositeKey
+	stub.GetStateStub = func(key string) ([]byte, error) {
+		if strings.HasPrefix(key, "\x00"+ownerIndex+"\x00") {
+			return registered[key], nil
+		}
+		return state.state, state.err
+	}
+}
+
 func TestInitLedger(t *testing.T) {
 	tests := []struct {
 		state       stateReturn
@@ -224,6 +261,11 @@ func TestCreateCar(t *testing.T) {
 			errors.New("the car with id 11 already exist"),
 			asset.Car{ID: "11", Brand: "Toyota", Owner: "Peter"},
 		},
+		{
+			stateReturn{nil, nil},
+			errors.New("owner does not exist ID: Pedro"),
+			asset.Car{ID: "11", Brand: "Toyota", Owner: "Pedro"},
+		},
 		{
 			stateReturn{nil, errors.New("connection failed")},
 			errors.New("connection failed"),
@@ -258,7 +300,7 @@ func TestCreateCar(t *testing.T) {
 			tctx.GetStubReturns(stu)
 
 			sc := SmartContract{}
-			stu.GetStateReturns(test.state.state, test.state.err)
+			registerOwners(stu, test.state, "Peter", "Max")
 			err := sc.CreateCar(tctx, test.car.ID, test.car.Brand, test.car.Owner)
 			assert.Equal(t, test.expectedErr, err)
 		})
@@ -346,6 +388,24 @@ func TestIsAbleToTransfer(t *testing.T) {
 			nil,
 			true,
 		},
+		{
+			&asset.Car{ID: "12", Owner: "Mar", Stolen: true, ActiveLiens: 2},
+			"Jackson",
+			errors.New("unable to process transaction, car 12 is reported stolen"),
+			false,
+		},
+		{
+			&asset.Car{ID: "12", Owner: "Mar", ActiveLiens: 2},
+			"Jackson",
+			errors.New("unable to process transaction, car 12 has 2 active liens"),
+			false,
+		},
+		{
+			&asset.Car{ID: "12", Owner: "Mar", Owners: []asset.Share{{Owner: "Mar", Percent: 50}, {Owner: "Ana", Percent: 50}}},
+			"Mar",
+			nil,
+			true,
+		},
 	}
 
 	for _, test := range tests {
@@ -394,6 +454,16 @@ func TestTransferCart(t *testing.T) {
 			errors.New("unable to process transaction, car owner Max is equal to Max"),
 			"Max",
 		},
+		{
+			asset.Car{
+				Brand:          "Toyota",
+				ID:             "123",
+				Owner:          "Max",
+				TransfersCount: 1,
+			},
+			errors.New("owner does not exist ID: Pedro"),
+			"Pedro",
+		},
 	}
 
 	for _, test := range tests {
@@ -405,10 +475,404 @@ func TestTransferCart(t *testing.T) {
 			bytes, err := json.Marshal(test.car)
 			require.NoError(t, err)
 
-			stub.GetStateReturns(bytes, nil)
+			registerOwners(stub, stateReturn{bytes, nil}, "Max", "Peter")
+			asRegistrar(t, tctx, stub)
 			sc := &SmartContract{}
 			err = sc.TransferCart(tctx, "", test.newOwner)
 			assert.Equal(t, test.expectedErr, err)
 		})
 	}
 }
+
+func TestTransferCarIfMatch(t *testing.T) {
+	tests := []struct {
+		car         asset.Car
+		revision    int
+		expectedErr error
+	}{
+		{
+			asset.Car{ID: "123", Brand: "Toyota", Owner: "Max", TransfersCount: 1, Revision: 1},
+			1,
+			nil,
+		},
+		{
+			asset.Car{ID: "123", Brand: "Toyota", Owner: "Max", TransfersCount: 1, Revision: 2},
+			1,
+			fmt.Errorf("%w, car 123 is at revision 2, expected 1", ErrRevisionMismatch),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%v", test), func(t *testing.T) {
+			stub := &mocks.ChaincodeStub{}
+			tctx := &mocks.TransactionContext{}
+			tctx.GetStubReturns(stub)
+
+			bytes, err := json.Marshal(test.car)
+			require.NoError(t, err)
+
+			registerOwners(stub, stateReturn{bytes, nil}, "Peter")
+			asRegistrar(t, tctx, stub)
+			sc := &SmartContract{}
+			err = sc.TransferCarIfMatch(tctx, "123", "Peter", test.revision)
+			assert.Equal(t, test.expectedErr, err)
+			if test.expectedErr != nil {
+				assert.True(t, errors.Is(err, ErrRevisionMismatch))
+				assert.Equal(t, 0, stub.PutStateCallCount())
+				return
+			}
+
+			require.Equal(t, 1, stub.PutStateCallCount())
+			_, putJSON := stub.PutStateArgsForCall(0)
+			var updated asset.Car
+			require.NoError(t, json.Unmarshal(putJSON, &updated))
+			assert.Equal(t, test.revision+1, updated.Revision)
+			assert.Equal(t, "Peter", updated.Owner)
+		})
+	}
+}
+
+func TestCreateCars(t *testing.T) {
+	tests := []struct {
+		cars            []asset.Car
+		state           stateReturn
+		expectedErr     error
+		expectedCreated bool
+		expectedItems   []string
+		expectedPuts    int
+	}{
+		{
+			[]asset.Car{{ID: "1", Brand: "Toyota", Owner: "Juan"}, {ID: "2", Brand: "Honda", Owner: "Max"}},
+			stateReturn{nil, nil},
+			nil,
+			true,
+			[]string{"", ""},
+			2,
+		},
+		{
+			[]asset.Car{{ID: "1", Brand: "Toyota", Owner: "Juan"}, {ID: "2", Owner: "Max"}, {ID: "1", Brand: "Kia", Owner: "Ana"}},
+			stateReturn{nil, nil},
+			nil,
+			false,
+			[]string{"", "All fields are required", "the car with id 1 is repeated in the batch"},
+			0,
+		},
+		{
+			[]asset.Car{{ID: "1", Brand: "Volvo", Owner: "Juan", Type: asset.VehicleTruck, Axles: 3, PayloadKg: 18000}, {ID: "2", Brand: "Volvo", Owner: "Juan", Type: asset.VehicleTruck}},
+			stateReturn{nil, nil},
+			nil,
+			false,
+			[]string{"", "a truck requires at least 2 axles"},
+			0,
+		},
+		{
+			[]asset.Car{{ID: "1", Brand: "Toyota", Owner: "Juan"}, {ID: "2", Brand: "Seat", Owner: "Juan", Owners: []asset.Share{{Owner: "Juan", Percent: 50}, {Owner: "Pedro", Percent: 50}}}},
+			stateReturn{nil, nil},
+			nil,
+			false,
+			[]string{"", "owner does not exist ID: Pedro"},
+			0,
+		},
+		{
+			[]asset.Car{{ID: "1", Brand: "Toyota", Owner: "Juan"}},
+			stateReturn{[]byte{}, nil},
+			nil,
+			false,
+			[]string{"the car with id 1 already exist"},
+			0,
+		},
+		{
+			[]asset.Car{{ID: "1", Brand: "Toyota", Owner: "Juan"}},
+			stateReturn{nil, errors.New("connection failed")},
+			errors.New("connection failed"),
+			false,
+			nil,
+			0,
+		},
+		{
+			[]asset.Car{},
+			stateReturn{nil, nil},
+			errors.New("the batch is empty"),
+			false,
+			nil,
+			0,
+		},
+		{
+			make([]asset.Car, maxBatchSize+1),
+			stateReturn{nil, nil},
+			fmt.Errorf("the batch has %d cars, the limit is %d", maxBatchSize+1, maxBatchSize),
+			false,
+			nil,
+			0,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%v", test.cars), func(t *testing.T) {
+			stu := &mocks.ChaincodeStub{}
+			tctx := &mocks.TransactionContext{}
+			tctx.GetStubReturns(stu)
+			registerOwners(stu, test.state, "Juan", "Max", "Ana")
+
+			sc := SmartContract{}
+			report, err := sc.CreateCars(tctx, test.cars)
+			assert.Equal(t, test.expectedErr, err)
+			assert.Equal(t, test.expectedPuts, stu.PutStateCallCount())
+			if err != nil {
+				assert.Nil(t, report)
+				return
+			}
+
+			assert.Equal(t, test.expectedCreated, report.Created)
+			var items []string
+			for _, item := range report.Items {
+				items = append(items, item.Error)
+			}
+			assert.Equal(t, test.expectedItems, items)
+		})
+	}
+}
+
+func TestGetCarsPage(t *testing.T) {
+	car := asset.Car{ID: "123", Owner: "Peter", Brand: "Honda"}
+	b, err := json.Marshal(car)
+	require.NoError(t, err)
+
+	tests := []struct {
+		pageSize         int32
+		err              error
+		expectedErr      error
+		expectedBookmark string
+		expectedCars     int
+	}{
+		{1, nil, nil, "124", 1},
+		{2, nil, nil, "", 1},
+		{0, nil, errors.New("page size must be greater than zero"), "", 0},
+		{1, errors.New("connection failed"), errors.New("connection failed"), "", 0},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%v", test), func(t *testing.T) {
+			it := &mocks.StateQueryIterator{}
+			it.HasNextReturnsOnCall(0, true)
+			it.HasNextReturnsOnCall(1, false)
+			it.NextReturns(&queryresult.KV{Value: b}, nil)
+
+			stub := &mocks.ChaincodeStub{}
+			tctx := &mocks.TransactionContext{}
+			tctx.GetStubReturns(stub)
+			stub.GetStateByRangeWithPaginationReturns(it, &peer.QueryResponseMetadata{Bookmark: "124", FetchedRecordsCount: 1}, test.err)
+
+			sc := &SmartContract{}
+			page, err := sc.GetCarsPage(tctx, test.pageSize, "")
+			assert.Equal(t, test.expectedErr, err)
+			if err != nil {
+				return
+			}
+
+			assert.Equal(t, test.expectedBookmark, page.Bookmark)
+			assert.Len(t, page.Cars, test.expectedCars)
+		})
+	}
+}
+
+func TestCarLifecycle(t *testing.T) {
+	ledger := memstub.New(memstub.WithCreator(registrar(t)))
+	sc := &SmartContract{}
+
+	require.NoError(t, ledger.Run(sc.InitLedger))
+	juan, _ := seededOwners(t, ledger)
+	createOwners(t, ledger, sc, "Max", "Ana")
+	require.NoError(t, ledger.Run(func(ctx contractapi.TransactionContextInterface) error {
+		return sc.CreateCar(ctx, "31", "Kia", juan)
+	}))
+
+	for _, owner := range []string{"Max", "Ana", juan} {
+		require.NoError(t, ledger.Run(func(ctx contractapi.TransactionContextInterface) error {
+			return sc.TransferCart(ctx, "31", owner)
+		}))
+	}
+
+	err := ledger.Run(func(ctx contractapi.TransactionContextInterface) error {
+		return sc.TransferCart(ctx, "31", "Max")
+	})
+	assert.EqualError(t, err, "unable to process, total car transaction 3 exceed the limit")
+
+	err = ledger.Run(func(ctx contractapi.TransactionContextInterface) error {
+		cars, err := sc.GetCarsByOwner(ctx, juan)
+		if err != nil {
+			return err
+		}
+
+		assert.Equal(t, []*asset.Car{
+			{ID: "12", Brand: "Toyota", Owner: juan},
+			{ID: "31", Brand: "Kia", Owner: juan, TransfersCount: 3, Revision: 3},
+		}, cars)
+		return nil
+	})
+	require.NoError(t, err)
+
+	stub := ledger.NewTransaction("GetHistoryForKey")
+	it, err := stub.GetHistoryForKey("31")
+	require.NoError(t, err)
+
+	var owners []string
+	for it.HasNext() {
+		mod, err := it.Next()
+		require.NoError(t, err)
+
+		var car asset.Car
+		require.NoError(t, json.Unmarshal(mod.Value, &car))
+		owners = append(owners, car.Owner)
+	}
+	assert.Equal(t, []string{juan, "Ana", "Max", juan}, owners)
+}
+
+func TestGetCarRecord(t *testing.T) {
+	now := time.Date(2021, 9, 21, 15, 0, 0, 0, time.UTC)
+	ledger := memstub.New(memstub.WithCreator(registrar(t)), memstub.WithClock(func() time.Time {
+		now = now.Add(time.Hour)
+		return now
+	}))
+	sc := &SmartContract{}
+	createOwners(t, ledger, sc, "Juan", "Max")
+
+	require.NoError(t, ledger.Run(func(ctx contractapi.TransactionContextInterface) error {
+		return sc.CreateCar(ctx, "31", "Kia", "Juan")
+	}))
+
+	var txID string
+	require.NoError(t, ledger.Run(func(ctx contractapi.TransactionContextInterface) error {
+		txID = ctx.GetStub().GetTxID()
+		return sc.TransferCart(ctx, "31", "Max")
+	}))
+
+	err := ledger.Run(func(ctx contractapi.TransactionContextInterface) error {
+		record, err := sc.GetCarRecord(ctx, "31")
+		require.NoError(t, err)
+		assert.Equal(t, "Max", record.Car.Owner)
+		assert.Equal(t, txID, record.TxID)
+		assert.Equal(t, "2021-09-21T19:00:00Z", record.Timestamp)
+
+		_, err = sc.GetCarRecord(ctx, "99")
+		assert.EqualError(t, err, "car does not exist ID: 99")
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+func TestGetCarHistory(t *testing.T) {
+	now := time.Date(2021, 9, 21, 15, 0, 0, 0, time.UTC)
+	ledger := memstub.New(memstub.WithCreator(registrar(t)), memstub.WithClock(func() time.Time {
+		now = now.Add(time.Hour)
+		return now
+	}))
+	sc := &SmartContract{}
+	createOwners(t, ledger, sc, "Juan", "Max")
+
+	var txIDs []string
+	for _, fn := range []func(ctx contractapi.TransactionContextInterface) error{
+		func(ctx contractapi.TransactionContextInterface) error { return sc.CreateCar(ctx, "31", "Kia", "Juan") },
+		func(ctx contractapi.TransactionContextInterface) error { return sc.TransferCart(ctx, "31", "Max") },
+		func(ctx contractapi.TransactionContextInterface) error { return sc.TransferCart(ctx, "31", "Juan") },
+	} {
+		require.NoError(t, ledger.Run(func(ctx contractapi.TransactionContextInterface) error {
+			txIDs = append([]string{ctx.GetStub().GetTxID()}, txIDs...)
+			return fn(ctx)
+		}))
+	}
+
+	err := ledger.Run(func(ctx contractapi.TransactionContextInterface) error {
+		records, err := sc.GetCarHistory(ctx, "31")
+		require.NoError(t, err)
+		require.Len(t, records, 3)
+
+		var owners []string
+		for i, record := range records {
+			owners = append(owners, record.Car.Owner)
+			assert.Equal(t, txIDs[i], record.TxID)
+		}
+		assert.Equal(t, []string{"Juan", "Max", "Juan"}, owners)
+		assert.Equal(t, 2, records[0].Car.TransfersCount)
+		assert.Equal(t, "2021-09-21T20:00:00Z", records[0].Timestamp)
+		assert.Equal(t, "2021-09-21T18:00:00Z", records[2].Timestamp)
+
+		_, err = sc.GetCarHistory(ctx, "99")
+		assert.EqualError(t, err, "car does not exist ID: 99")
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+func TestCreateVehicle(t *testing.T) {
+	ledger := memstub.New()
+	sc := &SmartContract{}
+	require.NoError(t, ledger.Run(sc.InitLedger))
+	createOwners(t, ledger, sc, "Cargo SA", "Logistics SL", "Ana")
+
+	tests := []struct {
+		vehicle     asset.Car
+		expectedErr string
+	}{
+		{asset.Car{ID: "T1", Brand: "Volvo", Owner: "Cargo SA", Type: asset.VehicleTruck, Axles: 3, PayloadKg: 18000}, ""},
+		{asset.Car{ID: "M1", Brand: "Ducati", Owner: "Ana", Type: asset.VehicleMotorcycle, EngineCC: 937}, ""},
+		{asset.Car{ID: "R1", Brand: "Schmitz", Owner: "Cargo SA", Type: asset.VehicleTrailer, Axles: 1, PayloadKg: 9000}, ""},
+		{asset.Car{ID: "C1", Brand: "Kia", Owner: "Ana", Type: asset.VehicleCar}, ""},
+		{asset.Car{ID: "T1", Brand: "Volvo", Owner: "Cargo SA", Type: asset.VehicleTruck, Axles: 3, PayloadKg: 18000}, "the car with id T1 already exist"},
+		{asset.Car{ID: "T2", Brand: "Volvo", Owner: "Cargo SA", Type: asset.VehicleTruck, Axles: 1, PayloadKg: 18000}, "a truck requires at least 2 axles"},
+		{asset.Car{ID: "T3", Brand: "Volvo", Owner: "Cargo SA", Type: asset.VehicleTruck, Axles: 2}, "a truck requires a positive payloadKg"},
+		{asset.Car{ID: "M2", Brand: "Ducati", Owner: "Ana", Type: asset.VehicleMotorcycle}, "a motorcycle requires a positive engineCC"},
+		{asset.Car{ID: "C2", Brand: "Kia", Owner: "Ana", Axles: 2}, "a car does not take engineCC, axles or payloadKg"},
+		{asset.Car{ID: "B1", Brand: "Beneteau", Owner: "Ana", Type: "boat"}, `unknown vehicle type "boat", use car, motorcycle, truck or trailer`},
+		{asset.Car{ID: "T4", Owner: "Ana", Type: asset.VehicleTruck}, "All fields are required"},
+	}
+
+	for _, test := range tests {
+		err := ledger.Run(func(ctx contractapi.TransactionContextInterface) error {
+			return sc.CreateVehicle(ctx, test.vehicle)
+		})
+
+		if test.expectedErr == "" {
+			assert.NoError(t, err, test.vehicle.ID)
+		} else {
+			assert.EqualError(t, err, test.expectedErr, test.vehicle.ID)
+		}
+	}
+
+	ledger.SetCreator(identity(t, "WorkshopMSP", nil))
+	require.NoError(t, ledger.Run(func(ctx contractapi.TransactionContextInterface) error {
+		_, err := sc.RecordOdometer(ctx, "12", 1000, "workshop")
+		return err
+	}))
+
+	ledger.SetCreator(registrar(t))
+	require.NoError(t, ledger.Run(func(ctx contractapi.TransactionContextInterface) error {
+		return sc.TransferCart(ctx, "T1", "Logistics SL")
+	}))
+
+	require.NoError(t, ledger.Run(func(ctx contractapi.TransactionContextInterface) error {
+		trucks, err := sc.GetCarsByType(ctx, asset.VehicleTruck)
+		require.NoError(t, err)
+		assert.Equal(t, []*asset.Car{{ID: "T1", Brand: "Volvo", Owner: "Logistics SL", TransfersCount: 1, Type: asset.VehicleTruck, Axles: 3, PayloadKg: 18000, Revision: 1}}, trucks)
+
+		cars, err := sc.GetCarsByType(ctx, asset.VehicleCar)
+		require.NoError(t, err)
+		assert.Len(t, cars, 3, "cars without type and the ones created as car")
+		for _, car := range cars {
+			assert.Empty(t, car.Type)
+		}
+
+		_, err = sc.GetCarsByType(ctx, "boat")
+		assert.EqualError(t, err, `unknown vehicle type "boat"`)
+
+		queried, err := sc.QueryCars(ctx, `{"type":"car"}`)
+		require.NoError(t, err)
+		assert.Equal(t, cars, queried, "the odometer readings are not cars")
+
+		queried, err = sc.QueryCars(ctx, `{"type":"trailer","owner":"Cargo SA"}`)
+		require.NoError(t, err)
+		require.Len(t, queried, 1)
+		assert.Equal(t, "R1", queried[0].ID)
+		return nil
+	}))
+}
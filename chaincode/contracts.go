@@ -0,0 +1,140 @@
+package chaincode
+
+import (
+	"reflect"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/hyperledger/fabric-contract-api-go/metadata"
+)
+
+// Contract names, a transaction is invoked as name:function
+const (
+	RegistryContractName = "registry"
+	TransferContractName = "transfer"
+	AdminContractName    = "admin"
+	QueryContractName    = "query"
+)
+
+// ContractVersion is the version of the contracts published in the metadata
+const ContractVersion = "1.0.0"
+
+// namedContract exposes a subset of the transactions of SmartContract, the
+// other methods are ignored
+type namedContract struct {
+	SmartContract
+
+	transactions []string
+	evaluate     []string
+}
+
+// GetIgnoredFunctions ignores every method that is not a transaction of the
+// contract, helpers included
+func (n *namedContract) GetIgnoredFunctions() []string {
+	return ignoredFunctions(n, n.transactions)
+}
+
+// GetEvaluateTransactions marks the read-only transactions, they are
+// evaluated instead of submitted to the orderer
+func (n *namedContract) GetEvaluateTransactions() []string {
+	return n.evaluate
+}
+
+// RegistryContract registers cars, owners and the facts about them
+type RegistryContract struct {
+	namedContract
+}
+
+// TransferContract changes the owners of the cars and pays for them
+type TransferContract struct {
+	namedContract
+}
+
+// AdminContract holds the transactions of the network operators
+type AdminContract struct {
+	namedContract
+}
+
+// NewRegistryContract ...
+func NewRegistryContract() *RegistryContract {
+	return &RegistryContract{newNamedContract(RegistryContractName, "Cars and owners registry",
+		[]string{
+			"CreateCar", "CreateCars", "CreateVehicle",
+			"CreateOwner", "UpdateOwner",
+			"PlaceLien", "PlaceLienIfMatch", "ReleaseLien", "ReleaseLienIfMatch",
+			"ReportStolen", "ReportRecovered",
+			"RecordOdometer", "AddServiceRecord", "AnchorAttachment",
+		},
+		[]string{
+			"GetCar", "GetCars", "GetCarsPage", "GetCarsByOwner", "GetCarsByType", "QueryCars", "ExistCar", "GetCarRecord", "GetCarHistory",
+			"GetOwner", "GetOwnerPII",
+			"GetLien", "GetLiens", "GetStolenCars",
+			"GetOdometerReadings", "GetServiceRecords", "GetAttachment", "GetAttachments",
+		},
+	)}
+}
+
+// NewTransferContract checks every transfer with the hooks
+func NewTransferContract(hooks []TransferHook) *TransferContract {
+	contract := &TransferContract{newNamedContract(TransferContractName, "Car transfers and payments",
+		[]string{"TransferCart", "TransferCarIfMatch", "ApproveTransfer", "ApproveTransferIfMatch", "OfferCar", "PurchaseCar", "Transfer"},
+		[]string{"GetTransferApprovals", "BalanceOf", "ClientAccountID", "TotalSupply"},
+	)}
+	contract.TransferHooks = hooks
+	return contract
+}
+
+// NewAdminContract ...
+func NewAdminContract() *AdminContract {
+	return &AdminContract{newNamedContract(AdminContractName, "Network administration",
+		[]string{"InitLedger", "EraseOwner", "Mint"},
+		[]string{"QueryAuditRecords"},
+	)}
+}
+
+// NewQueryContract ...
+func NewQueryContract() *QueryContract {
+	contract := &QueryContract{}
+	contract.Name = QueryContractName
+	contract.Info = metadata.InfoMetadata{Title: "Read-only registry queries", Version: ContractVersion}
+	evaluate := contract.GetEvaluateTransactions()
+	auditContract(&contract.Contract, evaluate, evaluate)
+	return contract
+}
+
+// GetEvaluateTransactions marks every function of the query contract as
+// read-only
+func (q *QueryContract) GetEvaluateTransactions() []string {
+	return []string{"ReadCar", "CarExists", "GetCarsByOwner", "GetLiens", "IsTransferable"}
+}
+
+// NewChaincode registers the named contracts, registry is the default one
+func NewChaincode(hooks []TransferHook) (*contractapi.ContractChaincode, error) {
+	return contractapi.NewChaincode(NewRegistryContract(), NewTransferContract(hooks), NewAdminContract(), NewQueryContract())
+}
+
+func newNamedContract(name, title string, submit, evaluate []string) namedContract {
+	contract := namedContract{transactions: append(append([]string{}, submit...), evaluate...), evaluate: evaluate}
+	contract.Name = name
+	contract.Info = metadata.InfoMetadata{Title: title, Version: ContractVersion}
+	auditContract(&contract.Contract, contract.transactions, evaluate)
+	return contract
+}
+
+// ignoredFunctions lists the methods of the contract that are not in the
+// transactions
+func ignoredFunctions(contract interface{}, transactions []string) []string {
+	allowed := map[string]bool{}
+	for _, name := range transactions {
+		allowed[name] = true
+	}
+
+	var ignored []string
+	contractType := reflect.TypeOf(contract)
+	for i := 0; i < contractType.NumMethod(); i++ {
+		if name := contractType.Method(i).Name; !allowed[name] {
+			ignored = append(ignored, name)
+		}
+	}
+
+	return ignored
+}
@@ -0,0 +1,127 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/hyperledger/fabric-contract-api-go/metadata"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yimialmonte/chaincode-cars/chaincode/memstub"
+)
+
+var update = flag.Bool("update", false, "update metadata.json")
+
+func TestNamedContracts(t *testing.T) {
+	cc, err := NewChaincode(nil)
+	require.NoError(t, err)
+
+	ledger := memstub.New(memstub.WithCreator(identity(t, "Org1MSP", nil)))
+	stub := ledger.NewTransaction("admin:InitLedger")
+	response := cc.Invoke(stub)
+	require.Equal(t, int32(200), response.Status, response.Message)
+	require.NoError(t, stub.Commit())
+	juan := seedOwnerID(stub.GetTxID(), 0)
+
+	tests := []struct {
+		function        string
+		args            []string
+		expectedStatus  int32
+		expectedMessage string
+	}{
+		{"registry:GetCar", []string{"12"}, 200, ""},
+		{"GetCar", []string{"12"}, 200, ""},
+		{"query:ReadCar", []string{"12"}, 200, ""},
+		{"registry:GetCars", nil, 200, ""},
+		{"registry:GetCarsPage", []string{"1", ""}, 200, ""},
+		{"registry:GetCarRecord", []string{"12"}, 200, ""},
+		{"registry:GetCarHistory", []string{"12"}, 200, ""},
+		{"registry:GetCarsByType", []string{"truck"}, 200, ""},
+		{"registry:GetOwner", []string{juan}, 200, ""},
+		{"registry:GetLiens", []string{"12"}, 200, ""},
+		{"registry:GetStolenCars", nil, 200, ""},
+		{"registry:GetOdometerReadings", []string{"12"}, 200, ""},
+		{"registry:GetServiceRecords", []string{"12"}, 200, ""},
+		{"registry:GetAttachments", []string{"12"}, 200, ""},
+		{"transfer:GetTransferApprovals", []string{"12"}, 200, ""},
+		{"transfer:TotalSupply", nil, 200, ""},
+		{"query:GetCarsByOwner", []string{juan}, 200, ""},
+		{"query:IsTransferable", []string{"12"}, 200, ""},
+		{"registry:IsAbleToTransfer", []string{"12"}, 500, "unknown function IsAbleToTransfer in contract registry, the functions are CreateCar, CreateCars"},
+		{"registry:TransferCart", []string{"12", "Marcos"}, 500, "unknown function TransferCart in contract registry"},
+		{"transfer:GetCar", []string{"12"}, 500, "unknown function GetCar in contract transfer, the functions are TransferCart, TransferCarIfMatch"},
+		{"transfer:checkApprovals", []string{"12"}, 500, "unknown function checkApprovals in contract transfer"},
+		{"cars:GetCar", []string{"12"}, 500, "Contract not found with name cars"},
+	}
+
+	for _, test := range tests {
+		response := cc.Invoke(ledger.NewTransaction(test.function, test.args...))
+		assert.Equal(t, test.expectedStatus, response.Status, test.function)
+		assert.True(t, strings.HasPrefix(response.Message, test.expectedMessage), "%s: %s", test.function, response.Message)
+		if test.expectedMessage == "" {
+			assert.Empty(t, response.Message, test.function)
+		}
+	}
+}
+
+func TestMetadata(t *testing.T) {
+	cc, err := NewChaincode(nil)
+	require.NoError(t, err)
+
+	response := cc.Invoke(memstub.New().NewTransaction("org.hyperledger.fabric:GetMetadata"))
+	require.Equal(t, int32(200), response.Status, response.Message)
+
+	var chaincodeMetadata metadata.ContractChaincodeMetadata
+	require.NoError(t, json.Unmarshal(response.Payload, &chaincodeMetadata))
+
+	contracts := map[string][]string{}
+	for name, contract := range chaincodeMetadata.Contracts {
+		for _, transaction := range contract.Transactions {
+			contracts[name] = append(contracts[name], transaction.Name)
+		}
+	}
+
+	var all []string
+	for _, name := range []string{RegistryContractName, TransferContractName, AdminContractName} {
+		all = append(all, contracts[name]...)
+	}
+	sort.Strings(all)
+
+	// IsAbleToTransfer is a helper of TransferCart, not a transaction
+	excluded := append(ignoredFunctions(&contractapi.Contract{}, nil), "IsAbleToTransfer")
+	expected := ignoredFunctions(&SmartContract{}, excluded)
+	sort.Strings(expected)
+	assert.Equal(t, expected, all, "every transaction of SmartContract is in one named contract")
+	assert.True(t, chaincodeMetadata.Contracts[RegistryContractName].Default)
+
+	for _, transaction := range chaincodeMetadata.Contracts[QueryContractName].Transactions {
+		assert.Equal(t, []string{"evaluate"}, transaction.Tag, transaction.Name)
+	}
+}
+
+// TestMetadataFile checks that the committed metadata.json is the metadata
+// the chaincode generates
+func TestMetadataFile(t *testing.T) {
+	cc, err := NewChaincode(nil)
+	require.NoError(t, err)
+
+	response := cc.Invoke(memstub.New().NewTransaction("org.hyperledger.fabric:GetMetadata"))
+	require.Equal(t, int32(200), response.Status, response.Message)
+
+	formatted, err := json.MarshalIndent(json.RawMessage(response.Payload), "", "  ")
+	require.NoError(t, err)
+	formatted = append(formatted, '\n')
+
+	if *update {
+		require.NoError(t, ioutil.WriteFile("metadata.json", formatted, 0644))
+	}
+
+	golden, err := ioutil.ReadFile("metadata.json")
+	require.NoError(t, err)
+	assert.Equal(t, string(golden), string(formatted), "metadata.json is outdated, run go test ./chaincode -run TestMetadata -update")
+}
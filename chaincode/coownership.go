@@ -0,0 +1,159 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/yimialmonte/chaincode-cars/asset"
+)
+
+// approvalIndex stores the transfer approvals of the co-owners under
+// car~approval composite keys, one per co-owner
+const approvalIndex = "car~approval"
+
+// ApproveTransfer records the consent of a co-owner to transfer the car to
+// newOwner, a co-owned car is transferred once every co-owner approved. Only
+// the identity linked to the co-owner can approve for it.
+func (s *SmartContract) ApproveTransfer(ctx contractapi.TransactionContextInterface, id, owner, newOwner string) error {
+	car, err := s.GetCar(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if len(car.Owners) == 0 {
+		return fmt.Errorf("the car %s has a single owner, it does not need approvals", id)
+	}
+
+	if !car.HeldBy(owner) {
+		return fmt.Errorf("%s does not hold a share of the car %s", owner, id)
+	}
+
+	if err := requireOwnerIdentity(ctx, owner); err != nil {
+		return err
+	}
+
+	if ok, errTran := s.IsAbleToTransfer(car, newOwner); !ok {
+		return errTran
+	}
+
+	if err := requireOwner(ctx, newOwner); err != nil {
+		return err
+	}
+
+	now, err := txTime(ctx)
+	if err != nil {
+		return err
+	}
+
+	approvedBy, err := callerMSP(ctx)
+	if err != nil {
+		return err
+	}
+
+	approvalJSON, err := json.Marshal(asset.TransferApproval{
+		CarID:      id,
+		Owner:      owner,
+		NewOwner:   newOwner,
+		Revision:   car.Revision,
+		ApprovedBy: approvedBy,
+		ApprovedAt: now,
+	})
+	if err != nil {
+		return err
+	}
+
+	key, err := ctx.GetStub().CreateCompositeKey(approvalIndex, []string{id, owner})
+	if err != nil {
+		return err
+	}
+
+	return ctx.GetStub().PutState(key, approvalJSON)
+}
+
+// ApproveTransferIfMatch records the approval only when the car is at the
+// revision the co-owner decided on
+func (s *SmartContract) ApproveTransferIfMatch(ctx contractapi.TransactionContextInterface, id, owner, newOwner string, revision int) error {
+	car, err := s.GetCar(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := checkRevision(car, revision); err != nil {
+		return err
+	}
+
+	return s.ApproveTransfer(ctx, id, owner, newOwner)
+}
+
+// GetTransferApprovals ...
+func (s *SmartContract) GetTransferApprovals(ctx contractapi.TransactionContextInterface, id string) ([]*asset.TransferApproval, error) {
+	res, err := ctx.GetStub().GetStateByPartialCompositeKey(approvalIndex, []string{id})
+	if err != nil {
+		return nil, err
+	}
+	defer res.Close()
+
+	approvals := []*asset.TransferApproval{}
+	for res.HasNext() {
+		kv, err := res.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		var approval asset.TransferApproval
+		if err := json.Unmarshal(kv.Value, &approval); err != nil {
+			return nil, err
+		}
+
+		approvals = append(approvals, &approval)
+	}
+
+	return approvals, nil
+}
+
+// checkApprovals fails unless every co-owner approved the transfer to
+// newOwner at the current revision of the car
+func (s *SmartContract) checkApprovals(ctx contractapi.TransactionContextInterface, car *asset.Car, newOwner string) error {
+	approvals, err := s.GetTransferApprovals(ctx, car.ID)
+	if err != nil {
+		return err
+	}
+
+	approved := map[string]bool{}
+	for _, approval := range approvals {
+		if approval.NewOwner == newOwner && approval.Revision == car.Revision {
+			approved[approval.Owner] = true
+		}
+	}
+
+	var missing []string
+	for _, share := range car.Owners {
+		if !approved[share.Owner] {
+			missing = append(missing, share.Owner)
+		}
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("unable to process transaction, the transfer of car %s to %s needs the approval of %s", car.ID, newOwner, strings.Join(missing, ", "))
+	}
+
+	return nil
+}
+
+// clearApprovals deletes the approvals of the car once it is transferred
+func (s *SmartContract) clearApprovals(ctx contractapi.TransactionContextInterface, car *asset.Car) error {
+	for _, share := range car.Owners {
+		key, err := ctx.GetStub().CreateCompositeKey(approvalIndex, []string{car.ID, share.Owner})
+		if err != nil {
+			return err
+		}
+
+		if err := ctx.GetStub().DelState(key); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
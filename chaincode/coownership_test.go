@@ -0,0 +1,161 @@
+package chaincode
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yimialmonte/chaincode-cars/asset"
+	"github.com/yimialmonte/chaincode-cars/chaincode/memstub"
+)
+
+func TestCoOwnership(t *testing.T) {
+	ana := identity(t, "Org1MSP", nil)
+	max, err := memstub.NewIdentity("Org1MSP", "max", nil)
+	require.NoError(t, err)
+
+	ledger := memstub.New()
+	sc := &SmartContract{}
+	require.NoError(t, ledger.Run(sc.InitLedger))
+	createOwners(t, ledger, sc, "Peter", "Lucia")
+	linkOwner(t, ledger, sc, "Max", max)
+	linkOwner(t, ledger, sc, "Ana", ana)
+
+	shares := []asset.Share{{Owner: "Ana", Percent: 60}, {Owner: "Max", Percent: 40}}
+
+	tests := []struct {
+		owner       string
+		shares      []asset.Share
+		expectedErr string
+	}{
+		{"Ana", []asset.Share{{Owner: "Ana", Percent: 60}, {Owner: "Max", Percent: 30}}, "the shares sum 90%, they must sum 100%"},
+		{"Ana", []asset.Share{{Owner: "Ana", Percent: 50}, {Owner: "Ana", Percent: 50}}, "Ana holds more than one share"},
+		{"Ana", []asset.Share{{Owner: "Ana", Percent: 100}, {Owner: "Max", Percent: 0}}, "the share of Max must be between 1 and 100 percent"},
+		{"Ana", []asset.Share{{Owner: "", Percent: 100}}, "every share requires an owner"},
+		{"Juan", shares, "the owner Juan does not hold a share"},
+	}
+
+	for _, test := range tests {
+		err := ledger.Run(func(ctx contractapi.TransactionContextInterface) error {
+			return sc.CreateVehicle(ctx, asset.Car{ID: "J1", Brand: "Seat", Owner: test.owner, Owners: test.shares})
+		})
+		assert.EqualError(t, err, test.expectedErr)
+	}
+
+	require.NoError(t, ledger.Run(func(ctx contractapi.TransactionContextInterface) error {
+		return sc.CreateVehicle(ctx, asset.Car{ID: "J1", Brand: "Seat", Owner: "Ana", Owners: shares})
+	}))
+
+	err = ledger.Run(func(ctx contractapi.TransactionContextInterface) error {
+		return sc.ApproveTransfer(ctx, "12", "Juan", "Peter")
+	})
+	assert.EqualError(t, err, "the car 12 has a single owner, it does not need approvals")
+
+	err = ledger.Run(func(ctx contractapi.TransactionContextInterface) error {
+		return sc.ApproveTransfer(ctx, "J1", "Juan", "Peter")
+	})
+	assert.EqualError(t, err, "Juan does not hold a share of the car J1")
+
+	err = ledger.Run(func(ctx contractapi.TransactionContextInterface) error {
+		return sc.ApproveTransferIfMatch(ctx, "J1", "Ana", "Peter", 1)
+	})
+	assert.EqualError(t, err, "revision mismatch, car J1 is at revision 0, expected 1")
+
+	require.NoError(t, ledger.Run(func(ctx contractapi.TransactionContextInterface) error {
+		return sc.ApproveTransfer(ctx, "J1", "Ana", "Peter")
+	}))
+
+	err = ledger.Run(func(ctx contractapi.TransactionContextInterface) error {
+		return sc.TransferCart(ctx, "J1", "Peter")
+	})
+	assert.EqualError(t, err, "unable to process transaction, the transfer of car J1 to Peter needs the approval of Max")
+
+	err = ledger.Run(func(ctx contractapi.TransactionContextInterface) error {
+		return sc.ApproveTransfer(ctx, "J1", "Max", "Peter")
+	})
+	assert.True(t, errors.Is(err, ErrAccessDenied), "a co-owner can not approve for another one")
+	assert.EqualError(t, err, "access denied, the owner Max is linked to another identity")
+
+	ledger.SetCreator(max)
+	require.NoError(t, ledger.Run(func(ctx contractapi.TransactionContextInterface) error {
+		return sc.ApproveTransfer(ctx, "J1", "Max", "Lucia")
+	}))
+
+	err = ledger.Run(func(ctx contractapi.TransactionContextInterface) error {
+		return sc.TransferCart(ctx, "J1", "Peter")
+	})
+	assert.EqualError(t, err, "unable to process transaction, the transfer of car J1 to Peter needs the approval of Max", "approvals for another buyer do not count")
+
+	require.NoError(t, ledger.Run(func(ctx contractapi.TransactionContextInterface) error {
+		cars, err := sc.GetCarsByOwner(ctx, "Max")
+		require.NoError(t, err)
+		require.Len(t, cars, 1)
+		assert.Equal(t, "J1", cars[0].ID)
+
+		queried, err := sc.QueryCars(ctx, `{"owner":"Max"}`)
+		require.NoError(t, err)
+		assert.Equal(t, cars, queried)
+
+		approvals, err := sc.GetTransferApprovals(ctx, "J1")
+		require.NoError(t, err)
+		assert.Len(t, approvals, 2)
+		return sc.ApproveTransfer(ctx, "J1", "Max", "Peter")
+	}))
+
+	require.NoError(t, ledger.Run(func(ctx contractapi.TransactionContextInterface) error {
+		return sc.TransferCart(ctx, "J1", "Peter")
+	}))
+
+	require.NoError(t, ledger.Run(func(ctx contractapi.TransactionContextInterface) error {
+		car, err := sc.GetCar(ctx, "J1")
+		require.NoError(t, err)
+		assert.Equal(t, &asset.Car{ID: "J1", Brand: "Seat", Owner: "Peter", TransfersCount: 1, Revision: 1}, car)
+
+		approvals, err := sc.GetTransferApprovals(ctx, "J1")
+		require.NoError(t, err)
+		assert.Empty(t, approvals)
+
+		cars, err := sc.GetCarsByOwner(ctx, "Max")
+		require.NoError(t, err)
+		assert.Empty(t, cars)
+		return nil
+	}))
+}
+
+func TestCoOwnerBuyout(t *testing.T) {
+	owners := map[string][]byte{"Ana": identity(t, "Org1MSP", nil)}
+	max, err := memstub.NewIdentity("Org1MSP", "max", nil)
+	require.NoError(t, err)
+	owners["Max"] = max
+
+	ledger := memstub.New()
+	sc := &SmartContract{}
+	for _, owner := range []string{"Ana", "Max"} {
+		linkOwner(t, ledger, sc, owner, owners[owner])
+	}
+	require.NoError(t, ledger.Run(func(ctx contractapi.TransactionContextInterface) error {
+		return sc.CreateVehicle(ctx, asset.Car{ID: "J1", Brand: "Seat", Owner: "Ana", Owners: []asset.Share{{Owner: "Ana", Percent: 50}, {Owner: "Max", Percent: 50}}})
+	}))
+
+	for _, owner := range []string{"Ana", "Max"} {
+		owner := owner
+		ledger.SetCreator(owners[owner])
+		require.NoError(t, ledger.Run(func(ctx contractapi.TransactionContextInterface) error {
+			return sc.ApproveTransfer(ctx, "J1", owner, "Ana")
+		}))
+	}
+
+	require.NoError(t, ledger.Run(func(ctx contractapi.TransactionContextInterface) error {
+		return sc.TransferCart(ctx, "J1", "Ana")
+	}))
+
+	require.NoError(t, ledger.Run(func(ctx contractapi.TransactionContextInterface) error {
+		car, err := sc.GetCar(ctx, "J1")
+		require.NoError(t, err)
+		assert.Equal(t, "Ana", car.Owner)
+		assert.Empty(t, car.Owners)
+		return nil
+	}))
+}
@@ -0,0 +1,80 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/yimialmonte/chaincode-cars/asset"
+)
+
+// ErrTransferRefused is returned when a transfer hook refuses the transfer
+var ErrTransferRefused = errors.New("transfer refused")
+
+// TransferHook is an external chaincode asked to clear every transfer, like
+// a tax clearance check. Function is called with the car ID, the current
+// owner and the new owner and must answer with an OK status. There is no
+// timeout per hook, the execute timeout of the peer is the only bound on a
+// hook that does not answer.
+type TransferHook struct {
+	Chaincode string
+	Function  string
+	Channel   string
+}
+
+// TransferHooksFromEnv reads the hooks from CARS_TRANSFER_HOOKS, a JSON
+// array like [{"chaincode":"tax","function":"CheckClearance"}]
+func TransferHooksFromEnv() ([]TransferHook, error) {
+	spec := os.Getenv("CARS_TRANSFER_HOOKS")
+	if spec == "" {
+		return nil, nil
+	}
+
+	var configs []struct {
+		Chaincode string `json:"chaincode"`
+		Function  string `json:"function"`
+		Channel   string `json:"channel"`
+	}
+	if err := json.Unmarshal([]byte(spec), &configs); err != nil {
+		return nil, fmt.Errorf("invalid CARS_TRANSFER_HOOKS, %v", err)
+	}
+
+	hooks := make([]TransferHook, 0, len(configs))
+	for _, config := range configs {
+		if config.Chaincode == "" || config.Function == "" {
+			return nil, fmt.Errorf("invalid CARS_TRANSFER_HOOKS, the chaincode and function are required")
+		}
+
+		hooks = append(hooks, TransferHook{Chaincode: config.Chaincode, Function: config.Function, Channel: config.Channel})
+	}
+
+	return hooks, nil
+}
+
+// checkTransferHooks asks every hook to clear the transfer of the car
+func (s *SmartContract) checkTransferHooks(ctx contractapi.TransactionContextInterface, car *asset.Car, newOwner string) error {
+	for _, hook := range s.TransferHooks {
+		if err := hook.check(ctx.GetStub(), car, newOwner); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// check invokes the hook in the transaction. The shim is not safe for
+// concurrent use, so the call cannot be raced against a timer: it is
+// synchronous and only the execute timeout of the peer bounds it, failing
+// the whole transaction.
+func (h TransferHook) check(stub shim.ChaincodeStubInterface, car *asset.Car, newOwner string) error {
+	args := [][]byte{[]byte(h.Function), []byte(car.ID), []byte(car.Owner), []byte(newOwner)}
+	response := stub.InvokeChaincode(h.Chaincode, args, h.Channel)
+	if response.Status != shim.OK {
+		return fmt.Errorf("%w by %s, %s answered %d: %s", ErrTransferRefused, h.Chaincode, h.Function, response.Status, response.Message)
+	}
+
+	return nil
+}
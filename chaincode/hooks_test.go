@@ -0,0 +1,104 @@
+package chaincode
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yimialmonte/chaincode-cars/chaincode/memstub"
+)
+
+func TestTransferHooks(t *testing.T) {
+	var received [][]byte
+	clearance := map[string]bool{"12": true}
+
+	ledger := memstub.New(memstub.WithCreator(registrar(t)))
+	ledger.RegisterChaincode("tax", func(args [][]byte) pb.Response {
+		received = args
+		if !clearance[string(args[1])] {
+			return shim.Error("car " + string(args[1]) + " has unpaid taxes")
+		}
+		return shim.Success(nil)
+	})
+
+	sc := &SmartContract{TransferHooks: []TransferHook{{Chaincode: "tax", Function: "CheckClearance"}}}
+	require.NoError(t, ledger.Run(sc.InitLedger))
+	juan, marcos := seededOwners(t, ledger)
+	createOwners(t, ledger, sc, "Max")
+
+	require.NoError(t, ledger.Run(func(ctx contractapi.TransactionContextInterface) error {
+		return sc.TransferCart(ctx, "12", "Max")
+	}))
+	assert.Equal(t, [][]byte{[]byte("CheckClearance"), []byte("12"), []byte(juan), []byte("Max")}, received)
+
+	tests := []struct {
+		hooks       []TransferHook
+		expectedErr error
+		expectedMsg string
+	}{
+		{
+			[]TransferHook{{Chaincode: "tax", Function: "CheckClearance"}},
+			ErrTransferRefused,
+			"transfer refused by tax, CheckClearance answered 500: car 22 has unpaid taxes",
+		},
+		{
+			[]TransferHook{{Chaincode: "insurance", Function: "Check"}},
+			ErrTransferRefused,
+			"transfer refused by insurance, Check answered 500: chaincode insurance not found",
+		},
+	}
+
+	for _, test := range tests {
+		sc := &SmartContract{TransferHooks: test.hooks}
+		err := ledger.Run(func(ctx contractapi.TransactionContextInterface) error {
+			return sc.TransferCart(ctx, "22", "Max")
+		})
+		assert.True(t, errors.Is(err, test.expectedErr))
+		assert.EqualError(t, err, test.expectedMsg)
+	}
+
+	require.NoError(t, ledger.Run(func(ctx contractapi.TransactionContextInterface) error {
+		car, err := sc.GetCar(ctx, "22")
+		require.NoError(t, err)
+		assert.Equal(t, marcos, car.Owner, "a refused transfer is not applied")
+		return nil
+	}))
+}
+
+func TestTransferHooksFromEnv(t *testing.T) {
+	tests := []struct {
+		spec        string
+		expected    []TransferHook
+		expectedErr string
+	}{
+		{"", nil, ""},
+		{
+			`[{"chaincode":"tax","function":"CheckClearance"},{"chaincode":"insurance","function":"Check","channel":"other"}]`,
+			[]TransferHook{
+				{Chaincode: "tax", Function: "CheckClearance"},
+				{Chaincode: "insurance", Function: "Check", Channel: "other"},
+			},
+			"",
+		},
+		{`[{"chaincode":"tax"}]`, nil, "invalid CARS_TRANSFER_HOOKS, the chaincode and function are required"},
+		{`{`, nil, "invalid CARS_TRANSFER_HOOKS, unexpected end of JSON input"},
+	}
+
+	defer os.Unsetenv("CARS_TRANSFER_HOOKS")
+	for _, test := range tests {
+		os.Setenv("CARS_TRANSFER_HOOKS", test.spec)
+		hooks, err := TransferHooksFromEnv()
+		if test.expectedErr != "" {
+			assert.EqualError(t, err, test.expectedErr)
+			continue
+		}
+
+		require.NoError(t, err)
+		assert.Equal(t, test.expected, hooks)
+	}
+}
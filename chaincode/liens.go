@@ -0,0 +1,193 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/yimialmonte/chaincode-cars/asset"
+)
+
+// Liens are stored under car~lien composite keys so the liens of a car are
+// found with a partial key
+const (
+	lienIndex  = "car~lien"
+	lenderRole = "lender"
+)
+
+// PlaceLien encumbers the car, only callers with the lender role can place
+// liens and the lien is owned by their organization
+func (s *SmartContract) PlaceLien(ctx contractapi.TransactionContextInterface, carID string, amount int64) (*asset.Lien, error) {
+	lender, err := requireRole(ctx, lenderRole)
+	if err != nil {
+		return nil, err
+	}
+
+	if amount <= 0 {
+		return nil, fmt.Errorf("the lien amount must be greater than zero")
+	}
+
+	car, err := s.GetCar(ctx, carID)
+	if err != nil {
+		return nil, err
+	}
+
+	now, err := txTime(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	lien := &asset.Lien{
+		ID:       ctx.GetStub().GetTxID(),
+		CarID:    carID,
+		Lender:   lender,
+		Amount:   amount,
+		Status:   asset.LienActive,
+		PlacedAt: now,
+	}
+
+	if err := putLien(ctx, lien); err != nil {
+		return nil, err
+	}
+
+	car.ActiveLiens++
+	car.Revision++
+	return lien, putCar(ctx, car)
+}
+
+// PlaceLienIfMatch places the lien only when the car is at the expected
+// revision
+func (s *SmartContract) PlaceLienIfMatch(ctx contractapi.TransactionContextInterface, carID string, amount int64, revision int) (*asset.Lien, error) {
+	car, err := s.GetCar(ctx, carID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkRevision(car, revision); err != nil {
+		return nil, err
+	}
+
+	return s.PlaceLien(ctx, carID, amount)
+}
+
+// ReleaseLien releases an active lien, only the lender organization that
+// placed it can release it
+func (s *SmartContract) ReleaseLien(ctx contractapi.TransactionContextInterface, carID, lienID string) error {
+	lender, err := requireRole(ctx, lenderRole)
+	if err != nil {
+		return err
+	}
+
+	lien, err := s.GetLien(ctx, carID, lienID)
+	if err != nil {
+		return err
+	}
+
+	if lien.Lender != lender {
+		return fmt.Errorf("%w, the lien %s belongs to %s", ErrAccessDenied, lienID, lien.Lender)
+	}
+
+	if lien.Status != asset.LienActive {
+		return fmt.Errorf("the lien %s is already %s", lienID, lien.Status)
+	}
+
+	car, err := s.GetCar(ctx, carID)
+	if err != nil {
+		return err
+	}
+
+	lien.Status = asset.LienReleased
+	lien.ReleasedAt, err = txTime(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := putLien(ctx, lien); err != nil {
+		return err
+	}
+
+	if car.ActiveLiens > 0 {
+		car.ActiveLiens--
+	}
+	car.Revision++
+	return putCar(ctx, car)
+}
+
+// ReleaseLienIfMatch releases the lien only when the car is at the expected
+// revision
+func (s *SmartContract) ReleaseLienIfMatch(ctx contractapi.TransactionContextInterface, carID, lienID string, revision int) error {
+	car, err := s.GetCar(ctx, carID)
+	if err != nil {
+		return err
+	}
+
+	if err := checkRevision(car, revision); err != nil {
+		return err
+	}
+
+	return s.ReleaseLien(ctx, carID, lienID)
+}
+
+// GetLien ...
+func (s *SmartContract) GetLien(ctx contractapi.TransactionContextInterface, carID, lienID string) (*asset.Lien, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(lienIndex, []string{carID, lienID})
+	if err != nil {
+		return nil, err
+	}
+
+	lienJSON, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("error getting lien, %v", err)
+	}
+
+	if lienJSON == nil {
+		return nil, fmt.Errorf("lien does not exist ID: %s", lienID)
+	}
+
+	var lien asset.Lien
+	if err := json.Unmarshal(lienJSON, &lien); err != nil {
+		return nil, err
+	}
+
+	return &lien, nil
+}
+
+// GetLiens returns every lien of the car, released ones included
+func (s *SmartContract) GetLiens(ctx contractapi.TransactionContextInterface, carID string) ([]*asset.Lien, error) {
+	res, err := ctx.GetStub().GetStateByPartialCompositeKey(lienIndex, []string{carID})
+	if err != nil {
+		return nil, err
+	}
+	defer res.Close()
+
+	liens := []*asset.Lien{}
+	for res.HasNext() {
+		kv, err := res.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		var lien asset.Lien
+		if err := json.Unmarshal(kv.Value, &lien); err != nil {
+			return nil, err
+		}
+
+		liens = append(liens, &lien)
+	}
+
+	return liens, nil
+}
+
+func putLien(ctx contractapi.TransactionContextInterface, lien *asset.Lien) error {
+	key, err := ctx.GetStub().CreateCompositeKey(lienIndex, []string{lien.CarID, lien.ID})
+	if err != nil {
+		return err
+	}
+
+	lienJSON, err := json.Marshal(lien)
+	if err != nil {
+		return err
+	}
+
+	return ctx.GetStub().PutState(key, lienJSON)
+}
@@ -0,0 +1,106 @@
+package chaincode
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yimialmonte/chaincode-cars/asset"
+	"github.com/yimialmonte/chaincode-cars/chaincode/memstub"
+)
+
+func identity(t *testing.T, mspID string, attrs map[string]string) []byte {
+	creator, err := memstub.NewIdentity(mspID, "user1", attrs)
+	require.NoError(t, err)
+	return creator
+}
+
+func TestLiens(t *testing.T) {
+	bank := identity(t, "BankMSP", map[string]string{"role": "lender"})
+	otherBank := identity(t, "OtherBankMSP", map[string]string{"role": "lender"})
+	dealer := registrar(t)
+
+	ledger := memstub.New(memstub.WithCreator(dealer))
+	sc := &SmartContract{}
+	require.NoError(t, ledger.Run(sc.InitLedger))
+	createOwners(t, ledger, sc, "Max")
+
+	err := ledger.Run(func(ctx contractapi.TransactionContextInterface) error {
+		_, err := sc.PlaceLien(ctx, "12", 1000)
+		return err
+	})
+	assert.True(t, errors.Is(err, ErrAccessDenied))
+
+	ledger.SetCreator(bank)
+	var lien *asset.Lien
+	require.NoError(t, ledger.Run(func(ctx contractapi.TransactionContextInterface) (err error) {
+		lien, err = sc.PlaceLien(ctx, "12", 1500000)
+		return err
+	}))
+	assert.Equal(t, "BankMSP", lien.Lender)
+	assert.Equal(t, asset.LienActive, lien.Status)
+
+	err = ledger.Run(func(ctx contractapi.TransactionContextInterface) error {
+		_, err := sc.PlaceLienIfMatch(ctx, "12", 1000, 0)
+		return err
+	})
+	assert.True(t, errors.Is(err, ErrRevisionMismatch), "the car is at revision 1")
+
+	err = ledger.Run(func(ctx contractapi.TransactionContextInterface) error {
+		return sc.ReleaseLienIfMatch(ctx, "12", lien.ID, 0)
+	})
+	assert.True(t, errors.Is(err, ErrRevisionMismatch))
+
+	err = ledger.Run(func(ctx contractapi.TransactionContextInterface) error {
+		_, err := sc.PlaceLien(ctx, "12", 0)
+		return err
+	})
+	assert.EqualError(t, err, "the lien amount must be greater than zero")
+
+	ledger.SetCreator(dealer)
+	err = ledger.Run(func(ctx contractapi.TransactionContextInterface) error {
+		return sc.TransferCart(ctx, "12", "Max")
+	})
+	assert.EqualError(t, err, "unable to process transaction, car 12 has 1 active liens")
+
+	ledger.SetCreator(otherBank)
+	err = ledger.Run(func(ctx contractapi.TransactionContextInterface) error {
+		return sc.ReleaseLien(ctx, "12", lien.ID)
+	})
+	assert.True(t, errors.Is(err, ErrAccessDenied))
+
+	ledger.SetCreator(bank)
+	require.NoError(t, ledger.Run(func(ctx contractapi.TransactionContextInterface) error {
+		return sc.ReleaseLien(ctx, "12", lien.ID)
+	}))
+
+	err = ledger.Run(func(ctx contractapi.TransactionContextInterface) error {
+		return sc.ReleaseLien(ctx, "12", lien.ID)
+	})
+	assert.EqualError(t, err, "the lien "+lien.ID+" is already released")
+
+	ledger.SetCreator(dealer)
+	require.NoError(t, ledger.Run(func(ctx contractapi.TransactionContextInterface) error {
+		return sc.TransferCart(ctx, "12", "Max")
+	}))
+
+	require.NoError(t, ledger.Run(func(ctx contractapi.TransactionContextInterface) error {
+		liens, err := sc.GetLiens(ctx, "12")
+		require.NoError(t, err)
+		require.Len(t, liens, 1)
+		assert.Equal(t, asset.LienReleased, liens[0].Status)
+		assert.NotEmpty(t, liens[0].ReleasedAt)
+
+		liens, err = sc.GetLiens(ctx, "22")
+		require.NoError(t, err)
+		assert.Empty(t, liens)
+
+		car, err := sc.GetCar(ctx, "12")
+		require.NoError(t, err)
+		assert.Equal(t, 0, car.ActiveLiens)
+		assert.Equal(t, 3, car.Revision)
+		return nil
+	}))
+}
@@ -0,0 +1,149 @@
+package memstub
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// FileStore persists the blocks of a ledger in an append-only file, one
+// JSON block per line
+type FileStore struct {
+	mu   sync.Mutex
+	path string
+	file *os.File
+}
+
+// OpenFileStore opens or creates the ledger file at path
+func OpenFileStore(path string) (*FileStore, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FileStore{path: path, file: file}, nil
+}
+
+// Load reads every block of the file
+func (f *FileStore) Load() ([]*Block, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.load()
+}
+
+func (f *FileStore) load() ([]*Block, error) {
+	if _, err := f.file.Seek(0, 0); err != nil {
+		return nil, err
+	}
+
+	var blocks []*Block
+	scanner := bufio.NewScanner(f.file)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		var block Block
+		if err := json.Unmarshal(scanner.Bytes(), &block); err != nil {
+			return nil, fmt.Errorf("corrupted ledger file at block %d, %v", len(blocks)+1, err)
+		}
+		blocks = append(blocks, &block)
+	}
+
+	return blocks, scanner.Err()
+}
+
+// Append writes the block and syncs the file
+func (f *FileStore) Append(block *Block) error {
+	blockJSON, err := json.Marshal(block)
+	if err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, err := f.file.Write(append(blockJSON, '\n')); err != nil {
+		return err
+	}
+
+	return f.file.Sync()
+}
+
+// Purge removes the private writes of the key from every block, the file
+// is rewritten to a temporary one renamed over it
+func (f *FileStore) Purge(collection, key string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	blocks, err := f.load()
+	if err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(f.path), filepath.Base(f.path)+".purge-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	writer := bufio.NewWriter(tmp)
+	for _, block := range blocks {
+		var kept []KVWrite
+		for _, w := range block.PrivateWrites[collection] {
+			if w.Key != key {
+				kept = append(kept, w)
+			}
+		}
+
+		if len(kept) > 0 {
+			block.PrivateWrites[collection] = kept
+		} else {
+			delete(block.PrivateWrites, collection)
+		}
+
+		blockJSON, err := json.Marshal(block)
+		if err != nil {
+			tmp.Close()
+			return err
+		}
+
+		if _, err := writer.Write(append(blockJSON, '\n')); err != nil {
+			tmp.Close()
+			return err
+		}
+	}
+
+	if err := writer.Flush(); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmp.Name(), f.path); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(f.path, os.O_RDWR|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+
+	f.file.Close()
+	f.file = file
+	return nil
+}
+
+// Close ...
+func (f *FileStore) Close() error {
+	return f.file.Close()
+}
@@ -0,0 +1,67 @@
+package memstub
+
+import (
+	"errors"
+
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+)
+
+var errNoMoreResults = errors.New("no more results")
+
+type iterator struct {
+	kvs    []*queryresult.KV
+	closed bool
+}
+
+func newIterator(kvs []*queryresult.KV) *iterator {
+	return &iterator{kvs: kvs}
+}
+
+// HasNext ...
+func (it *iterator) HasNext() bool {
+	return !it.closed && len(it.kvs) > 0
+}
+
+// Next ...
+func (it *iterator) Next() (*queryresult.KV, error) {
+	if !it.HasNext() {
+		return nil, errNoMoreResults
+	}
+
+	kv := it.kvs[0]
+	it.kvs = it.kvs[1:]
+	return kv, nil
+}
+
+// Close ...
+func (it *iterator) Close() error {
+	it.closed = true
+	return nil
+}
+
+type historyIterator struct {
+	mods   []*queryresult.KeyModification
+	closed bool
+}
+
+// HasNext ...
+func (it *historyIterator) HasNext() bool {
+	return !it.closed && len(it.mods) > 0
+}
+
+// Next ...
+func (it *historyIterator) Next() (*queryresult.KeyModification, error) {
+	if !it.HasNext() {
+		return nil, errNoMoreResults
+	}
+
+	mod := it.mods[0]
+	it.mods = it.mods[1:]
+	return mod, nil
+}
+
+// Close ...
+func (it *historyIterator) Close() error {
+	it.closed = true
+	return nil
+}
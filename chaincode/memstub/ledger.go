@@ -0,0 +1,355 @@
+// Package memstub provides an in-memory world state implementing
+// shim.ChaincodeStubInterface, so contracts can run multi-step scenarios
+// against a ledger that behaves like the peer one. Commit validates the
+// read keys and the read ranges like a peer, the rich queries are not
+// validated.
+package memstub
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/hyperledger/fabric-chaincode-go/pkg/cid"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+)
+
+// ErrReadConflict is returned by Commit when a key read by the transaction
+// was modified by another transaction committed in the meantime
+var ErrReadConflict = errors.New("MVCC_READ_CONFLICT")
+
+// ErrPhantomRead is returned by Commit when a key was added to, modified in
+// or deleted from a range read by the transaction in the meantime
+var ErrPhantomRead = errors.New("PHANTOM_READ_CONFLICT")
+
+// KVWrite is a write of a committed transaction
+type KVWrite struct {
+	Key      string `json:"key"`
+	Value    []byte `json:"value,omitempty"`
+	IsDelete bool   `json:"isDelete,omitempty"`
+}
+
+// Block is a committed transaction as persisted by a Store
+type Block struct {
+	TxID          string               `json:"txId"`
+	Timestamp     time.Time            `json:"timestamp"`
+	Writes        []KVWrite            `json:"writes"`
+	PrivateWrites map[string][]KVWrite `json:"privateWrites,omitempty"`
+}
+
+// Store persists the committed transactions of a ledger
+type Store interface {
+	Load() ([]*Block, error)
+	Append(block *Block) error
+}
+
+// Purger is implemented by the stores able to forget private data, like the
+// purge of Fabric 2.5
+type Purger interface {
+	Purge(collection, key string) error
+}
+
+// Ledger is the committed state shared by the transactions
+type Ledger struct {
+	mu          sync.RWMutex
+	channel     string
+	clock       func() time.Time
+	creator     []byte
+	state       map[string][]byte
+	versions    map[string]uint64
+	height      uint64
+	blocks      map[string]uint64
+	history     map[string][]*queryresult.KeyModification
+	private     map[string]map[string][]byte
+	validations map[string][]byte
+	events      []*pb.ChaincodeEvent
+	chaincodes  map[string]func(args [][]byte) pb.Response
+	store       Store
+}
+
+// Option ...
+type Option func(l *Ledger)
+
+// WithClock sets the clock used for the transaction timestamps
+func WithClock(clock func() time.Time) Option {
+	return func(l *Ledger) {
+		l.clock = clock
+	}
+}
+
+// WithChannel sets the channel returned by the stubs
+func WithChannel(channel string) Option {
+	return func(l *Ledger) {
+		l.channel = channel
+	}
+}
+
+// WithCreator sets the serialized identity submitting the transactions
+func WithCreator(creator []byte) Option {
+	return func(l *Ledger) {
+		l.creator = creator
+	}
+}
+
+// New ...
+func New(opts ...Option) *Ledger {
+	l := &Ledger{
+		channel:     "mychannel",
+		clock:       time.Now,
+		state:       map[string][]byte{},
+		versions:    map[string]uint64{},
+		blocks:      map[string]uint64{},
+		history:     map[string][]*queryresult.KeyModification{},
+		private:     map[string]map[string][]byte{},
+		validations: map[string][]byte{},
+		chaincodes:  map[string]func(args [][]byte) pb.Response{},
+	}
+
+	for _, opt := range opts {
+		opt(l)
+	}
+
+	return l
+}
+
+// Open returns a ledger whose transactions are replayed from and persisted
+// to store
+func Open(store Store, opts ...Option) (*Ledger, error) {
+	l := New(opts...)
+
+	blocks, err := store.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, block := range blocks {
+		if err := l.apply(block); err != nil {
+			return nil, err
+		}
+	}
+
+	l.store = store
+	return l, nil
+}
+
+// BlockNumber returns the number of the block holding the transaction
+func (l *Ledger) BlockNumber(txID string) (uint64, bool) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	number, ok := l.blocks[txID]
+	return number, ok
+}
+
+// SetCreator changes the identity submitting the next transactions
+func (l *Ledger) SetCreator(creator []byte) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.creator = creator
+}
+
+// RegisterChaincode makes a chaincode callable through InvokeChaincode
+func (l *Ledger) RegisterChaincode(name string, invoke func(args [][]byte) pb.Response) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.chaincodes[name] = invoke
+}
+
+// Events returns the events of the committed transactions
+func (l *Ledger) Events() []*pb.ChaincodeEvent {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return append([]*pb.ChaincodeEvent{}, l.events...)
+}
+
+// NewTransaction starts a transaction, its writes are only visible to other
+// transactions after Commit
+func (l *Ledger) NewTransaction(function string, args ...string) *Stub {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	stubArgs := [][]byte{[]byte(function)}
+	for _, arg := range args {
+		stubArgs = append(stubArgs, []byte(arg))
+	}
+
+	return &Stub{
+		ledger:        l,
+		txID:          newTxID(),
+		timestamp:     l.clock(),
+		creator:       l.creator,
+		args:          stubArgs,
+		reads:         map[string]uint64{},
+		ranges:        []*rangeRead{},
+		writes:        map[string]*write{},
+		privateWrites: map[string]map[string]*write{},
+	}
+}
+
+// Run executes fn in a new transaction, the writes are committed when fn
+// succeeds and discarded when it returns an error
+func (l *Ledger) Run(fn func(ctx contractapi.TransactionContextInterface) error) error {
+	return l.RunTransient(nil, fn)
+}
+
+// RunTransient executes fn like Run with the transient data of the proposal
+func (l *Ledger) RunTransient(transient map[string][]byte, fn func(ctx contractapi.TransactionContextInterface) error) error {
+	stub := l.NewTransaction("")
+	stub.SetTransient(transient)
+	ctx := &contractapi.TransactionContext{}
+	ctx.SetStub(stub)
+
+	if stub.creator != nil {
+		identity, err := cid.New(stub)
+		if err != nil {
+			return err
+		}
+		ctx.SetClientIdentity(identity)
+	}
+
+	if err := fn(ctx); err != nil {
+		return err
+	}
+
+	return stub.Commit()
+}
+
+// PurgePrivateData removes every version of the private key, from the
+// state and from the store when it is a Purger
+func (l *Ledger) PurgePrivateData(collection, key string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if purger, ok := l.store.(Purger); ok {
+		if err := purger.Purge(collection, key); err != nil {
+			return err
+		}
+	}
+
+	delete(l.private[collection], key)
+	return nil
+}
+
+func (l *Ledger) commit(s *Stub) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for key, version := range s.reads {
+		if l.versions[key] != version {
+			return fmt.Errorf("%w, key %s was modified", ErrReadConflict, key)
+		}
+	}
+
+	for _, r := range s.ranges {
+		if !r.unchanged(l) {
+			return fmt.Errorf("%w, the range %q to %q was modified", ErrPhantomRead, r.startKey, r.endKey)
+		}
+	}
+
+	block := &Block{TxID: s.txID, Timestamp: s.timestamp}
+	for _, key := range sortedKeys(s.writes) {
+		w := s.writes[key]
+		block.Writes = append(block.Writes, KVWrite{Key: key, Value: w.value, IsDelete: w.delete})
+	}
+
+	for collection, writes := range s.privateWrites {
+		if block.PrivateWrites == nil {
+			block.PrivateWrites = map[string][]KVWrite{}
+		}
+
+		for _, key := range sortedKeys(writes) {
+			w := writes[key]
+			block.PrivateWrites[collection] = append(block.PrivateWrites[collection], KVWrite{Key: key, Value: w.value, IsDelete: w.delete})
+		}
+	}
+
+	if len(block.Writes) > 0 || len(block.PrivateWrites) > 0 {
+		if l.store != nil {
+			if err := l.store.Append(block); err != nil {
+				return err
+			}
+		}
+
+		if err := l.apply(block); err != nil {
+			return err
+		}
+	}
+
+	for key, ep := range s.validations {
+		l.validations[key] = ep
+	}
+
+	if s.event != nil {
+		l.events = append(l.events, s.event)
+	}
+
+	return nil
+}
+
+// apply writes the block to the world state and the history
+func (l *Ledger) apply(block *Block) error {
+	ts, err := ptypes.TimestampProto(block.Timestamp)
+	if err != nil {
+		return err
+	}
+
+	l.height++
+	l.blocks[block.TxID] = l.height
+
+	for _, w := range block.Writes {
+		if w.IsDelete {
+			delete(l.state, w.Key)
+		} else {
+			l.state[w.Key] = w.Value
+		}
+		l.versions[w.Key]++
+
+		l.history[w.Key] = append(l.history[w.Key], &queryresult.KeyModification{
+			TxId:      block.TxID,
+			Value:     w.Value,
+			Timestamp: ts,
+			IsDelete:  w.IsDelete,
+		})
+	}
+
+	for collection, writes := range block.PrivateWrites {
+		if l.private[collection] == nil {
+			l.private[collection] = map[string][]byte{}
+		}
+
+		for _, w := range writes {
+			if w.IsDelete {
+				delete(l.private[collection], w.Key)
+				continue
+			}
+			l.private[collection][w.Key] = w.Value
+		}
+	}
+
+	return nil
+}
+
+func sortedKeys(m map[string]*write) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// newTxID returns a random hex ID like the ones of the peer
+func newTxID() string {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,321 @@
+package memstub
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+)
+
+// query is the subset of a CouchDB Mango query understood by the ledger
+type query struct {
+	Selector map[string]interface{} `json:"selector"`
+	Sort     []interface{}          `json:"sort"`
+	Limit    int                    `json:"limit"`
+	Fields   []string               `json:"fields"`
+	UseIndex interface{}            `json:"use_index"`
+}
+
+func parseQuery(raw string) (*query, error) {
+	var q query
+	if err := json.Unmarshal([]byte(raw), &q); err != nil {
+		return nil, fmt.Errorf("invalid query, %v", err)
+	}
+
+	if q.Selector == nil {
+		return nil, fmt.Errorf("invalid query, selector is required")
+	}
+
+	return &q, nil
+}
+
+// filter keeps the JSON values matching the selector, sorted and limited
+func (q *query) filter(kvs []*queryresult.KV) ([]*queryresult.KV, error) {
+	var found []*queryresult.KV
+	docs := map[*queryresult.KV]map[string]interface{}{}
+	for _, kv := range kvs {
+		var doc map[string]interface{}
+		if err := json.Unmarshal(kv.Value, &doc); err != nil {
+			continue
+		}
+
+		ok, err := Match(q.Selector, doc)
+		if err != nil {
+			return nil, err
+		}
+
+		if ok {
+			found = append(found, kv)
+			docs[kv] = doc
+		}
+	}
+
+	for i := len(q.Sort) - 1; i >= 0; i-- {
+		field, desc, err := sortField(q.Sort[i])
+		if err != nil {
+			return nil, err
+		}
+
+		sort.SliceStable(found, func(a, b int) bool {
+			va, _ := lookup(docs[found[a]], field)
+			vb, _ := lookup(docs[found[b]], field)
+			if desc {
+				return compare(vb, va) < 0
+			}
+			return compare(va, vb) < 0
+		})
+	}
+
+	if q.Limit > 0 && len(found) > q.Limit {
+		found = found[:q.Limit]
+	}
+
+	return found, nil
+}
+
+func sortField(s interface{}) (string, bool, error) {
+	switch v := s.(type) {
+	case string:
+		return v, false, nil
+	case map[string]interface{}:
+		for field, dir := range v {
+			return field, dir == "desc", nil
+		}
+	}
+
+	return "", false, fmt.Errorf("invalid sort %v", s)
+}
+
+// Match reports whether the document matches the selector. Supported are
+// field equality with dotted paths, $and, $or, $not and the $eq, $ne, $gt,
+// $gte, $lt, $lte, $in, $nin, $exists and $elemMatch operators.
+func Match(selector map[string]interface{}, doc map[string]interface{}) (bool, error) {
+	for key, cond := range selector {
+		switch key {
+		case "$and", "$or":
+			list, ok := cond.([]interface{})
+			if !ok {
+				return false, fmt.Errorf("%s expects an array", key)
+			}
+
+			matched := key == "$and"
+			for _, item := range list {
+				sub, ok := item.(map[string]interface{})
+				if !ok {
+					return false, fmt.Errorf("%s expects an array of selectors", key)
+				}
+
+				ok, err := Match(sub, doc)
+				if err != nil {
+					return false, err
+				}
+
+				if key == "$and" && !ok {
+					matched = false
+					break
+				}
+				if key == "$or" && ok {
+					matched = true
+					break
+				}
+			}
+
+			if !matched {
+				return false, nil
+			}
+		case "$not":
+			sub, ok := cond.(map[string]interface{})
+			if !ok {
+				return false, fmt.Errorf("$not expects a selector")
+			}
+
+			ok, err := Match(sub, doc)
+			if err != nil || ok {
+				return false, err
+			}
+		default:
+			value, exists := lookup(doc, key)
+			ok, err := matchField(cond, value, exists)
+			if err != nil || !ok {
+				return false, err
+			}
+		}
+	}
+
+	return true, nil
+}
+
+func matchField(cond interface{}, value interface{}, exists bool) (bool, error) {
+	ops, ok := cond.(map[string]interface{})
+	if !ok || !isOperatorMap(ops) {
+		return exists && reflect.DeepEqual(normalize(cond), normalize(value)), nil
+	}
+
+	for op, arg := range ops {
+		var ok bool
+		switch op {
+		case "$eq":
+			ok = exists && compare(value, arg) == 0
+		case "$ne":
+			ok = !exists || compare(value, arg) != 0
+		case "$gt":
+			ok = exists && comparable(value, arg) && compare(value, arg) > 0
+		case "$gte":
+			ok = exists && comparable(value, arg) && compare(value, arg) >= 0
+		case "$lt":
+			ok = exists && comparable(value, arg) && compare(value, arg) < 0
+		case "$lte":
+			ok = exists && comparable(value, arg) && compare(value, arg) <= 0
+		case "$exists":
+			want, isBool := arg.(bool)
+			if !isBool {
+				return false, fmt.Errorf("$exists expects a boolean")
+			}
+			ok = exists == want
+		case "$in", "$nin":
+			list, isList := arg.([]interface{})
+			if !isList {
+				return false, fmt.Errorf("%s expects an array", op)
+			}
+
+			in := false
+			for _, item := range list {
+				if exists && compare(value, item) == 0 {
+					in = true
+					break
+				}
+			}
+			ok = in == (op == "$in")
+		case "$elemMatch":
+			sub, isSelector := arg.(map[string]interface{})
+			if !isSelector {
+				return false, fmt.Errorf("$elemMatch expects a selector")
+			}
+
+			list, _ := value.([]interface{})
+			for _, item := range list {
+				var err error
+				if elem, isDoc := item.(map[string]interface{}); isDoc && !isOperatorMap(sub) {
+					ok, err = Match(sub, elem)
+				} else {
+					ok, err = matchField(sub, item, true)
+				}
+
+				if err != nil {
+					return false, err
+				}
+				if ok {
+					break
+				}
+			}
+		default:
+			return false, fmt.Errorf("unsupported operator %s", op)
+		}
+
+		if !ok {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+func isOperatorMap(m map[string]interface{}) bool {
+	for key := range m {
+		if !strings.HasPrefix(key, "$") {
+			return false
+		}
+	}
+	return len(m) > 0
+}
+
+func lookup(doc map[string]interface{}, path string) (interface{}, bool) {
+	var current interface{} = doc
+	for _, part := range strings.Split(path, ".") {
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+
+		current, ok = m[part]
+		if !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+func normalize(v interface{}) interface{} {
+	switch n := v.(type) {
+	case int:
+		return float64(n)
+	case int32:
+		return float64(n)
+	case int64:
+		return float64(n)
+	}
+	return v
+}
+
+func comparable(a, b interface{}) bool {
+	return typeRank(normalize(a)) == typeRank(normalize(b))
+}
+
+// typeRank follows the CouchDB collation order of JSON types
+func typeRank(v interface{}) int {
+	switch v.(type) {
+	case nil:
+		return 0
+	case bool:
+		return 1
+	case float64:
+		return 2
+	case string:
+		return 3
+	case []interface{}:
+		return 4
+	default:
+		return 5
+	}
+}
+
+func compare(a, b interface{}) int {
+	a, b = normalize(a), normalize(b)
+	ra, rb := typeRank(a), typeRank(b)
+	if ra != rb {
+		return ra - rb
+	}
+
+	switch va := a.(type) {
+	case bool:
+		vb := b.(bool)
+		if va == vb {
+			return 0
+		}
+		if !va {
+			return -1
+		}
+		return 1
+	case float64:
+		vb := b.(float64)
+		switch {
+		case va < vb:
+			return -1
+		case va > vb:
+			return 1
+		}
+		return 0
+	case string:
+		return strings.Compare(va, b.(string))
+	case nil:
+		return 0
+	}
+
+	if reflect.DeepEqual(a, b) {
+		return 0
+	}
+	return 1
+}
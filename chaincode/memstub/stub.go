@@ -0,0 +1,566 @@
+package memstub
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+)
+
+const compositeKeyNamespace = "\x00"
+
+var errNotSupported = errors.New("not supported by the in-memory ledger")
+
+type write struct {
+	value  []byte
+	delete bool
+}
+
+// Stub is a transaction over the ledger. Like on a peer, reads see the
+// committed state only, writes are buffered until Commit and Commit fails
+// when a key read was modified by another transaction or, for the range and
+// partial composite key reads, when the keys of the range changed. As on a
+// peer, the rich queries are not checked again.
+type Stub struct {
+	ledger        *Ledger
+	txID          string
+	timestamp     time.Time
+	creator       []byte
+	transient     map[string][]byte
+	args          [][]byte
+	reads         map[string]uint64
+	ranges        []*rangeRead
+	writes        map[string]*write
+	privateWrites map[string]map[string]*write
+	validations   map[string][]byte
+	event         *pb.ChaincodeEvent
+	committed     bool
+}
+
+var _ shim.ChaincodeStubInterface = &Stub{}
+
+// Commit applies the writes of the transaction to the ledger
+func (s *Stub) Commit() error {
+	if s.committed {
+		return fmt.Errorf("transaction %s already committed", s.txID)
+	}
+
+	if err := s.ledger.commit(s); err != nil {
+		return err
+	}
+
+	s.committed = true
+	return nil
+}
+
+// SetTransient sets the transient data of the transaction
+func (s *Stub) SetTransient(transient map[string][]byte) {
+	s.transient = transient
+}
+
+// GetArgs ...
+func (s *Stub) GetArgs() [][]byte {
+	return s.args
+}
+
+// GetStringArgs ...
+func (s *Stub) GetStringArgs() []string {
+	args := make([]string, 0, len(s.args))
+	for _, arg := range s.args {
+		args = append(args, string(arg))
+	}
+	return args
+}
+
+// GetFunctionAndParameters ...
+func (s *Stub) GetFunctionAndParameters() (string, []string) {
+	args := s.GetStringArgs()
+	if len(args) == 0 {
+		return "", []string{}
+	}
+	return args[0], args[1:]
+}
+
+// GetArgsSlice ...
+func (s *Stub) GetArgsSlice() ([]byte, error) {
+	var slice []byte
+	for _, arg := range s.args {
+		slice = append(slice, arg...)
+	}
+	return slice, nil
+}
+
+// GetTxID ...
+func (s *Stub) GetTxID() string {
+	return s.txID
+}
+
+// GetChannelID ...
+func (s *Stub) GetChannelID() string {
+	return s.ledger.channel
+}
+
+// InvokeChaincode calls a chaincode registered in the ledger
+func (s *Stub) InvokeChaincode(chaincodeName string, args [][]byte, channel string) pb.Response {
+	s.ledger.mu.RLock()
+	invoke, ok := s.ledger.chaincodes[chaincodeName]
+	s.ledger.mu.RUnlock()
+
+	if !ok {
+		return shim.Error(fmt.Sprintf("chaincode %s not found", chaincodeName))
+	}
+
+	return invoke(args)
+}
+
+// GetState ...
+func (s *Stub) GetState(key string) ([]byte, error) {
+	s.ledger.mu.RLock()
+	defer s.ledger.mu.RUnlock()
+
+	if _, ok := s.reads[key]; !ok {
+		s.reads[key] = s.ledger.versions[key]
+	}
+	return copyBytes(s.ledger.state[key]), nil
+}
+
+// PutState ...
+func (s *Stub) PutState(key string, value []byte) error {
+	if key == "" {
+		return errors.New("key must not be an empty string")
+	}
+
+	if value == nil {
+		value = []byte{}
+	}
+
+	s.writes[key] = &write{value: copyBytes(value)}
+	return nil
+}
+
+// DelState ...
+func (s *Stub) DelState(key string) error {
+	if key == "" {
+		return errors.New("key must not be an empty string")
+	}
+
+	s.writes[key] = &write{delete: true}
+	return nil
+}
+
+// SetStateValidationParameter ...
+func (s *Stub) SetStateValidationParameter(key string, ep []byte) error {
+	if s.validations == nil {
+		s.validations = map[string][]byte{}
+	}
+	s.validations[key] = ep
+	return nil
+}
+
+// GetStateValidationParameter ...
+func (s *Stub) GetStateValidationParameter(key string) ([]byte, error) {
+	s.ledger.mu.RLock()
+	defer s.ledger.mu.RUnlock()
+	return copyBytes(s.ledger.validations[key]), nil
+}
+
+// GetStateByRange returns the simple keys in [startKey, endKey) in order,
+// an empty endKey means no upper bound
+func (s *Stub) GetStateByRange(startKey, endKey string) (shim.StateQueryIteratorInterface, error) {
+	if err := validateSimpleKeys(startKey, endKey); err != nil {
+		return nil, err
+	}
+
+	s.ledger.mu.RLock()
+	defer s.ledger.mu.RUnlock()
+	return newIterator(s.readRange(startKey, endKey, false, "")), nil
+}
+
+// GetStateByRangeWithPagination ...
+func (s *Stub) GetStateByRangeWithPagination(startKey, endKey string, pageSize int32,
+	bookmark string) (shim.StateQueryIteratorInterface, *pb.QueryResponseMetadata, error) {
+	if err := validateSimpleKeys(startKey, endKey); err != nil {
+		return nil, nil, err
+	}
+
+	if bookmark != "" {
+		startKey = bookmark
+	}
+
+	s.ledger.mu.RLock()
+	defer s.ledger.mu.RUnlock()
+
+	kvs, meta := paginate(rangeKVs(s.ledger.state, startKey, endKey, false), pageSize)
+	s.readRange(startKey, endKey, false, meta.Bookmark)
+	return newIterator(kvs), meta, nil
+}
+
+// GetStateByPartialCompositeKey ...
+func (s *Stub) GetStateByPartialCompositeKey(objectType string, keys []string) (shim.StateQueryIteratorInterface, error) {
+	prefix, err := s.CreateCompositeKey(objectType, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	s.ledger.mu.RLock()
+	defer s.ledger.mu.RUnlock()
+	return newIterator(s.readRange(prefix, prefix+string(utf8.MaxRune), true, "")), nil
+}
+
+// GetStateByPartialCompositeKeyWithPagination ...
+func (s *Stub) GetStateByPartialCompositeKeyWithPagination(objectType string, keys []string,
+	pageSize int32, bookmark string) (shim.StateQueryIteratorInterface, *pb.QueryResponseMetadata, error) {
+	prefix, err := s.CreateCompositeKey(objectType, keys)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	startKey := prefix
+	if bookmark != "" {
+		if !strings.HasPrefix(bookmark, prefix) {
+			return nil, nil, fmt.Errorf("invalid bookmark %q", bookmark)
+		}
+		startKey = bookmark
+	}
+
+	s.ledger.mu.RLock()
+	defer s.ledger.mu.RUnlock()
+
+	kvs, meta := paginate(rangeKVs(s.ledger.state, startKey, prefix+string(utf8.MaxRune), true), pageSize)
+	s.readRange(startKey, prefix+string(utf8.MaxRune), true, meta.Bookmark)
+	return newIterator(kvs), meta, nil
+}
+
+// CreateCompositeKey ...
+func (s *Stub) CreateCompositeKey(objectType string, attributes []string) (string, error) {
+	return shim.CreateCompositeKey(objectType, attributes)
+}
+
+// SplitCompositeKey ...
+func (s *Stub) SplitCompositeKey(compositeKey string) (string, []string, error) {
+	if !strings.HasPrefix(compositeKey, compositeKeyNamespace) {
+		return "", nil, fmt.Errorf("%q is not a composite key", compositeKey)
+	}
+
+	parts := strings.Split(strings.TrimPrefix(compositeKey, compositeKeyNamespace), "\x00")
+	if len(parts) < 2 {
+		return "", nil, fmt.Errorf("%q is not a composite key", compositeKey)
+	}
+
+	return parts[0], parts[1 : len(parts)-1], nil
+}
+
+// GetQueryResult evaluates a CouchDB selector over every key, composite keys
+// included as in CouchDB, see Match for the supported operators
+func (s *Stub) GetQueryResult(query string) (shim.StateQueryIteratorInterface, error) {
+	q, err := parseQuery(query)
+	if err != nil {
+		return nil, err
+	}
+
+	s.ledger.mu.RLock()
+	defer s.ledger.mu.RUnlock()
+
+	kvs, err := q.filter(allKVs(s.ledger.state))
+	if err != nil {
+		return nil, err
+	}
+	return newIterator(kvs), nil
+}
+
+// GetQueryResultWithPagination evaluates the query like GetQueryResult, the
+// bookmark is the key of the first result of the page
+func (s *Stub) GetQueryResultWithPagination(query string, pageSize int32,
+	bookmark string) (shim.StateQueryIteratorInterface, *pb.QueryResponseMetadata, error) {
+	q, err := parseQuery(query)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	s.ledger.mu.RLock()
+	defer s.ledger.mu.RUnlock()
+
+	kvs, err := q.filter(allKVs(s.ledger.state))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// the results may be sorted by any field, the page starts at the
+	// bookmark wherever it is
+	for i, kv := range kvs {
+		if bookmark == "" || kv.Key == bookmark {
+			kvs = kvs[i:]
+			break
+		}
+		if i == len(kvs)-1 {
+			kvs = nil
+		}
+	}
+
+	kvs, meta := paginate(kvs, pageSize)
+	return newIterator(kvs), meta, nil
+}
+
+// GetHistoryForKey returns the modifications of the key, newest first
+func (s *Stub) GetHistoryForKey(key string) (shim.HistoryQueryIteratorInterface, error) {
+	s.ledger.mu.RLock()
+	defer s.ledger.mu.RUnlock()
+
+	history := s.ledger.history[key]
+	mods := make([]*queryresult.KeyModification, 0, len(history))
+	for i := len(history) - 1; i >= 0; i-- {
+		mod := *history[i]
+		mod.Value = copyBytes(mod.Value)
+		mods = append(mods, &mod)
+	}
+
+	return &historyIterator{mods: mods}, nil
+}
+
+// GetPrivateData ...
+func (s *Stub) GetPrivateData(collection, key string) ([]byte, error) {
+	if collection == "" {
+		return nil, errors.New("collection must not be an empty string")
+	}
+
+	s.ledger.mu.RLock()
+	defer s.ledger.mu.RUnlock()
+	return copyBytes(s.ledger.private[collection][key]), nil
+}
+
+// GetPrivateDataHash ...
+func (s *Stub) GetPrivateDataHash(collection, key string) ([]byte, error) {
+	value, err := s.GetPrivateData(collection, key)
+	if err != nil || value == nil {
+		return nil, err
+	}
+
+	hash := sha256.Sum256(value)
+	return hash[:], nil
+}
+
+// PutPrivateData ...
+func (s *Stub) PutPrivateData(collection string, key string, value []byte) error {
+	if collection == "" {
+		return errors.New("collection must not be an empty string")
+	}
+
+	if key == "" {
+		return errors.New("key must not be an empty string")
+	}
+
+	if s.privateWrites[collection] == nil {
+		s.privateWrites[collection] = map[string]*write{}
+	}
+	s.privateWrites[collection][key] = &write{value: copyBytes(value)}
+	return nil
+}
+
+// DelPrivateData ...
+func (s *Stub) DelPrivateData(collection, key string) error {
+	if collection == "" {
+		return errors.New("collection must not be an empty string")
+	}
+
+	if s.privateWrites[collection] == nil {
+		s.privateWrites[collection] = map[string]*write{}
+	}
+	s.privateWrites[collection][key] = &write{delete: true}
+	return nil
+}
+
+// SetPrivateDataValidationParameter ...
+func (s *Stub) SetPrivateDataValidationParameter(collection, key string, ep []byte) error {
+	return errNotSupported
+}
+
+// GetPrivateDataValidationParameter ...
+func (s *Stub) GetPrivateDataValidationParameter(collection, key string) ([]byte, error) {
+	return nil, errNotSupported
+}
+
+// GetPrivateDataByRange ...
+func (s *Stub) GetPrivateDataByRange(collection, startKey, endKey string) (shim.StateQueryIteratorInterface, error) {
+	if err := validateSimpleKeys(startKey, endKey); err != nil {
+		return nil, err
+	}
+
+	s.ledger.mu.RLock()
+	defer s.ledger.mu.RUnlock()
+	return newIterator(rangeKVs(s.ledger.private[collection], startKey, endKey, false)), nil
+}
+
+// GetPrivateDataByPartialCompositeKey ...
+func (s *Stub) GetPrivateDataByPartialCompositeKey(collection, objectType string, keys []string) (shim.StateQueryIteratorInterface, error) {
+	prefix, err := s.CreateCompositeKey(objectType, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	s.ledger.mu.RLock()
+	defer s.ledger.mu.RUnlock()
+	return newIterator(rangeKVs(s.ledger.private[collection], prefix, prefix+string(utf8.MaxRune), true)), nil
+}
+
+// GetPrivateDataQueryResult ...
+func (s *Stub) GetPrivateDataQueryResult(collection, query string) (shim.StateQueryIteratorInterface, error) {
+	q, err := parseQuery(query)
+	if err != nil {
+		return nil, err
+	}
+
+	s.ledger.mu.RLock()
+	defer s.ledger.mu.RUnlock()
+
+	kvs, err := q.filter(rangeKVs(s.ledger.private[collection], "", "", false))
+	if err != nil {
+		return nil, err
+	}
+	return newIterator(kvs), nil
+}
+
+// GetCreator ...
+func (s *Stub) GetCreator() ([]byte, error) {
+	return s.creator, nil
+}
+
+// GetTransient ...
+func (s *Stub) GetTransient() (map[string][]byte, error) {
+	return s.transient, nil
+}
+
+// GetBinding ...
+func (s *Stub) GetBinding() ([]byte, error) {
+	return nil, errNotSupported
+}
+
+// GetDecorations ...
+func (s *Stub) GetDecorations() map[string][]byte {
+	return nil
+}
+
+// GetSignedProposal ...
+func (s *Stub) GetSignedProposal() (*pb.SignedProposal, error) {
+	return nil, errNotSupported
+}
+
+// GetTxTimestamp ...
+func (s *Stub) GetTxTimestamp() (*timestamp.Timestamp, error) {
+	return ptypes.TimestampProto(s.timestamp)
+}
+
+// SetEvent ...
+func (s *Stub) SetEvent(name string, payload []byte) error {
+	if name == "" {
+		return errors.New("event name can not be empty string")
+	}
+
+	s.event = &pb.ChaincodeEvent{TxId: s.txID, EventName: name, Payload: payload}
+	return nil
+}
+
+func validateSimpleKeys(keys ...string) error {
+	for _, key := range keys {
+		if strings.HasPrefix(key, compositeKeyNamespace) {
+			return fmt.Errorf("first character of the key [%s] contains a null character which is not allowed", key)
+		}
+	}
+	return nil
+}
+
+// rangeKVs returns the entries in [startKey, endKey) sorted by key, only
+// composite keys when composite is set and only simple keys otherwise
+func rangeKVs(state map[string][]byte, startKey, endKey string, composite bool) []*queryresult.KV {
+	keys := make([]string, 0, len(state))
+	for key := range state {
+		if strings.HasPrefix(key, compositeKeyNamespace) != composite {
+			continue
+		}
+
+		if key < startKey || endKey != "" && key >= endKey {
+			continue
+		}
+
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	kvs := make([]*queryresult.KV, 0, len(keys))
+	for _, key := range keys {
+		kvs = append(kvs, &queryresult.KV{Key: key, Value: copyBytes(state[key])})
+	}
+	return kvs
+}
+
+// rangeRead is a range read by a transaction with the versions of its keys
+type rangeRead struct {
+	startKey  string
+	endKey    string
+	composite bool
+	versions  map[string]uint64
+}
+
+// readRange records the range read by the transaction, a page stops before
+// the bookmark, and returns its keys. The ledger must be locked.
+func (s *Stub) readRange(startKey, endKey string, composite bool, bookmark string) []*queryresult.KV {
+	if bookmark != "" {
+		endKey = bookmark
+	}
+
+	kvs := rangeKVs(s.ledger.state, startKey, endKey, composite)
+	r := &rangeRead{startKey: startKey, endKey: endKey, composite: composite, versions: make(map[string]uint64, len(kvs))}
+	for _, kv := range kvs {
+		r.versions[kv.Key] = s.ledger.versions[kv.Key]
+	}
+
+	s.ranges = append(s.ranges, r)
+	return kvs
+}
+
+// unchanged tells if the range holds the same keys at the same versions
+func (r *rangeRead) unchanged(l *Ledger) bool {
+	kvs := rangeKVs(l.state, r.startKey, r.endKey, r.composite)
+	if len(kvs) != len(r.versions) {
+		return false
+	}
+
+	for _, kv := range kvs {
+		if version, ok := r.versions[kv.Key]; !ok || l.versions[kv.Key] != version {
+			return false
+		}
+	}
+	return true
+}
+
+// allKVs returns the simple and the composite keys
+func allKVs(state map[string][]byte) []*queryresult.KV {
+	return append(rangeKVs(state, "", "", false), rangeKVs(state, "", "", true)...)
+}
+
+// paginate cuts the page, the bookmark is the first key of the next page
+func paginate(kvs []*queryresult.KV, pageSize int32) ([]*queryresult.KV, *pb.QueryResponseMetadata) {
+	meta := &pb.QueryResponseMetadata{}
+	if pageSize > 0 && int(pageSize) < len(kvs) {
+		meta.Bookmark = kvs[pageSize].Key
+		kvs = kvs[:pageSize]
+	}
+
+	meta.FetchedRecordsCount = int32(len(kvs))
+	return kvs, meta
+}
+
+func copyBytes(b []byte) []byte {
+	if b == nil {
+		return nil
+	}
+	return append([]byte{}, b...)
+}
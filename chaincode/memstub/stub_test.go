@@ -0,0 +1,471 @@
+package memstub
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func keys(t *testing.T, it shim.StateQueryIteratorInterface) []string {
+	defer it.Close()
+
+	var found []string
+	for it.HasNext() {
+		kv, err := it.Next()
+		require.NoError(t, err)
+		found = append(found, kv.Key)
+	}
+	return found
+}
+
+func seed(t *testing.T, l *Ledger, kvs map[string]string) {
+	err := l.Run(func(ctx contractapi.TransactionContextInterface) error {
+		for key, value := range kvs {
+			if err := ctx.GetStub().PutState(key, []byte(value)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+func TestTransactionIsolation(t *testing.T) {
+	l := New()
+	stub := l.NewTransaction("CreateCar")
+	require.NoError(t, stub.PutState("1", []byte("a")))
+
+	value, err := stub.GetState("1")
+	require.NoError(t, err)
+	assert.Nil(t, value, "writes are not visible before commit")
+
+	require.NoError(t, stub.Commit())
+	assert.Error(t, stub.Commit())
+
+	value, err = l.NewTransaction("GetCar").GetState("1")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("a"), value)
+
+	err = l.Run(func(ctx contractapi.TransactionContextInterface) error {
+		ctx.GetStub().DelState("1")
+		return errors.New("rejected")
+	})
+	assert.EqualError(t, err, "rejected")
+
+	value, err = l.NewTransaction("GetCar").GetState("1")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("a"), value, "failed transactions are discarded")
+}
+
+func TestGetStateByRange(t *testing.T) {
+	l := New()
+	seed(t, l, map[string]string{"b": "{}", "a": "{}", "d": "{}", "c": "{}"})
+	stub := l.NewTransaction("")
+
+	ck, err := stub.CreateCompositeKey("owner~car", []string{"Max", "1"})
+	require.NoError(t, err)
+	seed(t, l, map[string]string{ck: "{}"})
+
+	tests := []struct {
+		start, end string
+		expected   []string
+	}{
+		{"", "", []string{"a", "b", "c", "d"}},
+		{"b", "d", []string{"b", "c"}},
+		{"c", "", []string{"c", "d"}},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%v", test), func(t *testing.T) {
+			it, err := stub.GetStateByRange(test.start, test.end)
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, keys(t, it))
+		})
+	}
+
+	_, err = stub.GetStateByRange(ck, "")
+	assert.Error(t, err)
+}
+
+func TestPagination(t *testing.T) {
+	l := New()
+	seed(t, l, map[string]string{"1": "{}", "2": "{}", "3": "{}", "4": "{}", "5": "{}"})
+	stub := l.NewTransaction("")
+
+	var pages [][]string
+	bookmark := ""
+	for {
+		it, meta, err := stub.GetStateByRangeWithPagination("", "", 2, bookmark)
+		require.NoError(t, err)
+		page := keys(t, it)
+		assert.Equal(t, int32(len(page)), meta.FetchedRecordsCount)
+		pages = append(pages, page)
+
+		if meta.Bookmark == "" {
+			break
+		}
+		bookmark = meta.Bookmark
+	}
+
+	assert.Equal(t, [][]string{{"1", "2"}, {"3", "4"}, {"5"}}, pages)
+}
+
+func TestCompositeKeys(t *testing.T) {
+	l := New()
+	stub := l.NewTransaction("")
+
+	var all []string
+	for _, attrs := range [][]string{{"Max", "2"}, {"Max", "1"}, {"Juan", "3"}} {
+		ck, err := stub.CreateCompositeKey("owner~car", attrs)
+		require.NoError(t, err)
+		all = append(all, ck)
+		require.NoError(t, stub.PutState(ck, []byte{0}))
+	}
+	require.NoError(t, stub.Commit())
+
+	stub = l.NewTransaction("")
+	it, err := stub.GetStateByPartialCompositeKey("owner~car", []string{"Max"})
+	require.NoError(t, err)
+	found := keys(t, it)
+	assert.Equal(t, []string{all[1], all[0]}, found)
+
+	objectType, attrs, err := stub.SplitCompositeKey(found[0])
+	require.NoError(t, err)
+	assert.Equal(t, "owner~car", objectType)
+	assert.Equal(t, []string{"Max", "1"}, attrs)
+
+	it, meta, err := stub.GetStateByPartialCompositeKeyWithPagination("owner~car", []string{}, 2, "")
+	require.NoError(t, err)
+	assert.Len(t, keys(t, it), 2)
+
+	it, meta, err = stub.GetStateByPartialCompositeKeyWithPagination("owner~car", []string{}, 2, meta.Bookmark)
+	require.NoError(t, err)
+	assert.Len(t, keys(t, it), 1)
+	assert.Equal(t, "", meta.Bookmark)
+}
+
+func TestHistoryAndTimestamps(t *testing.T) {
+	now := time.Date(2021, 9, 21, 15, 0, 0, 0, time.UTC)
+	l := New(WithClock(func() time.Time {
+		now = now.Add(time.Minute)
+		return now
+	}))
+
+	seed(t, l, map[string]string{"1": "a"})
+	seed(t, l, map[string]string{"1": "b"})
+	require.NoError(t, l.Run(func(ctx contractapi.TransactionContextInterface) error {
+		return ctx.GetStub().DelState("1")
+	}))
+
+	stub := l.NewTransaction("")
+	it, err := stub.GetHistoryForKey("1")
+	require.NoError(t, err)
+
+	var values []string
+	var deletes []bool
+	var minutes []int
+	for it.HasNext() {
+		mod, err := it.Next()
+		require.NoError(t, err)
+		values = append(values, string(mod.Value))
+		deletes = append(deletes, mod.IsDelete)
+		minutes = append(minutes, int(mod.Timestamp.Seconds/60%60))
+	}
+
+	assert.Equal(t, []string{"", "b", "a"}, values)
+	assert.Equal(t, []bool{true, false, false}, deletes)
+	assert.Equal(t, []int{3, 2, 1}, minutes)
+
+	ts, err := stub.GetTxTimestamp()
+	require.NoError(t, err)
+	assert.Equal(t, now.Unix(), ts.Seconds)
+	assert.NotEqual(t, l.NewTransaction("").GetTxID(), stub.GetTxID())
+}
+
+func TestGetQueryResult(t *testing.T) {
+	l := New()
+	seed(t, l, map[string]string{
+		"1": `{"id":"1","brand":"Toyota","transfersCount":0,"year":2010}`,
+		"2": `{"id":"2","brand":"Honda","transfersCount":2,"year":2015}`,
+		"3": `{"id":"3","brand":"Toyota","transfersCount":3,"owners":[{"owner":"Ana","percent":60},{"owner":"Max","percent":40}]}`,
+		"4": `not json`,
+	})
+	stub := l.NewTransaction("")
+
+	tests := []struct {
+		query       string
+		expected    []string
+		expectedErr bool
+	}{
+		{`{"selector":{"brand":"Toyota"}}`, []string{"1", "3"}, false},
+		{`{"selector":{"transfersCount":{"$gte":1,"$lte":2}}}`, []string{"2"}, false},
+		{`{"selector":{"year":{"$exists":false}}}`, []string{"3"}, false},
+		{`{"selector":{"$or":[{"brand":"Honda"},{"year":2010}]}}`, []string{"1", "2"}, false},
+		{`{"selector":{"brand":{"$in":["Honda","Kia"]}}}`, []string{"2"}, false},
+		{`{"selector":{"owners":{"$elemMatch":{"owner":"Max"}}}}`, []string{"3"}, false},
+		{`{"selector":{"owners":{"$elemMatch":{"percent":{"$gt":50}}}}}`, []string{"3"}, false},
+		{`{"selector":{"owners":{"$elemMatch":{"owner":"Juan"}}}}`, nil, false},
+		{`{"selector":{"brand":"Toyota"},"sort":[{"transfersCount":"desc"}],"limit":1}`, []string{"3"}, false},
+		{`{"selector":{"brand":{"$regex":"T.*"}}}`, nil, true},
+		{`{"brand":"Toyota"}`, nil, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.query, func(t *testing.T) {
+			it, err := stub.GetQueryResult(test.query)
+			if test.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, keys(t, it))
+		})
+	}
+}
+
+func TestGetQueryResultWithPagination(t *testing.T) {
+	l := New()
+	stub := l.NewTransaction("")
+	audit, err := stub.CreateCompositeKey("audit~txid", []string{"a"})
+	require.NoError(t, err)
+	seed(t, l, map[string]string{
+		"1":   `{"brand":"Toyota","year":2015}`,
+		"2":   `{"brand":"Toyota","year":2010}`,
+		"3":   `{"brand":"Toyota","year":2020}`,
+		"4":   `{"brand":"Honda","year":2012}`,
+		audit: `{"brand":"Toyota","year":2011}`,
+	})
+
+	var pages [][]string
+	bookmark := ""
+	for {
+		it, meta, err := stub.GetQueryResultWithPagination(`{"selector":{"brand":"Toyota"},"sort":["year"]}`, 2, bookmark)
+		require.NoError(t, err)
+		pages = append(pages, keys(t, it))
+
+		if meta.Bookmark == "" {
+			break
+		}
+		bookmark = meta.Bookmark
+	}
+
+	assert.Equal(t, [][]string{{"2", audit}, {"1", "3"}}, pages, "the composite keys are queried and the pages follow the sort")
+
+	it, _, err := stub.GetQueryResultWithPagination(`{"selector":{"brand":"Toyota"}}`, 2, "unknown")
+	require.NoError(t, err)
+	assert.Empty(t, keys(t, it))
+}
+
+func TestPrivateData(t *testing.T) {
+	l := New()
+	stub := l.NewTransaction("")
+	require.NoError(t, stub.PutPrivateData("owners", "1", []byte("Juan")))
+	require.NoError(t, stub.Commit())
+
+	stub = l.NewTransaction("")
+	value, err := stub.GetPrivateData("owners", "1")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("Juan"), value)
+
+	hash, err := stub.GetPrivateDataHash("owners", "1")
+	require.NoError(t, err)
+	assert.Len(t, hash, 32)
+
+	require.NoError(t, stub.DelPrivateData("owners", "1"))
+	require.NoError(t, stub.Commit())
+
+	value, err = l.NewTransaction("").GetPrivateData("owners", "1")
+	require.NoError(t, err)
+	assert.Nil(t, value)
+}
+
+func TestReadConflict(t *testing.T) {
+	l := New()
+	seed(t, l, map[string]string{"1": "a"})
+
+	first := l.NewTransaction("")
+	second := l.NewTransaction("")
+	for _, stub := range []*Stub{first, second} {
+		_, err := stub.GetState("1")
+		require.NoError(t, err)
+		require.NoError(t, stub.PutState("1", []byte(stub.GetTxID())))
+	}
+
+	require.NoError(t, first.Commit())
+	err := second.Commit()
+	assert.True(t, errors.Is(err, ErrReadConflict))
+
+	value, err := l.NewTransaction("").GetState("1")
+	require.NoError(t, err)
+	assert.Equal(t, []byte(first.GetTxID()), value)
+}
+
+func TestPhantomRead(t *testing.T) {
+	first, err := shim.CreateCompositeKey("owner~car", []string{"Max", "1"})
+	require.NoError(t, err)
+	second, err := shim.CreateCompositeKey("owner~car", []string{"Max", "2"})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		read     func(stub *Stub) error
+		write    map[string]string
+		conflict bool
+	}{
+		{"key added to the range", func(stub *Stub) error {
+			_, err := stub.GetStateByRange("1", "4")
+			return err
+		}, map[string]string{"2": "b"}, true},
+		{"key modified in the range", func(stub *Stub) error {
+			_, err := stub.GetStateByRange("1", "")
+			return err
+		}, map[string]string{"3": "d"}, true},
+		{"key added after the range", func(stub *Stub) error {
+			_, err := stub.GetStateByRange("1", "4")
+			return err
+		}, map[string]string{"5": "e"}, false},
+		{"key added after the page", func(stub *Stub) error {
+			_, _, err := stub.GetStateByRangeWithPagination("", "", 1, "")
+			return err
+		}, map[string]string{"5": "e"}, false},
+		{"composite key added", func(stub *Stub) error {
+			_, err := stub.GetStateByPartialCompositeKey("owner~car", []string{"Max"})
+			return err
+		}, map[string]string{second: "{}"}, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			l := New()
+			seed(t, l, map[string]string{"1": "a", "3": "c", first: "{}"})
+
+			stub := l.NewTransaction("")
+			require.NoError(t, test.read(stub))
+			require.NoError(t, stub.PutState("result", []byte("x")))
+
+			seed(t, l, test.write)
+			err := stub.Commit()
+			if test.conflict {
+				assert.True(t, errors.Is(err, ErrPhantomRead), "%v", err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestFileStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ledger.db")
+
+	store, err := OpenFileStore(path)
+	require.NoError(t, err)
+	l, err := Open(store)
+	require.NoError(t, err)
+
+	seed(t, l, map[string]string{"1": "a", "2": "b"})
+	seed(t, l, map[string]string{"1": "c"})
+	require.NoError(t, l.Run(func(ctx contractapi.TransactionContextInterface) error {
+		_, err := ctx.GetStub().GetState("1")
+		return err
+	}))
+	require.NoError(t, store.Close())
+
+	store, err = OpenFileStore(path)
+	require.NoError(t, err)
+	defer store.Close()
+
+	reopened, err := Open(store)
+	require.NoError(t, err)
+
+	stub := reopened.NewTransaction("")
+	it, err := stub.GetStateByRange("", "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1", "2"}, keys(t, it))
+
+	value, err := stub.GetState("1")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("c"), value)
+
+	history, err := stub.GetHistoryForKey("1")
+	require.NoError(t, err)
+	count := 0
+	for history.HasNext() {
+		_, err := history.Next()
+		require.NoError(t, err)
+		count++
+	}
+	assert.Equal(t, 2, count)
+	number, ok := reopened.BlockNumber(stub.GetTxID())
+	assert.False(t, ok, "uncommitted transactions are not in a block")
+	assert.Equal(t, uint64(0), number)
+
+	mod, err := reopened.NewTransaction("").GetHistoryForKey("2")
+	require.NoError(t, err)
+	first, err := mod.Next()
+	require.NoError(t, err)
+	number, ok = reopened.BlockNumber(first.TxId)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(1), number)
+}
+
+func TestPurgePrivateData(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ledger.db")
+
+	store, err := OpenFileStore(path)
+	require.NoError(t, err)
+	l, err := Open(store)
+	require.NoError(t, err)
+
+	for _, value := range []string{"Juan", "Juan Pérez"} {
+		value := value
+		require.NoError(t, l.RunTransient(map[string][]byte{"name": []byte(value)}, func(ctx contractapi.TransactionContextInterface) error {
+			transient, err := ctx.GetStub().GetTransient()
+			if err != nil {
+				return err
+			}
+			if err := ctx.GetStub().PutPrivateData("owners", "2", []byte("Ana")); err != nil {
+				return err
+			}
+			return ctx.GetStub().PutPrivateData("owners", "1", transient["name"])
+		}))
+	}
+
+	require.NoError(t, l.PurgePrivateData("owners", "1"))
+	seed(t, l, map[string]string{"1": "a"})
+
+	data, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "Juan", "the purge rewrites the file")
+	assert.NotContains(t, string(data), base64.StdEncoding.EncodeToString([]byte("Juan")))
+	require.NoError(t, store.Close())
+
+	store, err = OpenFileStore(path)
+	require.NoError(t, err)
+	defer store.Close()
+
+	reopened, err := Open(store)
+	require.NoError(t, err)
+
+	stub := reopened.NewTransaction("")
+	value, err := stub.GetPrivateData("owners", "1")
+	require.NoError(t, err)
+	assert.Nil(t, value)
+
+	value, err = stub.GetPrivateData("owners", "2")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("Ana"), value)
+
+	value, err = stub.GetState("1")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("a"), value, "the blocks appended after the purge are kept")
+}
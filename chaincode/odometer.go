@@ -0,0 +1,100 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/yimialmonte/chaincode-cars/asset"
+)
+
+// odometerIndex stores the readings under car~odometer composite keys with
+// the transaction time, so a partial key returns them in order
+const odometerIndex = "car~odometer"
+
+// RecordOdometer appends a mileage reading to the car, by a workshop or
+// inspection organization or by the linked identity of the owner. A reading
+// lower than the last one is rejected, the sign of an odometer rollback.
+func (s *SmartContract) RecordOdometer(ctx contractapi.TransactionContextInterface, id string, km int, source string) (*asset.OdometerReading, error) {
+	if km < 0 {
+		return nil, fmt.Errorf("the odometer reading can not be negative")
+	}
+
+	if strings.TrimSpace(source) == "" {
+		return nil, fmt.Errorf("the source of the reading is required")
+	}
+
+	car, err := s.GetCar(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := requireMSP(ctx, WorkshopMSPs); err != nil {
+		if err := requireOwnerIdentity(ctx, car.Owner); err != nil {
+			return nil, err
+		}
+	}
+
+	readings, err := s.GetOdometerReadings(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(readings) > 0 {
+		if last := readings[len(readings)-1].Km; km < last {
+			return nil, fmt.Errorf("the odometer reading %d km is lower than the last one, %d km", km, last)
+		}
+	}
+
+	now, err := txTime(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	reading := &asset.OdometerReading{
+		CarID:      id,
+		Km:         km,
+		Source:     source,
+		RecordedAt: now,
+		TxID:       ctx.GetStub().GetTxID(),
+	}
+
+	key, err := ctx.GetStub().CreateCompositeKey(odometerIndex, []string{id, now, reading.TxID})
+	if err != nil {
+		return nil, err
+	}
+
+	readingJSON, err := json.Marshal(reading)
+	if err != nil {
+		return nil, err
+	}
+
+	return reading, ctx.GetStub().PutState(key, readingJSON)
+}
+
+// GetOdometerReadings returns the readings of the car, oldest first
+func (s *SmartContract) GetOdometerReadings(ctx contractapi.TransactionContextInterface, id string) ([]*asset.OdometerReading, error) {
+	res, err := ctx.GetStub().GetStateByPartialCompositeKey(odometerIndex, []string{id})
+	if err != nil {
+		return nil, err
+	}
+	defer res.Close()
+
+	readings := []*asset.OdometerReading{}
+	for res.HasNext() {
+		kv, err := res.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		var reading asset.OdometerReading
+		if err := json.Unmarshal(kv.Value, &reading); err != nil {
+			return nil, err
+		}
+
+		readings = append(readings, &reading)
+	}
+
+	return readings, nil
+}
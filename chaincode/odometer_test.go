@@ -0,0 +1,87 @@
+package chaincode
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yimialmonte/chaincode-cars/asset"
+	"github.com/yimialmonte/chaincode-cars/chaincode/memstub"
+)
+
+func TestRecordOdometer(t *testing.T) {
+	workshop := identity(t, "WorkshopMSP", nil)
+	ana, err := memstub.NewIdentity("Org1MSP", "ana", nil)
+	require.NoError(t, err)
+	dealer := identity(t, "Org1MSP", nil)
+
+	now := time.Date(2021, 9, 21, 15, 0, 0, 0, time.UTC)
+	ledger := memstub.New(memstub.WithCreator(workshop), memstub.WithClock(func() time.Time {
+		now = now.Add(time.Hour)
+		return now
+	}))
+	sc := &SmartContract{}
+	require.NoError(t, ledger.Run(sc.InitLedger))
+	linkOwner(t, ledger, sc, "o-ana", ana)
+	require.NoError(t, ledger.Run(func(ctx contractapi.TransactionContextInterface) error {
+		return sc.CreateCar(ctx, "31", "Kia", "o-ana")
+	}))
+	ledger.SetCreator(workshop)
+
+	record := func(id string, km int, source string) (reading *asset.OdometerReading, err error) {
+		err = ledger.Run(func(ctx contractapi.TransactionContextInterface) error {
+			reading, err = sc.RecordOdometer(ctx, id, km, source)
+			return err
+		})
+		return reading, err
+	}
+
+	_, err = record("99", 1000, "dealer")
+	assert.EqualError(t, err, "car does not exist ID: 99")
+
+	_, err = record("12", -1, "dealer")
+	assert.EqualError(t, err, "the odometer reading can not be negative")
+
+	_, err = record("12", 1000, " ")
+	assert.EqualError(t, err, "the source of the reading is required")
+
+	first, err := record("12", 52000, "inspection")
+	require.NoError(t, err)
+
+	same, err := record("12", 52000, "workshop")
+	require.NoError(t, err)
+
+	_, err = record("12", 31000, "workshop")
+	assert.EqualError(t, err, "the odometer reading 31000 km is lower than the last one, 52000 km")
+
+	ledger.SetCreator(dealer)
+	_, err = record("12", 53000, "dealer")
+	assert.True(t, errors.Is(err, ErrAccessDenied), "a dealer is not a workshop nor the owner")
+	juan, _ := seededOwners(t, ledger)
+	assert.EqualError(t, err, "access denied, the owner "+juan+" is not linked to an identity")
+
+	ledger.SetCreator(ana)
+	byOwner, err := record("31", 1000, "owner")
+	require.NoError(t, err)
+
+	_, err = record("12", 53000, "owner")
+	assert.True(t, errors.Is(err, ErrAccessDenied), "only the owner of the car")
+
+	require.NoError(t, ledger.Run(func(ctx contractapi.TransactionContextInterface) error {
+		readings, err := sc.GetOdometerReadings(ctx, "12")
+		require.NoError(t, err)
+		assert.Equal(t, []*asset.OdometerReading{first, same}, readings)
+
+		readings, err = sc.GetOdometerReadings(ctx, "31")
+		require.NoError(t, err)
+		assert.Equal(t, []*asset.OdometerReading{byOwner}, readings)
+
+		cars, err := sc.GetCars(ctx)
+		require.NoError(t, err)
+		assert.Len(t, cars, 3, "the readings are not listed as cars")
+		return nil
+	}))
+}
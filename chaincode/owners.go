@@ -0,0 +1,451 @@
+package chaincode
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/yimialmonte/chaincode-cars/asset"
+)
+
+// ownerIndex stores the owners under owner composite keys, apart from the
+// cars listed by range
+const ownerIndex = "owner"
+
+// OwnerCollection is the private data collection, defined in
+// collections_config.json, holding the personal data of the owners
+const OwnerCollection = "ownerPII"
+
+// accountOwnerIndex maps, in the owner collection, the token account of a
+// linked identity to its owner
+const accountOwnerIndex = "account~owner"
+
+// privacyOfficerRole may erase the personal data of any owner
+const privacyOfficerRole = "privacy-officer"
+
+// registrarRole may update the owners not linked to an identity, like
+// linking one to them
+const registrarRole = "registrar"
+
+// Transient keys of the owner transactions, personal data is never passed as
+// an argument since the arguments are recorded in the public transaction
+const (
+	transientName     = "name"
+	transientIdentity = "identity"
+	transientSalt     = "salt"
+)
+
+// minSaltSize is the minimum number of random bytes of the salt hashing a
+// linked identity
+const minSaltSize = 16
+
+// ownerPrivate is the private data of an owner, Salt hashes the linked
+// identity and never leaves the collection
+type ownerPrivate struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Identity string `json:"identity,omitempty"`
+	Salt     string `json:"salt,omitempty"`
+}
+
+// CreateOwner registers an owner under a pseudonymous id. The name, and
+// optionally the client ID of the identity allowed to update the owner with
+// a random salt hashing it, are read from the name, identity and salt
+// transient keys.
+func (s *SmartContract) CreateOwner(ctx contractapi.TransactionContextInterface, id, ownerType string) error {
+	transient, err := ctx.GetStub().GetTransient()
+	if err != nil {
+		return err
+	}
+
+	registration := asset.OwnerRegistration{
+		ID:       id,
+		Name:     string(transient[transientName]),
+		Type:     ownerType,
+		Identity: string(transient[transientIdentity]),
+	}
+	if err := registration.Validate(); err != nil {
+		return err
+	}
+
+	existing, err := getOwner(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if existing != nil {
+		return fmt.Errorf("the owner with id %s already exist", id)
+	}
+
+	private := &ownerPrivate{ID: id, Name: registration.Name, Identity: registration.Identity}
+	if private.Identity != "" {
+		if private.Salt, err = ownerSalt(transient); err != nil {
+			return err
+		}
+	}
+
+	if err := linkAccount(ctx, private.Identity, id); err != nil {
+		return err
+	}
+
+	return putOwner(ctx, &asset.Owner{ID: id, Type: ownerType}, private)
+}
+
+// UpdateOwner changes the type of the owner and, when the transient keys are
+// set, its name and linked identity. A linked owner can only be updated by
+// its identity, the others by a registrar or a privacy officer.
+func (s *SmartContract) UpdateOwner(ctx contractapi.TransactionContextInterface, id, ownerType string) error {
+	owner, private, err := linkedOwner(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if owner.IdentityHash != "" {
+		if err := requireIdentity(ctx, owner, private); err != nil {
+			return err
+		}
+	} else if err := requireUnlinkedOwnerRole(ctx, id); err != nil {
+		return err
+	}
+
+	transient, err := ctx.GetStub().GetTransient()
+	if err != nil {
+		return err
+	}
+
+	if name, ok := transient[transientName]; ok {
+		private.Name = string(name)
+	}
+
+	if identity, ok := transient[transientIdentity]; ok && string(identity) != private.Identity {
+		if err := unlinkAccount(ctx, private.Identity); err != nil {
+			return err
+		}
+
+		private.Identity = string(identity)
+		private.Salt = ""
+		if private.Identity != "" {
+			if private.Salt, err = ownerSalt(transient); err != nil {
+				return err
+			}
+		}
+
+		if err := linkAccount(ctx, private.Identity, id); err != nil {
+			return err
+		}
+	}
+
+	registration := asset.OwnerRegistration{ID: id, Name: private.Name, Type: ownerType, Identity: private.Identity}
+	if err := registration.Validate(); err != nil {
+		return err
+	}
+
+	owner.Type = ownerType
+	return putOwner(ctx, owner, private)
+}
+
+// GetOwner returns the public record of the owner
+func (s *SmartContract) GetOwner(ctx contractapi.TransactionContextInterface, id string) (*asset.Owner, error) {
+	owner, err := getOwner(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if owner == nil {
+		return nil, fmt.Errorf("owner does not exist ID: %s", id)
+	}
+
+	return owner, nil
+}
+
+// GetOwnerPII returns the personal data of the owner, only the peers of the
+// organizations members of the collection can read it
+func (s *SmartContract) GetOwnerPII(ctx contractapi.TransactionContextInterface, id string) (*asset.OwnerPII, error) {
+	_, private, err := linkedOwner(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &asset.OwnerPII{ID: private.ID, Name: private.Name, Identity: private.Identity}, nil
+}
+
+// EraseOwner deletes the personal data of the owner, by request of its
+// linked identity or of a privacy officer. The pseudonymous public record is
+// kept, marked as erased, so the history of the cars stays consistent.
+// Deleting the key keeps its previous versions in the private store of the
+// peers until they are purged.
+func (s *SmartContract) EraseOwner(ctx contractapi.TransactionContextInterface, id string) error {
+	owner, private, err := linkedOwner(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if _, err := requireRole(ctx, privacyOfficerRole); err != nil {
+		if owner.IdentityHash == "" {
+			return err
+		}
+
+		if err := requireIdentity(ctx, owner, private); err != nil {
+			return err
+		}
+	}
+
+	if err := unlinkAccount(ctx, private.Identity); err != nil {
+		return err
+	}
+
+	if err := ctx.GetStub().DelPrivateData(OwnerCollection, id); err != nil {
+		return err
+	}
+
+	owner.IdentityHash = ""
+	owner.Erased = true
+	return putOwner(ctx, owner, nil)
+}
+
+// requireOwners checks the owner and the co-owners of the car are registered
+func requireOwners(ctx contractapi.TransactionContextInterface, car asset.Car) error {
+	ids := []string{car.Owner}
+	for _, share := range car.Owners {
+		if share.Owner != car.Owner {
+			ids = append(ids, share.Owner)
+		}
+	}
+
+	for _, id := range ids {
+		if err := requireOwner(ctx, id); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// requireOwner checks the owner is registered and was not erased
+func requireOwner(ctx contractapi.TransactionContextInterface, id string) error {
+	owner, err := getOwner(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if owner == nil {
+		return fmt.Errorf("owner does not exist ID: %s", id)
+	}
+
+	if owner.Erased {
+		return fmt.Errorf("the owner %s was erased", id)
+	}
+
+	return nil
+}
+
+// requireUnlinkedOwnerRole checks the caller is a registrar or a privacy
+// officer, the only ones updating an owner not linked to an identity
+func requireUnlinkedOwnerRole(ctx contractapi.TransactionContextInterface, id string) error {
+	for _, role := range []string{registrarRole, privacyOfficerRole} {
+		if _, err := requireRole(ctx, role); err == nil {
+			return nil
+		} else if !errors.Is(err, ErrAccessDenied) {
+			return err
+		}
+	}
+
+	return fmt.Errorf("%w, the owner %s is not linked to an identity, only a registrar or a privacy officer updates it", ErrAccessDenied, id)
+}
+
+// linkedOwner returns the public and the private record of an owner not
+// erased
+func linkedOwner(ctx contractapi.TransactionContextInterface, id string) (*asset.Owner, *ownerPrivate, error) {
+	owner, err := getOwner(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if owner == nil {
+		return nil, nil, fmt.Errorf("owner does not exist ID: %s", id)
+	}
+
+	if owner.Erased {
+		return nil, nil, fmt.Errorf("the owner %s was erased", id)
+	}
+
+	privateJSON, err := ctx.GetStub().GetPrivateData(OwnerCollection, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if privateJSON == nil {
+		return nil, nil, fmt.Errorf("the personal data of the owner %s is not available", id)
+	}
+
+	var private ownerPrivate
+	if err := json.Unmarshal(privateJSON, &private); err != nil {
+		return nil, nil, err
+	}
+
+	return owner, &private, nil
+}
+
+// requireIdentity checks the caller is the identity linked to the owner
+func requireIdentity(ctx contractapi.TransactionContextInterface, owner *asset.Owner, private *ownerPrivate) error {
+	caller, err := callerID(ctx)
+	if err != nil {
+		return err
+	}
+
+	if identityHash(private.Salt, caller) != owner.IdentityHash {
+		return fmt.Errorf("%w, the owner %s is linked to another identity", ErrAccessDenied, owner.ID)
+	}
+
+	return nil
+}
+
+// requireOwnerIdentity checks the caller is the identity linked to the
+// owner, an owner without a linked identity can not be acted for
+func requireOwnerIdentity(ctx contractapi.TransactionContextInterface, id string) error {
+	owner, private, err := linkedOwner(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if owner.IdentityHash == "" {
+		return fmt.Errorf("%w, the owner %s is not linked to an identity", ErrAccessDenied, id)
+	}
+
+	return requireIdentity(ctx, owner, private)
+}
+
+// accountOwner returns the owner linked to the token account, empty when
+// there is none
+func accountOwner(ctx contractapi.TransactionContextInterface, account string) (string, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(accountOwnerIndex, []string{account})
+	if err != nil {
+		return "", err
+	}
+
+	owner, err := ctx.GetStub().GetPrivateData(OwnerCollection, key)
+	if err != nil {
+		return "", err
+	}
+
+	return string(owner), nil
+}
+
+// linkAccount links the account of the identity to the owner, an identity
+// can only be linked to one owner
+func linkAccount(ctx contractapi.TransactionContextInterface, identity, ownerID string) error {
+	if identity == "" {
+		return nil
+	}
+
+	linked, err := accountOwner(ctx, accountID(identity))
+	if err != nil {
+		return err
+	}
+
+	if linked != "" && linked != ownerID {
+		return fmt.Errorf("the identity is already linked to the owner %s", linked)
+	}
+
+	key, err := ctx.GetStub().CreateCompositeKey(accountOwnerIndex, []string{accountID(identity)})
+	if err != nil {
+		return err
+	}
+
+	return ctx.GetStub().PutPrivateData(OwnerCollection, key, []byte(ownerID))
+}
+
+func unlinkAccount(ctx contractapi.TransactionContextInterface, identity string) error {
+	if identity == "" {
+		return nil
+	}
+
+	key, err := ctx.GetStub().CreateCompositeKey(accountOwnerIndex, []string{accountID(identity)})
+	if err != nil {
+		return err
+	}
+
+	return ctx.GetStub().DelPrivateData(OwnerCollection, key)
+}
+
+// ownerSalt returns the random salt of the salt transient key, the client
+// generates it since every endorser must write the same one
+func ownerSalt(transient map[string][]byte) (string, error) {
+	salt := transient[transientSalt]
+	if len(salt) < minSaltSize {
+		return "", fmt.Errorf("linking an identity requires a salt of at least %d random bytes", minSaltSize)
+	}
+
+	return hex.EncodeToString(salt), nil
+}
+
+// seedOwnerID returns the pseudonymous id of the i-th owner seeded by the
+// transaction, every endorser generates the same one
+func seedOwnerID(txID string, i int) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s:%d", txID, i)))
+	return "o-" + hex.EncodeToString(sum[:8])
+}
+
+func identityHash(salt, identity string) string {
+	sum := sha256.Sum256([]byte(salt + ":" + identity))
+	return hex.EncodeToString(sum[:])
+}
+
+// getOwner returns nil when the owner does not exist
+func getOwner(ctx contractapi.TransactionContextInterface, id string) (*asset.Owner, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(ownerIndex, []string{id})
+	if err != nil {
+		return nil, err
+	}
+
+	ownerJSON, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if ownerJSON == nil {
+		return nil, nil
+	}
+
+	var owner asset.Owner
+	if err := json.Unmarshal(ownerJSON, &owner); err != nil {
+		return nil, err
+	}
+
+	return &owner, nil
+}
+
+// putOwner writes the public record and, when set, the private one. The
+// identity hash is computed from the private record.
+func putOwner(ctx contractapi.TransactionContextInterface, owner *asset.Owner, private *ownerPrivate) error {
+	if private != nil {
+		owner.IdentityHash = ""
+		if private.Identity != "" {
+			owner.IdentityHash = identityHash(private.Salt, private.Identity)
+		}
+
+		privateJSON, err := json.Marshal(private)
+		if err != nil {
+			return err
+		}
+
+		if err := ctx.GetStub().PutPrivateData(OwnerCollection, owner.ID, privateJSON); err != nil {
+			return err
+		}
+	}
+
+	key, err := ctx.GetStub().CreateCompositeKey(ownerIndex, []string{owner.ID})
+	if err != nil {
+		return err
+	}
+
+	ownerJSON, err := json.Marshal(owner)
+	if err != nil {
+		return err
+	}
+
+	return ctx.GetStub().PutState(key, ownerJSON)
+}
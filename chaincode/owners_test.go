@@ -0,0 +1,203 @@
+package chaincode
+
+import (
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yimialmonte/chaincode-cars/asset"
+	"github.com/yimialmonte/chaincode-cars/chaincode/memstub"
+)
+
+// testSalt is the salt hashing the identities linked in the tests
+var testSalt = []byte("0123456789abcdef")
+
+// createOwners registers the owners as persons named as their id
+func createOwners(t *testing.T, ledger *memstub.Ledger, sc *SmartContract, ids ...string) {
+	for _, id := range ids {
+		id := id
+		require.NoError(t, ledger.RunTransient(map[string][]byte{"name": []byte(id)}, func(ctx contractapi.TransactionContextInterface) error {
+			return sc.CreateOwner(ctx, id, asset.OwnerPerson)
+		}))
+	}
+}
+
+// seededOwners returns the pseudonymous owners of the cars 12 and 22 seeded
+// by InitLedger
+func seededOwners(t *testing.T, ledger *memstub.Ledger) (string, string) {
+	sc := &SmartContract{}
+	var owners []string
+	require.NoError(t, ledger.Run(func(ctx contractapi.TransactionContextInterface) error {
+		for _, id := range []string{"12", "22"} {
+			car, err := sc.GetCar(ctx, id)
+			if err != nil {
+				return err
+			}
+			owners = append(owners, car.Owner)
+		}
+		return nil
+	}))
+
+	return owners[0], owners[1]
+}
+
+func TestOwners(t *testing.T) {
+	juan, err := memstub.NewIdentity("Org1MSP", "juan", nil)
+	require.NoError(t, err)
+	other, err := memstub.NewIdentity("Org1MSP", "other", nil)
+	require.NoError(t, err)
+
+	ledger := memstub.New(memstub.WithCreator(juan))
+	sc := &SmartContract{}
+	require.NoError(t, ledger.Run(sc.InitLedger))
+	ownerJuan, ownerMarcos := seededOwners(t, ledger)
+	assert.NotEqual(t, "Juan", ownerJuan, "the seeded owners are pseudonymous")
+
+	var juanID string
+	require.NoError(t, ledger.Run(func(ctx contractapi.TransactionContextInterface) (err error) {
+		juanID, err = ctx.GetClientIdentity().GetID()
+		return err
+	}))
+
+	tests := []struct {
+		id          string
+		name        string
+		ownerType   string
+		expectedErr string
+	}{
+		{"o-acme", "Acme Rentals SL", asset.OwnerCompany, ""},
+		{"o-acme", "Acme Rentals SL", asset.OwnerCompany, "the owner with id o-acme already exist"},
+		{ownerJuan, "Juan", asset.OwnerPerson, "the owner with id " + ownerJuan + " already exist"},
+		{"o-bob", "", asset.OwnerPerson, "the owner id and name are required"},
+		{"o-bob", "Bob", "robot", `unknown owner type "robot", use person or company`},
+	}
+
+	for _, test := range tests {
+		err := ledger.RunTransient(map[string][]byte{"name": []byte(test.name)}, func(ctx contractapi.TransactionContextInterface) error {
+			return sc.CreateOwner(ctx, test.id, test.ownerType)
+		})
+
+		if test.expectedErr == "" {
+			assert.NoError(t, err)
+		} else {
+			assert.EqualError(t, err, test.expectedErr)
+		}
+	}
+
+	err = ledger.Run(func(ctx contractapi.TransactionContextInterface) error {
+		return sc.CreateCar(ctx, "31", "Kia", "Jaun")
+	})
+	assert.EqualError(t, err, "owner does not exist ID: Jaun", "a typo does not create an owner")
+
+	err = ledger.Run(func(ctx contractapi.TransactionContextInterface) error {
+		return sc.TransferCart(ctx, "12", "o-acme")
+	})
+	assert.True(t, errors.Is(err, ErrAccessDenied), "a stranger does not transfer the car")
+	assert.EqualError(t, err, "access denied, the owner "+ownerJuan+" is not linked to an identity")
+
+	ledger.SetCreator(registrar(t))
+	err = ledger.Run(func(ctx contractapi.TransactionContextInterface) error {
+		return sc.TransferCart(ctx, "12", "Marcso")
+	})
+	assert.EqualError(t, err, "owner does not exist ID: Marcso")
+
+	require.NoError(t, ledger.Run(func(ctx contractapi.TransactionContextInterface) error {
+		return sc.TransferCart(ctx, "12", "o-acme")
+	}))
+
+	var otherID string
+	ledger.SetCreator(other)
+	require.NoError(t, ledger.Run(func(ctx contractapi.TransactionContextInterface) (err error) {
+		otherID, err = ctx.GetClientIdentity().GetID()
+		return err
+	}))
+
+	claim := map[string][]byte{"name": []byte("Juan"), "identity": []byte(otherID), "salt": testSalt}
+	err = ledger.RunTransient(claim, func(ctx contractapi.TransactionContextInterface) error {
+		return sc.UpdateOwner(ctx, ownerJuan, asset.OwnerPerson)
+	})
+	assert.True(t, errors.Is(err, ErrAccessDenied), "an identity does not claim an unlinked owner")
+	assert.EqualError(t, err, "access denied, the owner "+ownerJuan+" is not linked to an identity, only a registrar or a privacy officer updates it")
+
+	ledger.SetCreator(identity(t, "Org1MSP", map[string]string{"role": "registrar"}))
+	transient := map[string][]byte{"name": []byte("Juan Pérez"), "identity": []byte(juanID)}
+	err = ledger.RunTransient(transient, func(ctx contractapi.TransactionContextInterface) error {
+		return sc.UpdateOwner(ctx, ownerJuan, asset.OwnerPerson)
+	})
+	assert.EqualError(t, err, "linking an identity requires a salt of at least 16 random bytes")
+
+	transient["salt"] = testSalt
+	require.NoError(t, ledger.RunTransient(transient, func(ctx contractapi.TransactionContextInterface) error {
+		return sc.UpdateOwner(ctx, ownerJuan, asset.OwnerPerson)
+	}))
+
+	ledger.SetCreator(other)
+	err = ledger.RunTransient(map[string][]byte{"name": []byte("Someone else")}, func(ctx contractapi.TransactionContextInterface) error {
+		return sc.UpdateOwner(ctx, ownerJuan, asset.OwnerPerson)
+	})
+	assert.True(t, errors.Is(err, ErrAccessDenied))
+	assert.EqualError(t, err, "access denied, the owner "+ownerJuan+" is linked to another identity")
+
+	err = ledger.Run(func(ctx contractapi.TransactionContextInterface) error {
+		return sc.EraseOwner(ctx, ownerJuan)
+	})
+	assert.True(t, errors.Is(err, ErrAccessDenied), "only the linked identity or a privacy officer erase")
+
+	err = ledger.Run(func(ctx contractapi.TransactionContextInterface) error {
+		return sc.UpdateOwner(ctx, "nobody", asset.OwnerPerson)
+	})
+	assert.EqualError(t, err, "owner does not exist ID: nobody")
+
+	require.NoError(t, ledger.Run(func(ctx contractapi.TransactionContextInterface) error {
+		owner, err := sc.GetOwner(ctx, ownerJuan)
+		require.NoError(t, err)
+		assert.Equal(t, ownerJuan, owner.ID)
+		assert.Equal(t, identityHash(hex.EncodeToString(testSalt), juanID), owner.IdentityHash, "the identity is hashed with the salt")
+
+		privateJSON, err := ctx.GetStub().GetPrivateData(OwnerCollection, ownerJuan)
+		require.NoError(t, err)
+		assert.Contains(t, string(privateJSON), hex.EncodeToString(testSalt), "the salt is kept in the collection")
+
+		pii, err := sc.GetOwnerPII(ctx, ownerJuan)
+		require.NoError(t, err)
+		assert.Equal(t, &asset.OwnerPII{ID: ownerJuan, Name: "Juan Pérez", Identity: juanID}, pii)
+
+		cars, err := sc.GetCars(ctx)
+		require.NoError(t, err)
+		assert.Len(t, cars, 2, "the owners are not listed as cars")
+		return nil
+	}))
+
+	ledger.SetCreator(juan)
+	require.NoError(t, ledger.Run(func(ctx contractapi.TransactionContextInterface) error {
+		return sc.EraseOwner(ctx, ownerJuan)
+	}))
+
+	ledger.SetCreator(identity(t, "Org1MSP", map[string]string{"role": "privacy-officer"}))
+	require.NoError(t, ledger.Run(func(ctx contractapi.TransactionContextInterface) error {
+		return sc.EraseOwner(ctx, ownerMarcos)
+	}))
+
+	require.NoError(t, ledger.Run(func(ctx contractapi.TransactionContextInterface) error {
+		owner, err := sc.GetOwner(ctx, ownerJuan)
+		require.NoError(t, err)
+		assert.Equal(t, &asset.Owner{ID: ownerJuan, Type: asset.OwnerPerson, Erased: true}, owner)
+
+		_, err = sc.GetOwnerPII(ctx, ownerJuan)
+		assert.EqualError(t, err, "the owner "+ownerJuan+" was erased")
+
+		name, err := ctx.GetStub().GetPrivateData(OwnerCollection, ownerMarcos)
+		require.NoError(t, err)
+		assert.Nil(t, name)
+		return nil
+	}))
+
+	ledger.SetCreator(registrar(t))
+	err = ledger.Run(func(ctx contractapi.TransactionContextInterface) error {
+		return sc.TransferCart(ctx, "12", ownerJuan)
+	})
+	assert.EqualError(t, err, "the owner "+ownerJuan+" was erased")
+}
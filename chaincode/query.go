@@ -0,0 +1,113 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/yimialmonte/chaincode-cars/asset"
+)
+
+// QueryCars searches cars with a CouchDB rich query, the query is a JSON
+// asset.CarQuery and only its fields are turned into the selector
+func (s *SmartContract) QueryCars(ctx contractapi.TransactionContextInterface, query string) ([]*asset.Car, error) {
+	var carQuery asset.CarQuery
+	decoder := json.NewDecoder(strings.NewReader(query))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&carQuery); err != nil {
+		return nil, fmt.Errorf("invalid query, %v", err)
+	}
+
+	selector, err := buildCarSelector(carQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := ctx.GetStub().GetQueryResult(selector)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Close()
+
+	cars := []*asset.Car{}
+	for res.HasNext() {
+		car, err := res.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		var carAsset asset.Car
+		err = json.Unmarshal(car.Value, &carAsset)
+		if err != nil {
+			return nil, err
+		}
+
+		cars = append(cars, &carAsset)
+	}
+
+	return cars, nil
+}
+
+// buildCarSelector turns the query into a CouchDB selector, values are
+// marshaled as JSON so they can not inject operators
+func buildCarSelector(q asset.CarQuery) (string, error) {
+	if err := q.Validate(); err != nil {
+		return "", err
+	}
+
+	// every car has a brand, it tells them apart from the other documents
+	// of the state database, the approvals and the audit records among them
+	selector := map[string]interface{}{"brand": map[string]bool{"$exists": true}}
+	if brand := strings.TrimSpace(q.Brand); brand != "" {
+		selector["brand"] = brand
+	}
+
+	if owner := strings.TrimSpace(q.Owner); owner != "" {
+		// the owner or any co-owner
+		selector["$or"] = []map[string]interface{}{
+			{"owner": owner},
+			{"owners": map[string]interface{}{"$elemMatch": map[string]string{"owner": owner}}},
+		}
+	}
+
+	switch q.Type {
+	case "":
+	case asset.VehicleCar:
+		// cars have no type
+		selector["type"] = map[string]bool{"$exists": false}
+	default:
+		selector["type"] = q.Type
+	}
+
+	if r := rangeCondition(q.MinTransfers, q.MaxTransfers); r != nil {
+		selector["transfersCount"] = r
+	}
+
+	if r := rangeCondition(q.MinYear, q.MaxYear); r != nil {
+		selector["year"] = r
+	}
+
+	queryJSON, err := json.Marshal(map[string]interface{}{"selector": selector})
+	if err != nil {
+		return "", err
+	}
+
+	return string(queryJSON), nil
+}
+
+func rangeCondition(min, max *int) map[string]int {
+	if min == nil && max == nil {
+		return nil
+	}
+
+	condition := map[string]int{}
+	if min != nil {
+		condition["$gte"] = *min
+	}
+	if max != nil {
+		condition["$lte"] = *max
+	}
+
+	return condition
+}
@@ -0,0 +1,117 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yimialmonte/chaincode-cars/asset"
+	"github.com/yimialmonte/chaincode-cars/chaincode/mocks"
+)
+
+func TestQueryCars(t *testing.T) {
+	car := asset.Car{ID: "123", Owner: "Peter", Brand: "Honda", Year: 2015}
+	b, err := json.Marshal(car)
+	require.NoError(t, err)
+
+	tests := []struct {
+		query            string
+		err              error
+		expectedErr      error
+		expectedSelector string
+	}{
+		{
+			`{"brand":"Honda","owner":"Peter"}`,
+			nil,
+			nil,
+			`{"selector":{"$or":[{"owner":"Peter"},{"owners":{"$elemMatch":{"owner":"Peter"}}}],"brand":"Honda"}}`,
+		},
+		{
+			`{"minTransfers":1,"maxTransfers":3,"minYear":2010}`,
+			nil,
+			nil,
+			`{"selector":{"brand":{"$exists":true},"transfersCount":{"$gte":1,"$lte":3},"year":{"$gte":2010}}}`,
+		},
+		{
+			`{"type":"truck","minYear":2010}`,
+			nil,
+			nil,
+			`{"selector":{"brand":{"$exists":true},"type":"truck","year":{"$gte":2010}}}`,
+		},
+		{
+			`{"type":"car"}`,
+			nil,
+			nil,
+			`{"selector":{"brand":{"$exists":true},"type":{"$exists":false}}}`,
+		},
+		{
+			`{"type":"boat"}`,
+			nil,
+			errors.New(`unknown vehicle type "boat"`),
+			"",
+		},
+		{
+			`{"brand":{"$regex":".*"}}`,
+			nil,
+			errors.New("invalid query, json: cannot unmarshal object into Go struct field CarQuery.brand of type string"),
+			"",
+		},
+		{
+			`{"$or":[{"brand":"Honda"}]}`,
+			nil,
+			errors.New(`invalid query, json: unknown field "$or"`),
+			"",
+		},
+		{
+			`{}`,
+			nil,
+			errors.New("supply at least one search criterion"),
+			"",
+		},
+		{
+			`{"minYear":2020,"maxYear":2010}`,
+			nil,
+			errors.New("minYear is greater than maxYear"),
+			"",
+		},
+		{
+			`{"owner":"Peter"}`,
+			errors.New("connection failed"),
+			errors.New("connection failed"),
+			`{"selector":{"$or":[{"owner":"Peter"},{"owners":{"$elemMatch":{"owner":"Peter"}}}],"brand":{"$exists":true}}}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%v", test.query), func(t *testing.T) {
+			it := &mocks.StateQueryIterator{}
+			it.HasNextReturnsOnCall(0, true)
+			it.HasNextReturnsOnCall(1, false)
+			it.NextReturns(&queryresult.KV{Value: b}, nil)
+
+			stub := &mocks.ChaincodeStub{}
+			tctx := &mocks.TransactionContext{}
+			tctx.GetStubReturns(stub)
+			stub.GetQueryResultReturns(it, test.err)
+
+			sc := &SmartContract{}
+			cars, err := sc.QueryCars(tctx, test.query)
+			assert.Equal(t, test.expectedErr, err)
+
+			if test.expectedSelector == "" {
+				assert.Equal(t, 0, stub.GetQueryResultCallCount())
+				return
+			}
+
+			require.Equal(t, 1, stub.GetQueryResultCallCount())
+			assert.Equal(t, test.expectedSelector, stub.GetQueryResultArgsForCall(0))
+			if err == nil {
+				assert.Equal(t, []*asset.Car{&car}, cars)
+			}
+		})
+	}
+}
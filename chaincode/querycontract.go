@@ -0,0 +1,44 @@
+package chaincode
+
+import (
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/yimialmonte/chaincode-cars/asset"
+)
+
+// QueryContract is the read-only interface of the registry for the other
+// chaincodes of the channel, like insurance or tax. Its functions keep their
+// names, arguments and results across versions, they are called with
+// InvokeChaincode as query:ReadCar.
+type QueryContract struct {
+	contractapi.Contract
+}
+
+// ReadCar ...
+func (q *QueryContract) ReadCar(ctx contractapi.TransactionContextInterface, id string) (*asset.Car, error) {
+	return (&SmartContract{}).GetCar(ctx, id)
+}
+
+// CarExists ...
+func (q *QueryContract) CarExists(ctx contractapi.TransactionContextInterface, id string) (bool, error) {
+	return (&SmartContract{}).ExistCar(ctx, id)
+}
+
+// GetCarsByOwner returns the cars the owner holds alone or shares
+func (q *QueryContract) GetCarsByOwner(ctx contractapi.TransactionContextInterface, owner string) ([]*asset.Car, error) {
+	return (&SmartContract{}).GetCarsByOwner(ctx, owner)
+}
+
+// GetLiens ...
+func (q *QueryContract) GetLiens(ctx contractapi.TransactionContextInterface, id string) ([]*asset.Lien, error) {
+	return (&SmartContract{}).GetLiens(ctx, id)
+}
+
+// IsTransferable tells whether the car is free of liens and not stolen
+func (q *QueryContract) IsTransferable(ctx contractapi.TransactionContextInterface, id string) (bool, error) {
+	car, err := (&SmartContract{}).GetCar(ctx, id)
+	if err != nil {
+		return false, err
+	}
+
+	return !car.Stolen && car.ActiveLiens == 0, nil
+}
@@ -0,0 +1,51 @@
+package chaincode
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yimialmonte/chaincode-cars/chaincode/memstub"
+)
+
+func TestQueryContract(t *testing.T) {
+	ledger := memstub.New(memstub.WithCreator(identity(t, "BankMSP", map[string]string{"role": "lender"})))
+	sc := &SmartContract{}
+	qc := &QueryContract{}
+	require.NoError(t, ledger.Run(sc.InitLedger))
+	juan, marcos := seededOwners(t, ledger)
+	require.NoError(t, ledger.Run(func(ctx contractapi.TransactionContextInterface) error {
+		_, err := sc.PlaceLien(ctx, "22", 1000)
+		return err
+	}))
+
+	require.NoError(t, ledger.Run(func(ctx contractapi.TransactionContextInterface) error {
+		car, err := qc.ReadCar(ctx, "12")
+		require.NoError(t, err)
+		assert.Equal(t, juan, car.Owner)
+
+		_, err = qc.ReadCar(ctx, "99")
+		assert.EqualError(t, err, "car does not exist ID: 99")
+
+		exists, err := qc.CarExists(ctx, "99")
+		require.NoError(t, err)
+		assert.False(t, exists)
+
+		cars, err := qc.GetCarsByOwner(ctx, marcos)
+		require.NoError(t, err)
+		require.Len(t, cars, 1)
+		assert.Equal(t, "22", cars[0].ID)
+
+		liens, err := qc.GetLiens(ctx, "22")
+		require.NoError(t, err)
+		assert.Len(t, liens, 1)
+
+		for id, expected := range map[string]bool{"12": true, "22": false} {
+			transferable, err := qc.IsTransferable(ctx, id)
+			require.NoError(t, err)
+			assert.Equal(t, expected, transferable, id)
+		}
+		return nil
+	}))
+}
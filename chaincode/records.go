@@ -0,0 +1,104 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/yimialmonte/chaincode-cars/asset"
+)
+
+// recordIndex stores the service records under car~record composite keys
+const recordIndex = "car~record"
+
+// WorkshopMSPs are the workshop and inspection organizations allowed to log
+// service records
+var WorkshopMSPs = []string{"WorkshopMSP", "InspectionMSP"}
+
+// AddServiceRecord logs a service or inspection of the car, the workshop is
+// the organization of the caller
+func (s *SmartContract) AddServiceRecord(ctx contractapi.TransactionContextInterface, carID, recordType, date, documentHash string) (*asset.ServiceRecord, error) {
+	mspID, err := requireMSP(ctx, WorkshopMSPs)
+	if err != nil {
+		return nil, err
+	}
+
+	exist, err := s.ExistCar(ctx, carID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !exist {
+		return nil, fmt.Errorf("car does not exist ID: %s", carID)
+	}
+
+	now, err := txTime(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	record := &asset.ServiceRecord{
+		ID:           ctx.GetStub().GetTxID(),
+		CarID:        carID,
+		Workshop:     mspID,
+		Date:         date,
+		Type:         recordType,
+		DocumentHash: strings.ToLower(documentHash),
+		RecordedAt:   now,
+	}
+
+	if err := record.Validate(); err != nil {
+		return nil, err
+	}
+
+	if record.Date > now[:len(asset.RecordDateLayout)] {
+		return nil, fmt.Errorf("the date %s is in the future", record.Date)
+	}
+
+	key, err := ctx.GetStub().CreateCompositeKey(recordIndex, []string{carID, record.ID})
+	if err != nil {
+		return nil, err
+	}
+
+	recordJSON, err := json.Marshal(record)
+	if err != nil {
+		return nil, err
+	}
+
+	return record, ctx.GetStub().PutState(key, recordJSON)
+}
+
+// GetServiceRecords returns the service records of the car, sorted by date
+func (s *SmartContract) GetServiceRecords(ctx contractapi.TransactionContextInterface, carID string) ([]*asset.ServiceRecord, error) {
+	res, err := ctx.GetStub().GetStateByPartialCompositeKey(recordIndex, []string{carID})
+	if err != nil {
+		return nil, err
+	}
+	defer res.Close()
+
+	records := []*asset.ServiceRecord{}
+	for res.HasNext() {
+		kv, err := res.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		var record asset.ServiceRecord
+		if err := json.Unmarshal(kv.Value, &record); err != nil {
+			return nil, err
+		}
+
+		records = append(records, &record)
+	}
+
+	sort.SliceStable(records, func(i, j int) bool {
+		if records[i].Date != records[j].Date {
+			return records[i].Date < records[j].Date
+		}
+		return records[i].RecordedAt < records[j].RecordedAt
+	})
+
+	return records, nil
+}
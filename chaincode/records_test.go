@@ -0,0 +1,78 @@
+package chaincode
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yimialmonte/chaincode-cars/asset"
+	"github.com/yimialmonte/chaincode-cars/chaincode/memstub"
+)
+
+const invoiceHash = "9F86D081884C7D659A2FEAA0C55AD015A3BF4F1B2B0B822CD15D6C15B0F00A08"
+
+func TestServiceRecords(t *testing.T) {
+	workshop := identity(t, "WorkshopMSP", nil)
+	dealer := identity(t, "Org1MSP", nil)
+
+	ledger := memstub.New(
+		memstub.WithCreator(dealer),
+		memstub.WithClock(func() time.Time { return time.Date(2021, 9, 21, 15, 0, 0, 0, time.UTC) }),
+	)
+	sc := &SmartContract{}
+	require.NoError(t, ledger.Run(sc.InitLedger))
+
+	add := func(carID, recordType, date, hash string) (record *asset.ServiceRecord, err error) {
+		err = ledger.Run(func(ctx contractapi.TransactionContextInterface) error {
+			record, err = sc.AddServiceRecord(ctx, carID, recordType, date, hash)
+			return err
+		})
+		return record, err
+	}
+
+	_, err := add("12", asset.RecordMaintenance, "2021-09-01", invoiceHash)
+	assert.True(t, errors.Is(err, ErrAccessDenied))
+
+	ledger.SetCreator(workshop)
+
+	tests := []struct {
+		carID       string
+		recordType  string
+		date        string
+		hash        string
+		expectedErr string
+	}{
+		{"99", asset.RecordMaintenance, "2021-09-01", invoiceHash, "car does not exist ID: 99"},
+		{"12", "wash", "2021-09-01", invoiceHash, `unknown record type "wash", use maintenance, repair or inspection`},
+		{"12", asset.RecordMaintenance, "01/09/2021", invoiceHash, `the date "01/09/2021" is not in the YYYY-MM-DD format`},
+		{"12", asset.RecordMaintenance, "2021-09-01", "abc", "the document hash must be a hex SHA-256"},
+		{"12", asset.RecordMaintenance, "2021-09-22", invoiceHash, "the date 2021-09-22 is in the future"},
+	}
+
+	for _, test := range tests {
+		_, err := add(test.carID, test.recordType, test.date, test.hash)
+		assert.EqualError(t, err, test.expectedErr)
+	}
+
+	inspection, err := add("12", asset.RecordInspection, "2021-09-21", invoiceHash)
+	require.NoError(t, err)
+	assert.Equal(t, "WorkshopMSP", inspection.Workshop)
+	assert.Equal(t, "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08", inspection.DocumentHash)
+
+	service, err := add("12", asset.RecordMaintenance, "2020-03-10", invoiceHash)
+	require.NoError(t, err)
+
+	require.NoError(t, ledger.Run(func(ctx contractapi.TransactionContextInterface) error {
+		records, err := sc.GetServiceRecords(ctx, "12")
+		require.NoError(t, err)
+		assert.Equal(t, []*asset.ServiceRecord{service, inspection}, records)
+
+		records, err = sc.GetServiceRecords(ctx, "22")
+		require.NoError(t, err)
+		assert.Empty(t, records)
+		return nil
+	}))
+}
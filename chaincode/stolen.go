@@ -0,0 +1,124 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/yimialmonte/chaincode-cars/asset"
+)
+
+// stolenIndex lists the stolen cars under stolen~car composite keys
+const stolenIndex = "stolen~car"
+
+// StolenReporterMSPs are the police and insurer organizations allowed to
+// report cars as stolen or recovered
+var StolenReporterMSPs = []string{"PoliceMSP", "InsurerMSP"}
+
+// stolenEntry is the value of the stolen index
+type stolenEntry struct {
+	ReportedBy string `json:"reportedBy"`
+	ReportedAt string `json:"reportedAt"`
+}
+
+// ReportStolen flags the car as stolen, which freezes its transfers
+func (s *SmartContract) ReportStolen(ctx contractapi.TransactionContextInterface, id string) error {
+	mspID, err := requireMSP(ctx, StolenReporterMSPs)
+	if err != nil {
+		return err
+	}
+
+	car, err := s.GetCar(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if car.Stolen {
+		return fmt.Errorf("the car %s is already reported stolen", id)
+	}
+
+	now, err := txTime(ctx)
+	if err != nil {
+		return err
+	}
+
+	entryJSON, err := json.Marshal(stolenEntry{ReportedBy: mspID, ReportedAt: now})
+	if err != nil {
+		return err
+	}
+
+	key, err := ctx.GetStub().CreateCompositeKey(stolenIndex, []string{id})
+	if err != nil {
+		return err
+	}
+
+	if err := ctx.GetStub().PutState(key, entryJSON); err != nil {
+		return err
+	}
+
+	car.Stolen = true
+	car.StolenReport = fmt.Sprintf("reported by %s at %s", mspID, now)
+	car.Revision++
+	return putCar(ctx, car)
+}
+
+// ReportRecovered clears the stolen flag of the car
+func (s *SmartContract) ReportRecovered(ctx contractapi.TransactionContextInterface, id string) error {
+	if _, err := requireMSP(ctx, StolenReporterMSPs); err != nil {
+		return err
+	}
+
+	car, err := s.GetCar(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if !car.Stolen {
+		return fmt.Errorf("the car %s is not reported stolen", id)
+	}
+
+	key, err := ctx.GetStub().CreateCompositeKey(stolenIndex, []string{id})
+	if err != nil {
+		return err
+	}
+
+	if err := ctx.GetStub().DelState(key); err != nil {
+		return err
+	}
+
+	car.Stolen = false
+	car.StolenReport = ""
+	car.Revision++
+	return putCar(ctx, car)
+}
+
+// GetStolenCars lists the cars reported stolen
+func (s *SmartContract) GetStolenCars(ctx contractapi.TransactionContextInterface) ([]*asset.Car, error) {
+	res, err := ctx.GetStub().GetStateByPartialCompositeKey(stolenIndex, []string{})
+	if err != nil {
+		return nil, err
+	}
+	defer res.Close()
+
+	cars := []*asset.Car{}
+	for res.HasNext() {
+		kv, err := res.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		_, attrs, err := ctx.GetStub().SplitCompositeKey(kv.Key)
+		if err != nil {
+			return nil, err
+		}
+
+		car, err := s.GetCar(ctx, attrs[0])
+		if err != nil {
+			return nil, err
+		}
+
+		cars = append(cars, car)
+	}
+
+	return cars, nil
+}
@@ -0,0 +1,78 @@
+package chaincode
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yimialmonte/chaincode-cars/asset"
+	"github.com/yimialmonte/chaincode-cars/chaincode/memstub"
+)
+
+func TestStolenCars(t *testing.T) {
+	police := identity(t, "PoliceMSP", nil)
+	dealer := registrar(t)
+
+	ledger := memstub.New(memstub.WithCreator(dealer))
+	sc := &SmartContract{}
+	require.NoError(t, ledger.Run(sc.InitLedger))
+	createOwners(t, ledger, sc, "Max")
+
+	err := ledger.Run(func(ctx contractapi.TransactionContextInterface) error {
+		return sc.ReportStolen(ctx, "12")
+	})
+	assert.True(t, errors.Is(err, ErrAccessDenied))
+	assert.EqualError(t, err, "access denied, organization Org1MSP is not allowed")
+
+	ledger.SetCreator(police)
+	require.NoError(t, ledger.Run(func(ctx contractapi.TransactionContextInterface) error {
+		return sc.ReportStolen(ctx, "12")
+	}))
+
+	err = ledger.Run(func(ctx contractapi.TransactionContextInterface) error {
+		return sc.ReportStolen(ctx, "12")
+	})
+	assert.EqualError(t, err, "the car 12 is already reported stolen")
+
+	ledger.SetCreator(dealer)
+	err = ledger.Run(func(ctx contractapi.TransactionContextInterface) error {
+		return sc.TransferCart(ctx, "12", "Max")
+	})
+	assert.EqualError(t, err, "unable to process transaction, car 12 is reported stolen")
+
+	require.NoError(t, ledger.Run(func(ctx contractapi.TransactionContextInterface) error {
+		car, err := sc.GetCar(ctx, "12")
+		require.NoError(t, err)
+		assert.True(t, car.Stolen)
+		assert.Contains(t, car.StolenReport, "reported by PoliceMSP")
+
+		cars, err := sc.GetStolenCars(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, []*asset.Car{car}, cars)
+
+		all, err := sc.GetCars(ctx)
+		require.NoError(t, err)
+		assert.Len(t, all, 2, "the index is not listed as a car")
+		return nil
+	}))
+
+	ledger.SetCreator(police)
+	require.NoError(t, ledger.Run(func(ctx contractapi.TransactionContextInterface) error {
+		return sc.ReportRecovered(ctx, "12")
+	}))
+
+	err = ledger.Run(func(ctx contractapi.TransactionContextInterface) error {
+		return sc.ReportRecovered(ctx, "12")
+	})
+	assert.EqualError(t, err, "the car 12 is not reported stolen")
+
+	ledger.SetCreator(dealer)
+	require.NoError(t, ledger.Run(func(ctx contractapi.TransactionContextInterface) error {
+		cars, err := sc.GetStolenCars(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, cars)
+		return sc.TransferCart(ctx, "12", "Max")
+	}))
+}
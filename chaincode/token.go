@@ -0,0 +1,323 @@
+package chaincode
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/yimialmonte/chaincode-cars/asset"
+)
+
+// balanceIndex stores the token balances under balance composite keys
+const balanceIndex = "balance"
+
+// supplyIndex stores the total supply of tokens
+const supplyIndex = "supply"
+
+// minterRole may mint tokens
+const minterRole = "minter"
+
+// offerIndex stores the sale offers under offer~car composite keys
+const offerIndex = "offer~car"
+
+// Mint creates tokens in the account of the caller
+func (s *SmartContract) Mint(ctx contractapi.TransactionContextInterface, amount int64) error {
+	if _, err := requireRole(ctx, minterRole); err != nil {
+		return err
+	}
+
+	if amount <= 0 {
+		return fmt.Errorf("the amount must be greater than zero")
+	}
+
+	account, err := callerAccount(ctx)
+	if err != nil {
+		return err
+	}
+
+	supply, err := getTokens(ctx, supplyIndex, nil)
+	if err != nil {
+		return err
+	}
+
+	if supply > math.MaxInt64-amount {
+		return fmt.Errorf("minting %d tokens overflows the total supply", amount)
+	}
+
+	balance, err := getTokens(ctx, balanceIndex, []string{account})
+	if err != nil {
+		return err
+	}
+
+	if err := putTokens(ctx, supplyIndex, nil, supply+amount); err != nil {
+		return err
+	}
+
+	return putTokens(ctx, balanceIndex, []string{account}, balance+amount)
+}
+
+// Transfer moves tokens from the account of the caller to the recipient
+// account
+func (s *SmartContract) Transfer(ctx contractapi.TransactionContextInterface, recipient string, amount int64) error {
+	account, err := callerAccount(ctx)
+	if err != nil {
+		return err
+	}
+
+	return moveTokens(ctx, account, recipient, amount)
+}
+
+// BalanceOf ...
+func (s *SmartContract) BalanceOf(ctx contractapi.TransactionContextInterface, account string) (int64, error) {
+	return getTokens(ctx, balanceIndex, []string{account})
+}
+
+// ClientAccountID returns the account of the caller, the hash of its client
+// ID
+func (s *SmartContract) ClientAccountID(ctx contractapi.TransactionContextInterface) (string, error) {
+	return callerAccount(ctx)
+}
+
+// TotalSupply ...
+func (s *SmartContract) TotalSupply(ctx contractapi.TransactionContextInterface) (int64, error) {
+	return getTokens(ctx, supplyIndex, nil)
+}
+
+// OfferCar puts the car on sale at the price, only the identity linked to
+// the owner offers it. An empty buyer accepts any buyer, a new offer replaces
+// the previous one.
+func (s *SmartContract) OfferCar(ctx contractapi.TransactionContextInterface, id string, price int64, buyer string) (*asset.Offer, error) {
+	if price <= 0 {
+		return nil, fmt.Errorf("the price must be greater than zero")
+	}
+
+	car, err := s.GetCar(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(car.Owners) > 0 {
+		return nil, fmt.Errorf("the car %s is co-owned, it is transferred with approvals", id)
+	}
+
+	if err := requireOwnerIdentity(ctx, car.Owner); err != nil {
+		return nil, err
+	}
+
+	if buyer != "" {
+		if err := requireOwner(ctx, buyer); err != nil {
+			return nil, err
+		}
+	}
+
+	now, err := txTime(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	offer := &asset.Offer{CarID: id, Seller: car.Owner, Buyer: buyer, Price: price, OfferedAt: now}
+	offerJSON, err := json.Marshal(offer)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := ctx.GetStub().CreateCompositeKey(offerIndex, []string{id})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ctx.GetStub().PutState(key, offerJSON); err != nil {
+		return nil, err
+	}
+
+	return offer, nil
+}
+
+// PurchaseCar pays the price from the account of the caller to the account
+// of the seller and transfers the car to the owner linked to the caller. The
+// car must be offered by its owner to the caller at the same price, the
+// offer is removed by the transfer. The payment and the transfer fail
+// together.
+func (s *SmartContract) PurchaseCar(ctx contractapi.TransactionContextInterface, id string, price int64) (*asset.Sale, error) {
+	if price <= 0 {
+		return nil, fmt.Errorf("the price must be greater than zero")
+	}
+
+	car, err := s.GetCar(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(car.Owners) > 0 {
+		return nil, fmt.Errorf("the car %s is co-owned, it is transferred with approvals", id)
+	}
+
+	offerKey, err := ctx.GetStub().CreateCompositeKey(offerIndex, []string{id})
+	if err != nil {
+		return nil, err
+	}
+
+	offerJSON, err := ctx.GetStub().GetState(offerKey)
+	if err != nil {
+		return nil, err
+	}
+
+	var offer asset.Offer
+	if offerJSON != nil {
+		if err := json.Unmarshal(offerJSON, &offer); err != nil {
+			return nil, err
+		}
+	}
+
+	if offer.Seller != car.Owner {
+		return nil, fmt.Errorf("the car %s is not offered for sale", id)
+	}
+
+	if offer.Price != price {
+		return nil, fmt.Errorf("the price %d does not match the offer of %d", price, offer.Price)
+	}
+
+	_, seller, err := linkedOwner(ctx, car.Owner)
+	if err != nil {
+		return nil, err
+	}
+
+	if seller.Identity == "" {
+		return nil, fmt.Errorf("the seller %s is not linked to an identity, it can not be paid", car.Owner)
+	}
+
+	buyerAccount, err := callerAccount(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	buyer, err := accountOwner(ctx, buyerAccount)
+	if err != nil {
+		return nil, err
+	}
+
+	if buyer == "" {
+		return nil, fmt.Errorf("the caller is not linked to a registered owner")
+	}
+
+	if offer.Buyer != "" && offer.Buyer != buyer {
+		return nil, fmt.Errorf("the car %s is offered to another buyer", id)
+	}
+
+	if err := moveTokens(ctx, buyerAccount, accountID(seller.Identity), price); err != nil {
+		return nil, err
+	}
+
+	sellerID := car.Owner
+	if err := s.transferCar(ctx, car, buyer); err != nil {
+		return nil, err
+	}
+
+	now, err := txTime(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &asset.Sale{
+		CarID:  id,
+		Seller: sellerID,
+		Buyer:  buyer,
+		Price:  price,
+		TxID:   ctx.GetStub().GetTxID(),
+		SoldAt: now,
+	}, nil
+}
+
+// clearOffer removes the sale offer of the car, an offer is only valid for
+// the owner who made it
+func clearOffer(ctx contractapi.TransactionContextInterface, id string) error {
+	key, err := ctx.GetStub().CreateCompositeKey(offerIndex, []string{id})
+	if err != nil {
+		return err
+	}
+
+	return ctx.GetStub().DelState(key)
+}
+
+// moveTokens debits from and credits to, both balances are read before any
+// write
+func moveTokens(ctx contractapi.TransactionContextInterface, from, to string, amount int64) error {
+	if amount <= 0 {
+		return fmt.Errorf("the amount must be greater than zero")
+	}
+
+	if from == to {
+		return fmt.Errorf("the sender and the recipient accounts are the same")
+	}
+
+	fromBalance, err := getTokens(ctx, balanceIndex, []string{from})
+	if err != nil {
+		return err
+	}
+
+	if fromBalance < amount {
+		return fmt.Errorf("insufficient funds, the balance is %d and %d are required", fromBalance, amount)
+	}
+
+	toBalance, err := getTokens(ctx, balanceIndex, []string{to})
+	if err != nil {
+		return err
+	}
+
+	if toBalance > math.MaxInt64-amount {
+		return fmt.Errorf("the balance of the recipient overflows")
+	}
+
+	if err := putTokens(ctx, balanceIndex, []string{from}, fromBalance-amount); err != nil {
+		return err
+	}
+
+	return putTokens(ctx, balanceIndex, []string{to}, toBalance+amount)
+}
+
+// accountID hashes the client ID so the accounts do not expose the
+// identities on the public ledger
+func accountID(identity string) string {
+	sum := sha256.Sum256([]byte(identity))
+	return hex.EncodeToString(sum[:])
+}
+
+func callerAccount(ctx contractapi.TransactionContextInterface) (string, error) {
+	caller, err := callerID(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return accountID(caller), nil
+}
+
+func getTokens(ctx contractapi.TransactionContextInterface, index string, attributes []string) (int64, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(index, attributes)
+	if err != nil {
+		return 0, err
+	}
+
+	value, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return 0, err
+	}
+
+	if value == nil {
+		return 0, nil
+	}
+
+	return strconv.ParseInt(string(value), 10, 64)
+}
+
+func putTokens(ctx contractapi.TransactionContextInterface, index string, attributes []string, amount int64) error {
+	key, err := ctx.GetStub().CreateCompositeKey(index, attributes)
+	if err != nil {
+		return err
+	}
+
+	return ctx.GetStub().PutState(key, []byte(strconv.FormatInt(amount, 10)))
+}
@@ -0,0 +1,258 @@
+package chaincode
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yimialmonte/chaincode-cars/asset"
+	"github.com/yimialmonte/chaincode-cars/chaincode/memstub"
+)
+
+// linkOwner registers the owner linked to the client ID of the creator and
+// returns the client ID
+func linkOwner(t *testing.T, ledger *memstub.Ledger, sc *SmartContract, id string, creator []byte) string {
+	ledger.SetCreator(creator)
+	var clientID string
+	require.NoError(t, ledger.Run(func(ctx contractapi.TransactionContextInterface) (err error) {
+		clientID, err = ctx.GetClientIdentity().GetID()
+		return err
+	}))
+
+	transient := map[string][]byte{"name": []byte(id), "identity": []byte(clientID), "salt": testSalt}
+	require.NoError(t, ledger.RunTransient(transient, func(ctx contractapi.TransactionContextInterface) error {
+		return sc.CreateOwner(ctx, id, asset.OwnerPerson)
+	}))
+
+	return clientID
+}
+
+func TestPurchaseCar(t *testing.T) {
+	bank, err := memstub.NewIdentity("Org1MSP", "bank", map[string]string{"role": "minter"})
+	require.NoError(t, err)
+	ana, err := memstub.NewIdentity("Org1MSP", "ana", nil)
+	require.NoError(t, err)
+	max, err := memstub.NewIdentity("Org1MSP", "max", nil)
+	require.NoError(t, err)
+
+	ledger := memstub.New(memstub.WithCreator(bank))
+	sc := &SmartContract{}
+	require.NoError(t, ledger.Run(sc.InitLedger))
+
+	anaID := linkOwner(t, ledger, sc, "o-ana", ana)
+	anaAccount := accountID(anaID)
+	maxAccount := accountID(linkOwner(t, ledger, sc, "o-max", max))
+	require.NoError(t, ledger.Run(func(ctx contractapi.TransactionContextInterface) error {
+		return sc.CreateCar(ctx, "31", "Kia", "o-max")
+	}))
+
+	err = ledger.RunTransient(map[string][]byte{"name": []byte("Ana"), "identity": []byte(anaID), "salt": testSalt}, func(ctx contractapi.TransactionContextInterface) error {
+		return sc.CreateOwner(ctx, "o-other", asset.OwnerPerson)
+	})
+	assert.EqualError(t, err, "the identity is already linked to the owner o-ana")
+
+	err = ledger.Run(func(ctx contractapi.TransactionContextInterface) error {
+		return sc.Mint(ctx, 1000)
+	})
+	assert.True(t, errors.Is(err, ErrAccessDenied), "only a minter mints")
+
+	ledger.SetCreator(bank)
+	require.NoError(t, ledger.Run(func(ctx contractapi.TransactionContextInterface) error {
+		return sc.Mint(ctx, 1000)
+	}))
+	require.NoError(t, ledger.Run(func(ctx contractapi.TransactionContextInterface) error {
+		return sc.Transfer(ctx, anaAccount, 500)
+	}))
+
+	err = ledger.Run(func(ctx contractapi.TransactionContextInterface) error {
+		return sc.Transfer(ctx, anaAccount, 600)
+	})
+	assert.EqualError(t, err, "insufficient funds, the balance is 500 and 600 are required")
+
+	offer := func(id string, price int64, buyer string) error {
+		return ledger.Run(func(ctx contractapi.TransactionContextInterface) error {
+			_, err := sc.OfferCar(ctx, id, price, buyer)
+			return err
+		})
+	}
+
+	err = ledger.Run(func(ctx contractapi.TransactionContextInterface) error {
+		_, err := sc.PurchaseCar(ctx, "31", 400)
+		return err
+	})
+	assert.EqualError(t, err, "the car 31 is not offered for sale", "an unsolicited purchase is rejected")
+
+	ledger.SetCreator(ana)
+	err = offer("31", 400, "")
+	assert.True(t, errors.Is(err, ErrAccessDenied), "only the owner offers the car")
+	assert.EqualError(t, err, "access denied, the owner o-max is linked to another identity")
+
+	juan, _ := seededOwners(t, ledger)
+	err = offer("12", 100, "")
+	assert.EqualError(t, err, "access denied, the owner "+juan+" is not linked to an identity")
+
+	ledger.SetCreator(max)
+	assert.EqualError(t, offer("31", 0, ""), "the price must be greater than zero")
+	assert.EqualError(t, offer("31", 400, "o-nobody"), "owner does not exist ID: o-nobody")
+	require.NoError(t, offer("31", 400, juan))
+
+	ledger.SetCreator(bank)
+	err = ledger.Run(func(ctx contractapi.TransactionContextInterface) error {
+		_, err := sc.PurchaseCar(ctx, "31", 400)
+		return err
+	})
+	assert.EqualError(t, err, "the caller is not linked to a registered owner")
+
+	ledger.SetCreator(ana)
+	err = ledger.Run(func(ctx contractapi.TransactionContextInterface) error {
+		_, err := sc.PurchaseCar(ctx, "31", 400)
+		return err
+	})
+	assert.EqualError(t, err, "the car 31 is offered to another buyer")
+
+	ledger.SetCreator(max)
+	require.NoError(t, offer("31", 600, "o-ana"))
+
+	ledger.SetCreator(ana)
+	err = ledger.Run(func(ctx contractapi.TransactionContextInterface) error {
+		_, err := sc.PurchaseCar(ctx, "31", 600)
+		return err
+	})
+	assert.EqualError(t, err, "insufficient funds, the balance is 500 and 600 are required")
+
+	ledger.SetCreator(max)
+	require.NoError(t, offer("31", 400, "o-ana"))
+
+	ledger.SetCreator(ana)
+	tests := []struct {
+		id          string
+		price       int64
+		expectedErr string
+	}{
+		{"31", 100, "the price 100 does not match the offer of 400"},
+		{"31", 0, "the price must be greater than zero"},
+		{"12", 100, "the car 12 is not offered for sale"},
+		{"99", 100, "car does not exist ID: 99"},
+	}
+
+	for _, test := range tests {
+		err := ledger.Run(func(ctx contractapi.TransactionContextInterface) error {
+			_, err := sc.PurchaseCar(ctx, test.id, test.price)
+			return err
+		})
+		assert.EqualError(t, err, test.expectedErr)
+	}
+
+	var sale *asset.Sale
+	require.NoError(t, ledger.Run(func(ctx contractapi.TransactionContextInterface) (err error) {
+		sale, err = sc.PurchaseCar(ctx, "31", 400)
+		return err
+	}))
+	assert.Equal(t, "o-max", sale.Seller)
+	assert.Equal(t, "o-ana", sale.Buyer)
+	assert.Equal(t, int64(400), sale.Price)
+
+	require.NoError(t, ledger.Run(func(ctx contractapi.TransactionContextInterface) error {
+		car, err := sc.GetCar(ctx, "31")
+		require.NoError(t, err)
+		assert.Equal(t, "o-ana", car.Owner)
+		assert.Equal(t, 1, car.TransfersCount)
+
+		balances := map[string]int64{}
+		for name, account := range map[string]string{"ana": anaAccount, "max": maxAccount} {
+			balances[name], err = sc.BalanceOf(ctx, account)
+			require.NoError(t, err)
+		}
+		assert.Equal(t, map[string]int64{"ana": 100, "max": 400}, balances)
+
+		supply, err := sc.TotalSupply(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1000), supply)
+
+		account, err := sc.ClientAccountID(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, anaAccount, account)
+
+		key, err := ctx.GetStub().CreateCompositeKey(offerIndex, []string{"31"})
+		require.NoError(t, err)
+		offerJSON, err := ctx.GetStub().GetState(key)
+		require.NoError(t, err)
+		assert.Nil(t, offerJSON, "the offer is removed with the sale")
+		return nil
+	}))
+
+	ledger.SetCreator(max)
+	err = ledger.Run(func(ctx contractapi.TransactionContextInterface) error {
+		_, err := sc.PurchaseCar(ctx, "31", 400)
+		return err
+	})
+	assert.EqualError(t, err, "the car 31 is not offered for sale", "the offer is not reused")
+
+	ledger.SetCreator(ana)
+	require.NoError(t, offer("31", 100, ""))
+
+	ledger.SetCreator(identity(t, "PoliceMSP", nil))
+	require.NoError(t, ledger.Run(func(ctx contractapi.TransactionContextInterface) error {
+		return sc.ReportStolen(ctx, "31")
+	}))
+
+	ledger.SetCreator(max)
+	err = ledger.Run(func(ctx contractapi.TransactionContextInterface) error {
+		_, err := sc.PurchaseCar(ctx, "31", 100)
+		return err
+	})
+	assert.EqualError(t, err, "unable to process transaction, car 31 is reported stolen")
+
+	require.NoError(t, ledger.Run(func(ctx contractapi.TransactionContextInterface) error {
+		balance, err := sc.BalanceOf(ctx, maxAccount)
+		require.NoError(t, err)
+		assert.Equal(t, int64(400), balance, "a failed transfer does not pay")
+		return nil
+	}))
+}
+
+func TestOfferClearedByTransfer(t *testing.T) {
+	ana, err := memstub.NewIdentity("Org1MSP", "ana", nil)
+	require.NoError(t, err)
+	max, err := memstub.NewIdentity("Org1MSP", "max", nil)
+	require.NoError(t, err)
+
+	ledger := memstub.New()
+	sc := &SmartContract{}
+	require.NoError(t, ledger.Run(sc.InitLedger))
+	linkOwner(t, ledger, sc, "o-ana", ana)
+	linkOwner(t, ledger, sc, "o-max", max)
+	require.NoError(t, ledger.Run(func(ctx contractapi.TransactionContextInterface) error {
+		return sc.CreateCar(ctx, "31", "Kia", "o-max")
+	}))
+
+	require.NoError(t, ledger.Run(func(ctx contractapi.TransactionContextInterface) error {
+		_, err := sc.OfferCar(ctx, "31", 400, "")
+		return err
+	}))
+
+	ledger.SetCreator(ana)
+	err = ledger.Run(func(ctx contractapi.TransactionContextInterface) error {
+		return sc.TransferCart(ctx, "31", "o-ana")
+	})
+	assert.True(t, errors.Is(err, ErrAccessDenied), "only the owner transfers the car")
+	assert.EqualError(t, err, "access denied, the owner o-max is linked to another identity")
+
+	ledger.SetCreator(max)
+	require.NoError(t, ledger.Run(func(ctx contractapi.TransactionContextInterface) error {
+		return sc.TransferCart(ctx, "31", "o-ana")
+	}))
+
+	ledger.SetCreator(ana)
+	require.NoError(t, ledger.Run(func(ctx contractapi.TransactionContextInterface) error {
+		return sc.TransferCart(ctx, "31", "o-max")
+	}))
+
+	err = ledger.Run(func(ctx contractapi.TransactionContextInterface) error {
+		_, err := sc.PurchaseCar(ctx, "31", 400)
+		return err
+	})
+	assert.EqualError(t, err, "the car 31 is not offered for sale", "the offer of a former ownership is not valid again")
+}
@@ -0,0 +1,300 @@
+// Package client calls the cars REST API
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yimialmonte/chaincode-cars/asset"
+)
+
+// Defaults of the retries of the idempotent calls
+const (
+	DefaultRetries = 3
+	DefaultBackoff = 100 * time.Millisecond
+)
+
+// maxBackoff caps the wait between two attempts
+const maxBackoff = 5 * time.Second
+
+// Errors wrapped by an *Error, test them with errors.Is
+var (
+	ErrBadRequest         = errors.New("bad request")
+	ErrForbidden          = errors.New("forbidden")
+	ErrNotFound           = errors.New("not found")
+	ErrConflict           = errors.New("conflict")
+	ErrPreconditionFailed = errors.New("precondition failed")
+	ErrUnavailable        = errors.New("unavailable")
+)
+
+// Error is an unsuccessful response of the API
+type Error struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%d %s: %s", e.StatusCode, http.StatusText(e.StatusCode), e.Message)
+}
+
+// Unwrap maps the status code to one of the client errors
+func (e *Error) Unwrap() error {
+	switch e.StatusCode {
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return ErrBadRequest
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return ErrForbidden
+	case http.StatusNotFound:
+		return ErrNotFound
+	case http.StatusConflict:
+		return ErrConflict
+	case http.StatusPreconditionFailed, http.StatusPreconditionRequired:
+		return ErrPreconditionFailed
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return ErrUnavailable
+	}
+
+	return nil
+}
+
+// Client calls the API at its base URL
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	token      string
+	retries    int
+	backoff    time.Duration
+}
+
+// Option ...
+type Option func(c *Client)
+
+// WithHTTPClient sets the HTTP client, http.DefaultClient by default
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithToken sends the bearer token granting roles to the caller
+func WithToken(token string) Option {
+	return func(c *Client) {
+		c.token = token
+	}
+}
+
+// WithRetries sets how many times an idempotent call is retried and the
+// first wait, doubled on every attempt
+func WithRetries(retries int, backoff time.Duration) Option {
+	return func(c *Client) {
+		c.retries = retries
+		c.backoff = backoff
+	}
+}
+
+// New returns a client of the API at baseURL, like http://localhost:8080
+func New(baseURL string, opts ...Option) (*Client, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base URL, %v", err)
+	}
+
+	if u.Scheme != "http" && u.Scheme != "https" || u.Host == "" {
+		return nil, fmt.Errorf("invalid base URL %s, use http or https", baseURL)
+	}
+
+	c := &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: http.DefaultClient,
+		retries:    DefaultRetries,
+		backoff:    DefaultBackoff,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c, nil
+}
+
+// ListCars returns every car of the registry
+func (c *Client) ListCars(ctx context.Context) ([]*asset.Car, error) {
+	var cars []*asset.Car
+	err := c.do(ctx, http.MethodGet, "/cars", nil, nil, &cars)
+	return cars, err
+}
+
+// CarsByOwner returns the cars held by the owner
+func (c *Client) CarsByOwner(ctx context.Context, owner string) ([]*asset.Car, error) {
+	var cars []*asset.Car
+	err := c.do(ctx, http.MethodGet, "/cars/owner/"+url.PathEscape(owner), nil, nil, &cars)
+	return cars, err
+}
+
+// GetCar returns the car, its Revision is the one to transfer it
+func (c *Client) GetCar(ctx context.Context, id string) (*asset.Car, error) {
+	var car asset.Car
+	if err := c.do(ctx, http.MethodGet, "/cars/"+url.PathEscape(id), nil, nil, &car); err != nil {
+		return nil, err
+	}
+	return &car, nil
+}
+
+// CreateCar registers the car, an ErrConflict when the ID is taken
+func (c *Client) CreateCar(ctx context.Context, car asset.Car) (*asset.Car, error) {
+	var created asset.Car
+	if err := c.do(ctx, http.MethodPut, "/cars/"+url.PathEscape(car.ID), nil, car, &created); err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+// Transfer changes the owner of the car if it is still at the revision, an
+// ErrPreconditionFailed when it was modified in between. It is not retried.
+func (c *Client) Transfer(ctx context.Context, id, newOwner string, revision int) error {
+	header := http.Header{"If-Match": {`"` + strconv.Itoa(revision) + `"`}}
+	return c.do(ctx, http.MethodPost, "/cars", header, asset.Car{ID: id, Owner: newOwner}, nil)
+}
+
+// History returns every version of the car, newest first
+func (c *Client) History(ctx context.Context, id string) ([]*asset.CarRecord, error) {
+	var records []*asset.CarRecord
+	err := c.do(ctx, http.MethodGet, "/cars/"+url.PathEscape(id)+"/history", nil, nil, &records)
+	return records, err
+}
+
+// ImportCars starts an import job with the cars of body, contentType is
+// text/csv or application/x-ndjson. It is not retried.
+func (c *Client) ImportCars(ctx context.Context, body io.Reader, contentType string) (*asset.ImportJob, error) {
+	var job asset.ImportJob
+	header := http.Header{"Content-Type": {contentType}}
+	if err := c.do(ctx, http.MethodPost, "/cars:import", header, body, &job); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// ImportJob returns the status of the import job
+func (c *Client) ImportJob(ctx context.Context, id string) (*asset.ImportJob, error) {
+	var job asset.ImportJob
+	if err := c.do(ctx, http.MethodGet, "/cars/imports/"+url.PathEscape(id), nil, nil, &job); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// ExportCars streams the whole registry to w, format is csv or ndjson
+func (c *Client) ExportCars(ctx context.Context, format string, w io.Writer) error {
+	return c.do(ctx, http.MethodGet, "/cars/export?format="+url.QueryEscape(format), nil, nil, w)
+}
+
+// do sends the request, in is marshaled as JSON unless it is an io.Reader,
+// and decodes the response into out or copies it when it is an io.Writer.
+// The GET requests are retried on network errors and while the API is
+// unavailable.
+func (c *Client) do(ctx context.Context, method, path string, header http.Header, in, out interface{}) error {
+	var body []byte
+	var err error
+	switch in := in.(type) {
+	case nil:
+	case io.Reader:
+		body, err = ioutil.ReadAll(in)
+	default:
+		body, err = json.Marshal(in)
+	}
+	if err != nil {
+		return err
+	}
+
+	retries := 0
+	if method == http.MethodGet {
+		retries = c.retries
+	}
+
+	for attempt := 0; ; attempt++ {
+		err := c.send(ctx, method, path, header, body, out)
+		if err == nil || attempt >= retries || !retryable(err) || ctx.Err() != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(c.wait(attempt)):
+		}
+	}
+}
+
+func (c *Client) send(ctx context.Context, method, path string, header http.Header, body []byte, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return err
+	}
+
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	if req.Header.Get("Accept") == "" {
+		req.Header.Set("Accept", "application/json")
+	}
+	if body != nil && req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= http.StatusBadRequest {
+		message, _ := ioutil.ReadAll(io.LimitReader(res.Body, 4096))
+		return &Error{StatusCode: res.StatusCode, Message: strings.TrimSpace(string(message))}
+	}
+
+	if w, ok := out.(io.Writer); ok {
+		_, err := io.Copy(w, res.Body)
+		return err
+	}
+
+	if out != nil {
+		if err := json.NewDecoder(res.Body).Decode(out); err != nil {
+			return fmt.Errorf("invalid response, %v", err)
+		}
+	}
+
+	return nil
+}
+
+// wait is the backoff before the next attempt
+func (c *Client) wait(attempt int) time.Duration {
+	wait := c.backoff << uint(attempt)
+	if wait <= 0 || wait > maxBackoff {
+		return maxBackoff
+	}
+	return wait
+}
+
+// retryable is true for the network errors, returned by the HTTP client as
+// *url.Error, and the unavailable responses
+func retryable(err error) bool {
+	var netErr *url.Error
+	return errors.As(err, &netErr) || errors.Is(err, ErrUnavailable)
+}
@@ -0,0 +1,252 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yimialmonte/chaincode-cars/asset"
+	"github.com/yimialmonte/chaincode-cars/chaincode/memstub"
+	"github.com/yimialmonte/chaincode-cars/rest/handler"
+	"github.com/yimialmonte/chaincode-cars/rest/repository"
+)
+
+// newRouter serves the car routes of the API with the offline backend
+func newRouter(t *testing.T) *mux.Router {
+	creator, err := memstub.NewIdentity("Org1MSP", "api", map[string]string{"role": "registrar"})
+	require.NoError(t, err)
+
+	store, err := repository.NewOffline(filepath.Join(t.TempDir(), "cars.db"), creator)
+	require.NoError(t, err)
+	t.Cleanup(func() { store.Close() })
+
+	route := mux.NewRouter()
+	jobs := handler.NewImportJobs()
+	route.Handle("/cars/export", &handler.ExportCars{Store: store}).Methods(http.MethodGet)
+	route.Handle("/cars", &handler.GetAllCars{Store: store}).Methods(http.MethodGet)
+	route.Handle("/cars/owner/{id}", &handler.GetCarsOwner{Store: store}).Methods(http.MethodGet)
+	route.Handle("/cars/{id}", &handler.GetCar{Store: store}).Methods(http.MethodGet)
+	route.Handle("/cars/{id}", &handler.CreateCar{Store: store}).Methods(http.MethodPut)
+	route.Handle("/cars", &handler.TransferCarOwner{Store: store}).Methods(http.MethodPost)
+	route.Handle("/cars/{id}/history", &handler.GetCarHistory{Store: store}).Methods(http.MethodGet)
+	route.Handle("/cars:import", &handler.ImportCars{Store: store, Jobs: jobs}).Methods(http.MethodPost)
+	route.Handle("/cars/imports/{id}", &handler.GetImportJob{Jobs: jobs}).Methods(http.MethodGet)
+	return route
+}
+
+// seededOwner returns the pseudonymous owner of a car seeded by InitLedger
+func seededOwner(t *testing.T, c *Client, carID string) string {
+	car, err := c.GetCar(context.Background(), carID)
+	require.NoError(t, err)
+	return car.Owner
+}
+
+func TestClient(t *testing.T) {
+	server := httptest.NewServer(newRouter(t))
+	defer server.Close()
+
+	c, err := New(server.URL+"/", WithRetries(0, 0))
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	cars, err := c.ListCars(ctx)
+	require.NoError(t, err)
+	assert.Len(t, cars, 2)
+
+	car, err := c.GetCar(ctx, "12")
+	require.NoError(t, err)
+	juan, marcos := car.Owner, seededOwner(t, c, "22")
+	assert.Equal(t, &asset.Car{ID: "12", Brand: "Toyota", Owner: juan}, car)
+
+	created, err := c.CreateCar(ctx, asset.Car{ID: "31", Brand: "Kia", Owner: juan, Year: 2018})
+	require.NoError(t, err)
+	assert.Equal(t, &asset.Car{ID: "31", Brand: "Kia", Owner: juan, Year: 2018}, created)
+
+	cars, err = c.CarsByOwner(ctx, juan)
+	require.NoError(t, err)
+	assert.Len(t, cars, 2)
+
+	require.NoError(t, c.Transfer(ctx, "31", marcos, 0))
+
+	car, err = c.GetCar(ctx, "31")
+	require.NoError(t, err)
+	assert.Equal(t, marcos, car.Owner)
+	assert.Equal(t, 1, car.Revision)
+
+	history, err := c.History(ctx, "31")
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	assert.Equal(t, car, history[0].Car)
+	assert.Equal(t, juan, history[1].Car.Owner)
+
+	tests := []struct {
+		name        string
+		call        func() error
+		expectedErr error
+		expectedMsg string
+	}{
+		{"missing car", func() error { _, err := c.GetCar(ctx, "99"); return err }, ErrNotFound, "404 Not Found: car not found, car does not exist ID: 99"},
+		{"taken id", func() error { _, err := c.CreateCar(ctx, asset.Car{ID: "31", Brand: "Kia", Owner: juan}); return err }, ErrConflict, "409 Conflict: car already exists, ID: 31"},
+		{"unknown owner", func() error {
+			_, err := c.CreateCar(ctx, asset.Car{ID: "32", Brand: "Kia", Owner: "Nobody"})
+			return err
+		}, ErrBadRequest, "400 Bad Request: invalid car, owner does not exist ID: Nobody"},
+		{"stale revision", func() error { return c.Transfer(ctx, "31", juan, 0) }, ErrPreconditionFailed, ""},
+		{"transfer of a missing car", func() error { return c.Transfer(ctx, "99", juan, 0) }, ErrNotFound, ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.call()
+			assert.True(t, errors.Is(err, test.expectedErr), "%v", err)
+
+			var apiErr *Error
+			require.True(t, errors.As(err, &apiErr))
+			if test.expectedMsg != "" {
+				assert.Equal(t, test.expectedMsg, apiErr.Error())
+			}
+		})
+	}
+}
+
+func TestClientImportExport(t *testing.T) {
+	server := httptest.NewServer(newRouter(t))
+	defer server.Close()
+
+	c, err := New(server.URL)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	juan, marcos := seededOwner(t, c, "12"), seededOwner(t, c, "22")
+	job, err := c.ImportCars(ctx, strings.NewReader("id,brand,owner,year\n31,Kia,"+juan+",2018\n32,Seat,"+marcos+",2020\n"), "text/csv")
+	require.NoError(t, err)
+	assert.Equal(t, 2, job.Total)
+
+	for job.Status != asset.JobCompleted && job.Status != asset.JobFailed {
+		time.Sleep(5 * time.Millisecond)
+		job, err = c.ImportJob(ctx, job.ID)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 2, job.Created)
+
+	_, err = c.ImportCars(ctx, strings.NewReader("<cars/>"), "application/xml")
+	var apiErr *Error
+	require.True(t, errors.As(err, &apiErr), "%v", err)
+	assert.Equal(t, http.StatusUnsupportedMediaType, apiErr.StatusCode)
+
+	_, err = c.ImportJob(ctx, "unknown")
+	assert.True(t, errors.Is(err, ErrNotFound))
+
+	var exported bytes.Buffer
+	require.NoError(t, c.ExportCars(ctx, "csv", &exported))
+	assert.Equal(t, "id,brand,owner,year,transfersCount,revision,type,engineCC,axles,payloadKg\n"+
+		"12,Toyota,"+juan+",0,0,0,car,0,0,0\n"+
+		"22,Honda,"+marcos+",0,0,0,car,0,0,0\n"+
+		"31,Kia,"+juan+",2018,0,0,car,0,0,0\n"+
+		"32,Seat,"+marcos+",2020,0,0,car,0,0,0\n", exported.String())
+}
+
+// flaky answers 503 to the first failures requests
+type flaky struct {
+	next     http.Handler
+	failures int32
+	requests int32
+}
+
+func (f *flaky) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if atomic.AddInt32(&f.requests, 1) <= f.failures {
+		http.Error(w, "the peer is not reachable", http.StatusServiceUnavailable)
+		return
+	}
+	f.next.ServeHTTP(w, r)
+}
+
+func TestRetries(t *testing.T) {
+	router := newRouter(t)
+
+	tests := []struct {
+		name             string
+		failures         int32
+		retries          int
+		call             func(c *Client) error
+		expectedErr      error
+		expectedRequests int32
+	}{
+		{"get recovers", 2, 3, func(c *Client) error { _, err := c.GetCar(context.Background(), "12"); return err }, nil, 3},
+		{"get gives up", 5, 2, func(c *Client) error { _, err := c.ListCars(context.Background()); return err }, ErrUnavailable, 3},
+		{"transfer is not retried", 1, 3, func(c *Client) error { return c.Transfer(context.Background(), "12", "Marcos", 0) }, ErrUnavailable, 1},
+		{"create is not retried", 1, 3, func(c *Client) error { _, err := c.CreateCar(context.Background(), asset.Car{ID: "40"}); return err }, ErrUnavailable, 1},
+		{"not found is not retried", 0, 3, func(c *Client) error { _, err := c.GetCar(context.Background(), "99"); return err }, ErrNotFound, 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			f := &flaky{next: router, failures: test.failures}
+			server := httptest.NewServer(f)
+			defer server.Close()
+
+			c, err := New(server.URL, WithRetries(test.retries, time.Millisecond))
+			require.NoError(t, err)
+
+			err = test.call(c)
+			if test.expectedErr == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.True(t, errors.Is(err, test.expectedErr), "%v", err)
+			}
+			assert.Equal(t, test.expectedRequests, atomic.LoadInt32(&f.requests))
+		})
+	}
+}
+
+func TestRetriesContext(t *testing.T) {
+	f := &flaky{next: http.NotFoundHandler(), failures: 100}
+	server := httptest.NewServer(f)
+	defer server.Close()
+
+	c, err := New(server.URL, WithRetries(10, time.Hour))
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err = c.ListCars(ctx)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded), "%v", err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&f.requests))
+}
+
+func TestNetworkErrors(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	url := server.URL
+	server.Close()
+
+	c, err := New(url, WithRetries(2, time.Millisecond))
+	require.NoError(t, err)
+
+	_, err = c.GetCar(context.Background(), "12")
+	assert.Error(t, err)
+	assert.True(t, retryable(err))
+}
+
+func TestNew(t *testing.T) {
+	for _, baseURL := range []string{"", "localhost:8080", "ftp://cars", "http://"} {
+		_, err := New(baseURL)
+		assert.Error(t, err, baseURL)
+	}
+
+	c, err := New("https://cars.example.com/api/", WithToken("s3cret"))
+	require.NoError(t, err)
+	assert.Equal(t, "https://cars.example.com/api", c.baseURL)
+	assert.Equal(t, "s3cret", c.token)
+	assert.Equal(t, DefaultRetries, c.retries)
+}
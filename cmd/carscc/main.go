@@ -0,0 +1,25 @@
+// Command carscc is the car registry chaincode run by the peer, the transfer
+// hooks are read from CARS_TRANSFER_HOOKS
+package main
+
+import (
+	"log"
+
+	"github.com/yimialmonte/chaincode-cars/chaincode"
+)
+
+func main() {
+	hooks, err := chaincode.TransferHooksFromEnv()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	cc, err := chaincode.NewChaincode(hooks)
+	if err != nil {
+		log.Fatalf("creating the chaincode: %v", err)
+	}
+
+	if err := cc.Start(); err != nil {
+		log.Fatalf("starting the chaincode: %v", err)
+	}
+}
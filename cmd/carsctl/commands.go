@@ -0,0 +1,365 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/yimialmonte/chaincode-cars/asset"
+	"github.com/yimialmonte/chaincode-cars/rest/handler"
+)
+
+// importMediaTypes maps the import formats to the media types of the API
+var importMediaTypes = map[string]string{
+	handler.FormatCSV:    "text/csv",
+	handler.FormatNDJSON: "application/x-ndjson",
+}
+
+// newCommands returns the commands by name, every call has its own flag
+// values
+func newCommands() map[string]*command {
+	commands := map[string]*command{}
+	for _, cmd := range []*command{
+		listCommand(), getCommand(), createCommand(), transferCommand(),
+		historyCommand(), importCommand(), exportCommand(),
+		completionCommand(), completeIDsCommand(),
+	} {
+		commands[cmd.name] = cmd
+	}
+	return commands
+}
+
+func listCommand() *command {
+	var owner string
+	return &command{
+		name:    "list",
+		summary: "List the cars, or the ones held by an owner",
+		define: func(fs *flag.FlagSet) {
+			fs.StringVar(&owner, "owner", "", "only the cars held by the owner")
+		},
+		run: func(ctx context.Context, a *app, args []string) error {
+			if len(args) != 0 {
+				return usageError("list takes no arguments")
+			}
+
+			r, err := a.open()
+			if err != nil {
+				return err
+			}
+			defer r.Close()
+
+			var cars []*asset.Car
+			if owner != "" {
+				cars, err = r.CarsByOwner(ctx, owner)
+			} else {
+				cars, err = r.ListCars(ctx)
+			}
+			if err != nil {
+				return err
+			}
+
+			if cars == nil {
+				cars = []*asset.Car{}
+			}
+			return a.print(cars, func() table { return carsTable(cars...) })
+		},
+	}
+}
+
+func getCommand() *command {
+	return &command{
+		name:    "get",
+		args:    "ID",
+		summary: "Show a car",
+		run: func(ctx context.Context, a *app, args []string) error {
+			if len(args) != 1 {
+				return usageError("get takes the car ID")
+			}
+
+			r, err := a.open()
+			if err != nil {
+				return err
+			}
+			defer r.Close()
+
+			car, err := r.GetCar(ctx, args[0])
+			if err != nil {
+				return err
+			}
+			return a.print(car, func() table { return carsTable(car) })
+		},
+	}
+}
+
+func createCommand() *command {
+	var car asset.Car
+	return &command{
+		name:    "create",
+		args:    "ID",
+		summary: "Register a car",
+		define: func(fs *flag.FlagSet) {
+			fs.StringVar(&car.Brand, "brand", "", "brand of the car, required")
+			fs.StringVar(&car.Owner, "owner", "", "ID of the owner, required")
+			fs.IntVar(&car.Year, "year", 0, "model year")
+			fs.StringVar(&car.Type, "type", "", "vehicle type, car by default")
+			fs.IntVar(&car.EngineCC, "engine-cc", 0, "engine capacity of a motorcycle")
+			fs.IntVar(&car.Axles, "axles", 0, "axles of a truck")
+			fs.IntVar(&car.PayloadKg, "payload-kg", 0, "payload of a truck")
+		},
+		run: func(ctx context.Context, a *app, args []string) error {
+			if len(args) != 1 {
+				return usageError("create takes the car ID")
+			}
+
+			if car.Brand == "" || car.Owner == "" {
+				return usageError("create requires -brand and -owner")
+			}
+			car.ID = args[0]
+
+			r, err := a.open()
+			if err != nil {
+				return err
+			}
+			defer r.Close()
+
+			created, err := r.CreateCar(ctx, car)
+			if err != nil {
+				return err
+			}
+			return a.print(created, func() table { return carsTable(created) })
+		},
+	}
+}
+
+func transferCommand() *command {
+	var revision int
+	return &command{
+		name:    "transfer",
+		args:    "ID NEW_OWNER",
+		summary: "Transfer a car to a new owner",
+		define: func(fs *flag.FlagSet) {
+			fs.IntVar(&revision, "revision", -1, "expected revision of the car, the current one by default")
+		},
+		run: func(ctx context.Context, a *app, args []string) error {
+			if len(args) != 2 {
+				return usageError("transfer takes the car ID and the new owner")
+			}
+
+			r, err := a.open()
+			if err != nil {
+				return err
+			}
+			defer r.Close()
+
+			if revision < 0 {
+				car, err := r.GetCar(ctx, args[0])
+				if err != nil {
+					return err
+				}
+				revision = car.Revision
+			}
+
+			if err := r.Transfer(ctx, args[0], args[1], revision); err != nil {
+				return err
+			}
+
+			car, err := r.GetCar(ctx, args[0])
+			if err != nil {
+				return err
+			}
+			return a.print(car, func() table { return carsTable(car) })
+		},
+	}
+}
+
+func historyCommand() *command {
+	return &command{
+		name:    "history",
+		args:    "ID",
+		summary: "Show every version of a car, newest first",
+		run: func(ctx context.Context, a *app, args []string) error {
+			if len(args) != 1 {
+				return usageError("history takes the car ID")
+			}
+
+			r, err := a.open()
+			if err != nil {
+				return err
+			}
+			defer r.Close()
+
+			records, err := r.History(ctx, args[0])
+			if err != nil {
+				return err
+			}
+
+			return a.print(records, func() table {
+				t := table{header: []string{"REVISION", "OWNER", "TRANSFERS", "TIMESTAMP", "BLOCK", "TX"}}
+				for _, record := range records {
+					block := "-"
+					if record.BlockNumber > 0 {
+						block = fmt.Sprint(record.BlockNumber)
+					}
+					t.rows = append(t.rows, []string{
+						itoa(record.Car.Revision), record.Car.Owner, itoa(record.Car.TransfersCount), record.Timestamp, block, record.TxID,
+					})
+				}
+				return t
+			})
+		},
+	}
+}
+
+func importCommand() *command {
+	var format string
+	return &command{
+		name:    "import",
+		args:    "FILE",
+		summary: "Register the cars of a CSV or NDJSON file, - reads stdin",
+		define: func(fs *flag.FlagSet) {
+			fs.StringVar(&format, "format", "", "csv or ndjson, from the file extension by default")
+		},
+		run: func(ctx context.Context, a *app, args []string) error {
+			if len(args) != 1 {
+				return usageError("import takes the file")
+			}
+
+			if format == "" {
+				switch strings.ToLower(filepath.Ext(args[0])) {
+				case ".csv":
+					format = handler.FormatCSV
+				case ".ndjson", ".jsonl":
+					format = handler.FormatNDJSON
+				}
+			}
+
+			mediaType, ok := importMediaTypes[format]
+			if !ok {
+				return usageError("supply -format csv or ndjson")
+			}
+
+			var body []byte
+			var err error
+			if args[0] == "-" {
+				body, err = ioutil.ReadAll(a.stdin)
+			} else {
+				body, err = ioutil.ReadFile(args[0])
+			}
+			if err != nil {
+				return err
+			}
+
+			r, err := a.open()
+			if err != nil {
+				return err
+			}
+			defer r.Close()
+
+			job, err := r.ImportCars(ctx, body, mediaType)
+			if err != nil {
+				return err
+			}
+
+			if err := a.print(job, func() table { return importTable(job) }); err != nil {
+				return err
+			}
+
+			if job.Status == asset.JobFailed {
+				return fmt.Errorf("the import failed, %s", job.Error)
+			}
+			if job.Rejected > 0 {
+				return fmt.Errorf("%d of %d cars were rejected", job.Rejected, job.Total)
+			}
+			return nil
+		},
+	}
+}
+
+func exportCommand() *command {
+	var format, file string
+	return &command{
+		name:    "export",
+		summary: "Export the registry as CSV or NDJSON",
+		define: func(fs *flag.FlagSet) {
+			fs.StringVar(&format, "format", handler.FormatCSV, "csv or ndjson")
+			fs.StringVar(&file, "file", "", "file to write, stdout by default")
+		},
+		run: func(ctx context.Context, a *app, args []string) error {
+			if len(args) != 0 {
+				return usageError("export takes no arguments")
+			}
+
+			if _, ok := importMediaTypes[format]; !ok {
+				return usageError("supply -format csv or ndjson")
+			}
+
+			r, err := a.open()
+			if err != nil {
+				return err
+			}
+			defer r.Close()
+
+			if file == "" {
+				return r.ExportCars(ctx, format, a.stdout)
+			}
+
+			f, err := os.Create(file)
+			if err != nil {
+				return err
+			}
+
+			if err := r.ExportCars(ctx, format, f); err != nil {
+				f.Close()
+				return err
+			}
+			return f.Close()
+		},
+	}
+}
+
+func carsTable(cars ...*asset.Car) table {
+	t := table{header: []string{"ID", "BRAND", "OWNER", "YEAR", "TYPE", "TRANSFERS", "REVISION"}}
+	for _, car := range cars {
+		owner := car.Owner
+		if len(car.Owners) > 0 {
+			var shares []string
+			for _, share := range car.Owners {
+				shares = append(shares, fmt.Sprintf("%s (%d%%)", share.Owner, share.Percent))
+			}
+			owner = strings.Join(shares, ", ")
+		}
+
+		year := "-"
+		if car.Year > 0 {
+			year = itoa(car.Year)
+		}
+
+		t.rows = append(t.rows, []string{
+			car.ID, car.Brand, owner, year, car.VehicleType(), itoa(car.TransfersCount), itoa(car.Revision),
+		})
+	}
+	return t
+}
+
+// importTable has a row per car, the cars of a rejected batch without an
+// error of their own are rejected with the batch
+func importTable(job *asset.ImportJob) table {
+	t := table{header: []string{"ID", "RESULT"}}
+	for _, report := range job.Reports {
+		for _, item := range report.Items {
+			result := "created"
+			switch {
+			case item.Error != "":
+				result = "rejected, " + item.Error
+			case !report.Created:
+				result = "rejected with its batch"
+			}
+			t.rows = append(t.rows, []string{item.ID, result})
+		}
+	}
+	return t
+}
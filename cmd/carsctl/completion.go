@@ -0,0 +1,167 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// completeIDsName is the hidden command listing the car IDs for the shell
+// completion
+const completeIDsName = "__complete-ids"
+
+// flagValues are the values completed after a flag
+var flagValues = map[string]string{
+	"target": targetAPI + " " + targetGateway,
+	"o":      strings.Join(outputs, " "),
+	"format": "csv ndjson",
+}
+
+// idCommands take a car ID as first argument
+var idCommands = "get transfer history"
+
+func completionCommand() *command {
+	return &command{
+		name:    "completion",
+		args:    "bash|zsh|fish",
+		summary: "Print the shell completion script",
+		run: func(ctx context.Context, a *app, args []string) error {
+			if len(args) != 1 {
+				return usageError("completion takes the shell, bash, zsh or fish")
+			}
+
+			switch args[0] {
+			case "bash":
+				return writeBashCompletion(a.stdout)
+			case "zsh":
+				fmt.Fprintln(a.stdout, "autoload -U +X bashcompinit && bashcompinit")
+				return writeBashCompletion(a.stdout)
+			case "fish":
+				return writeFishCompletion(a.stdout)
+			}
+
+			return usageError(fmt.Sprintf("unknown shell %s, use bash, zsh or fish", args[0]))
+		},
+	}
+}
+
+func completeIDsCommand() *command {
+	return &command{
+		name:    completeIDsName,
+		summary: "List the car IDs for the shell completion",
+		run: func(ctx context.Context, a *app, args []string) error {
+			r, err := a.open()
+			if err != nil {
+				return err
+			}
+			defer r.Close()
+
+			cars, err := r.ListCars(ctx)
+			if err != nil {
+				return err
+			}
+
+			for _, car := range cars {
+				fmt.Fprintln(a.stdout, car.ID)
+			}
+			return nil
+		},
+	}
+}
+
+// completionNames returns the visible commands and the flags of each one
+func completionNames() ([]string, map[string][]string) {
+	var names []string
+	flags := map[string][]string{}
+	for name, cmd := range newCommands() {
+		if strings.HasPrefix(name, "__") {
+			continue
+		}
+		names = append(names, name)
+
+		a := &app{}
+		a.flagSet(cmd).VisitAll(func(f *flag.Flag) {
+			flags[name] = append(flags[name], "-"+f.Name)
+		})
+		sort.Strings(flags[name])
+	}
+	sort.Strings(names)
+	return names, flags
+}
+
+func writeBashCompletion(w io.Writer) error {
+	names, flags := completionNames()
+
+	var b strings.Builder
+	b.WriteString("# bash completion of carsctl, source <(carsctl completion bash)\n")
+	b.WriteString("_carsctl() {\n")
+	b.WriteString("    local cur=\"${COMP_WORDS[COMP_CWORD]}\" prev=\"${COMP_WORDS[COMP_CWORD-1]}\" flags=\"\"\n")
+	fmt.Fprintf(&b, "    if [ \"$COMP_CWORD\" -eq 1 ]; then\n        COMPREPLY=($(compgen -W %q -- \"$cur\"))\n        return\n    fi\n", strings.Join(names, " "))
+
+	b.WriteString("    case \"$prev\" in\n")
+	for _, name := range sortedKeys(flagValues) {
+		fmt.Fprintf(&b, "        -%s) COMPREPLY=($(compgen -W %q -- \"$cur\")); return ;;\n", name, flagValues[name])
+	}
+	b.WriteString("        -ledger|-file) COMPREPLY=($(compgen -f -- \"$cur\")); return ;;\n")
+	b.WriteString("    esac\n")
+
+	b.WriteString("    case \"${COMP_WORDS[1]}\" in\n")
+	for _, name := range names {
+		fmt.Fprintf(&b, "        %s) flags=%q ;;\n", name, strings.Join(flags[name], " "))
+	}
+	b.WriteString("    esac\n")
+
+	b.WriteString("    if [[ \"$cur\" == -* ]]; then\n        COMPREPLY=($(compgen -W \"$flags\" -- \"$cur\"))\n        return\n    fi\n")
+	b.WriteString("    case \"${COMP_WORDS[1]}\" in\n")
+	fmt.Fprintf(&b, "        %s) COMPREPLY=($(compgen -W \"$(carsctl %s 2>/dev/null)\" -- \"$cur\")) ;;\n", strings.Replace(idCommands, " ", "|", -1), completeIDsName)
+	b.WriteString("        import) COMPREPLY=($(compgen -f -- \"$cur\")) ;;\n")
+	b.WriteString("        completion) COMPREPLY=($(compgen -W \"bash zsh fish\" -- \"$cur\")) ;;\n")
+	b.WriteString("    esac\n")
+	b.WriteString("}\n")
+	b.WriteString("complete -F _carsctl carsctl\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func writeFishCompletion(w io.Writer) error {
+	names, flags := completionNames()
+	commands := newCommands()
+
+	var b strings.Builder
+	b.WriteString("# fish completion of carsctl, carsctl completion fish | source\n")
+	b.WriteString("complete -c carsctl -f\n")
+	for _, name := range names {
+		fmt.Fprintf(&b, "complete -c carsctl -n __fish_use_subcommand -a %s -d %q\n", name, commands[name].summary)
+	}
+
+	for _, name := range names {
+		for _, f := range flags[name] {
+			option := strings.TrimPrefix(f, "-")
+			fmt.Fprintf(&b, "complete -c carsctl -n \"__fish_seen_subcommand_from %s\" -o %s", name, option)
+			if values, ok := flagValues[option]; ok {
+				fmt.Fprintf(&b, " -x -a %q", values)
+			}
+			b.WriteString("\n")
+		}
+	}
+
+	fmt.Fprintf(&b, "complete -c carsctl -n \"__fish_seen_subcommand_from %s\" -a \"(carsctl %s 2>/dev/null)\"\n", idCommands, completeIDsName)
+	b.WriteString("complete -c carsctl -n \"__fish_seen_subcommand_from import\" -F\n")
+	b.WriteString("complete -c carsctl -n \"__fish_seen_subcommand_from completion\" -a \"bash zsh fish\"\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,219 @@
+// Command carsctl operates the car registry through the REST API or
+// straight through the gateway
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/yimialmonte/chaincode-cars/client"
+	"github.com/yimialmonte/chaincode-cars/rest/repository"
+)
+
+// Targets of the commands
+const (
+	targetAPI     = "api"
+	targetGateway = "gateway"
+)
+
+// app holds the common flags and the streams of a run
+type app struct {
+	stdin          io.Reader
+	stdout, stderr io.Writer
+
+	target  string
+	url     string
+	token   string
+	ledger  string
+	output  string
+	timeout time.Duration
+}
+
+// command is a subcommand, define registers its flags and run gets the
+// positional arguments
+type command struct {
+	name    string
+	args    string
+	summary string
+	define  func(fs *flag.FlagSet)
+	run     func(ctx context.Context, a *app, args []string) error
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run executes the command line and returns the exit code
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	commands := newCommands()
+	if len(args) == 0 || args[0] == "-h" || args[0] == "-help" || args[0] == "help" {
+		usage(stderr, commands)
+		if len(args) == 0 {
+			return 2
+		}
+		return 0
+	}
+
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(stderr, "carsctl: unknown command %s\n\n", args[0])
+		usage(stderr, commands)
+		return 2
+	}
+
+	a := &app{stdin: stdin, stdout: stdout, stderr: stderr}
+	fs := a.flagSet(cmd)
+	fs.SetOutput(stderr)
+	positional, err := parseInterspersed(fs, args[1:])
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 2
+	}
+
+	if !contains(outputs, a.output) {
+		fmt.Fprintf(stderr, "carsctl %s: unknown output %s, use %s\n", cmd.name, a.output, strings.Join(outputs, ", "))
+		return 2
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), a.timeout)
+	defer cancel()
+
+	if err := cmd.run(ctx, a, positional); err != nil {
+		fmt.Fprintf(stderr, "carsctl %s: %v\n", cmd.name, err)
+		var usageErr usageError
+		if errors.As(err, &usageErr) {
+			return 2
+		}
+		return 1
+	}
+
+	return 0
+}
+
+// parseInterspersed parses the flags before and after the positional
+// arguments, everything after -- is positional
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for len(args) > 0 {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+
+		rest := fs.Args()
+		if consumed := len(args) - len(rest); consumed > 0 && args[consumed-1] == "--" {
+			return append(positional, rest...), nil
+		}
+
+		if len(rest) == 0 {
+			break
+		}
+		positional = append(positional, rest[0])
+		args = rest[1:]
+	}
+
+	return positional, nil
+}
+
+// usageError is a misuse of the command line
+type usageError string
+
+func (u usageError) Error() string {
+	return string(u)
+}
+
+// flagSet has the common flags and the ones of the command
+func (a *app) flagSet(cmd *command) *flag.FlagSet {
+	fs := flag.NewFlagSet("carsctl "+cmd.name, flag.ContinueOnError)
+	fs.StringVar(&a.target, "target", envOr("CARSCTL_TARGET", targetAPI), "where the commands go, api or gateway")
+	fs.StringVar(&a.url, "url", envOr("CARS_API_URL", "http://localhost:8080"), "base URL of the REST API")
+	fs.StringVar(&a.token, "token", os.Getenv("CARS_API_TOKEN"), "bearer token of the REST API")
+	fs.StringVar(&a.ledger, "ledger", "", "offline ledger file of the gateway target, CARS_BACKEND otherwise")
+	fs.StringVar(&a.output, "o", outputTable, "output format, "+strings.Join(outputs, ", "))
+	fs.DurationVar(&a.timeout, "timeout", 30*time.Second, "time limit of the command")
+	if cmd.define != nil {
+		cmd.define(fs)
+	}
+
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: carsctl %s [flags] %s\n\n%s\n\nFlags:\n", cmd.name, cmd.args, cmd.summary)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// open connects to the target
+func (a *app) open() (registry, error) {
+	switch a.target {
+	case targetAPI:
+		c, err := client.New(a.url, client.WithToken(a.token))
+		if err != nil {
+			return nil, err
+		}
+		return &apiRegistry{Client: c}, nil
+	case targetGateway:
+		config := repository.ConfigFromEnv()
+		if a.ledger != "" {
+			config.Backend = "offline"
+			config.LedgerFile = a.ledger
+		}
+
+		store, err := repository.Open(config)
+		if err != nil {
+			return nil, err
+		}
+
+		if _, ok := store.(*repository.Car); ok {
+			return nil, errors.New("the gateway target needs CARS_BACKEND=offline or -ledger, the fabric backend is a stub that does not write to the ledger")
+		}
+		return &gatewayRegistry{store: store}, nil
+	}
+
+	return nil, usageError(fmt.Sprintf("unknown target %s, use api or gateway", a.target))
+}
+
+// print writes the result in the output format of the flags
+func (a *app) print(value interface{}, view func() table) error {
+	return printResult(a.stdout, a.output, value, view)
+}
+
+func usage(w io.Writer, commands map[string]*command) {
+	fmt.Fprint(w, "carsctl operates the car registry through the REST API or the gateway\n\nUsage: carsctl <command> [flags] [arguments]\n\nCommands:\n")
+
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		if !strings.HasPrefix(name, "__") {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		fmt.Fprintf(w, "  %-11s %s\n", name, commands[name].summary)
+	}
+	fmt.Fprint(w, "\nRun carsctl <command> -h for the flags of a command.\n")
+}
+
+func envOr(name, fallback string) string {
+	if value := os.Getenv(name); value != "" {
+		return value
+	}
+	return fallback
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yimialmonte/chaincode-cars/asset"
+	"github.com/yimialmonte/chaincode-cars/chaincode/memstub"
+	"github.com/yimialmonte/chaincode-cars/rest/handler"
+	"github.com/yimialmonte/chaincode-cars/rest/repository"
+	"gopkg.in/yaml.v3"
+)
+
+func newServer(t *testing.T) *httptest.Server {
+	creator, err := memstub.NewIdentity("Org1MSP", "api", map[string]string{"role": "registrar"})
+	require.NoError(t, err)
+
+	store, err := repository.NewOffline(filepath.Join(t.TempDir(), "cars.db"), creator)
+	require.NoError(t, err)
+	t.Cleanup(func() { store.Close() })
+
+	route := mux.NewRouter()
+	jobs := handler.NewImportJobs()
+	route.Handle("/cars/export", &handler.ExportCars{Store: store}).Methods(http.MethodGet)
+	route.Handle("/cars", &handler.GetAllCars{Store: store}).Methods(http.MethodGet)
+	route.Handle("/cars/owner/{id}", &handler.GetCarsOwner{Store: store}).Methods(http.MethodGet)
+	route.Handle("/cars/{id}", &handler.GetCar{Store: store}).Methods(http.MethodGet)
+	route.Handle("/cars/{id}", &handler.CreateCar{Store: store}).Methods(http.MethodPut)
+	route.Handle("/cars", &handler.TransferCarOwner{Store: store}).Methods(http.MethodPost)
+	route.Handle("/cars/{id}/history", &handler.GetCarHistory{Store: store}).Methods(http.MethodGet)
+	route.Handle("/cars:import", &handler.ImportCars{Store: store, Jobs: jobs}).Methods(http.MethodPost)
+	route.Handle("/cars/imports/{id}", &handler.GetImportJob{Jobs: jobs}).Methods(http.MethodGet)
+
+	server := httptest.NewServer(route)
+	t.Cleanup(server.Close)
+	return server
+}
+
+// carsctl runs the command line and returns the exit code and the output
+func carsctl(stdin string, args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	code := run(args, strings.NewReader(stdin), &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestTargets(t *testing.T) {
+	role, ok := os.LookupEnv("CARS_OFFLINE_ROLE")
+	if ok {
+		defer os.Setenv("CARS_OFFLINE_ROLE", role)
+	} else {
+		defer os.Unsetenv("CARS_OFFLINE_ROLE")
+	}
+	os.Setenv("CARS_OFFLINE_ROLE", "registrar")
+
+	server := newServer(t)
+	targets := map[string][]string{
+		targetAPI:     {"-target", targetAPI, "-url", server.URL},
+		targetGateway: {"-target", targetGateway, "-ledger", filepath.Join(t.TempDir(), "cars.db")},
+	}
+
+	for name, flags := range targets {
+		t.Run(name, func(t *testing.T) {
+			cmd := func(args ...string) (int, string, string) {
+				return carsctl("", append(args, flags...)...)
+			}
+
+			seeded := map[string]string{}
+			for _, id := range []string{"12", "22"} {
+				code, stdout, stderr := cmd("get", "-o", "json", id)
+				require.Equal(t, 0, code, stderr)
+				var car asset.Car
+				require.NoError(t, json.Unmarshal([]byte(stdout), &car))
+				seeded[id] = car.Owner
+			}
+			juan, marcos := seeded["12"], seeded["22"]
+
+			code, stdout, stderr := cmd("list")
+			require.Equal(t, 0, code, stderr)
+			lines := strings.Split(strings.TrimSpace(stdout), "\n")
+			require.Len(t, lines, 3)
+			assert.Equal(t, []string{"ID", "BRAND", "OWNER", "YEAR", "TYPE", "TRANSFERS", "REVISION"}, strings.Fields(lines[0]))
+			assert.Equal(t, []string{"12", "Toyota", juan, "-", "car", "0", "0"}, strings.Fields(lines[1]))
+			assert.Equal(t, []string{"22", "Honda", marcos, "-", "car", "0", "0"}, strings.Fields(lines[2]))
+
+			code, stdout, stderr = cmd("create", "-brand", "Kia", "-owner", juan, "-year", "2018", "-o", "json", "31")
+			require.Equal(t, 0, code, stderr)
+			var car asset.Car
+			require.NoError(t, json.Unmarshal([]byte(stdout), &car))
+			assert.Equal(t, asset.Car{ID: "31", Brand: "Kia", Owner: juan, Year: 2018}, car)
+
+			code, stdout, stderr = cmd("transfer", "31", marcos, "-o", "yaml")
+			require.Equal(t, 0, code, stderr)
+			car = asset.Car{}
+			require.NoError(t, yaml.Unmarshal([]byte(stdout), &car))
+			assert.Equal(t, marcos, car.Owner)
+			assert.Equal(t, 1, car.Revision)
+
+			code, _, stderr = cmd("transfer", "-revision", "0", "31", juan)
+			assert.Equal(t, 1, code)
+			assert.Contains(t, stderr, "carsctl transfer: ")
+
+			code, stdout, stderr = cmd("list", "-owner", marcos, "-o", "json")
+			require.Equal(t, 0, code, stderr)
+			var cars []asset.Car
+			require.NoError(t, json.Unmarshal([]byte(stdout), &cars))
+			assert.Len(t, cars, 2)
+
+			code, stdout, stderr = cmd("history", "-o", "json", "31")
+			require.Equal(t, 0, code, stderr)
+			var records []asset.CarRecord
+			require.NoError(t, json.Unmarshal([]byte(stdout), &records))
+			require.Len(t, records, 2)
+			assert.Equal(t, marcos, records[0].Car.Owner)
+			assert.Equal(t, juan, records[1].Car.Owner)
+
+			code, _, stderr = cmd("get", "99")
+			assert.Equal(t, 1, code)
+			assert.Contains(t, stderr, "99")
+
+			code, stdout, stderr = carsctl("id,brand,owner\n41,Seat,"+juan+"\n42,Fiat,Nobody\n", append([]string{"import", "-format", "csv", "-"}, flags...)...)
+			assert.Equal(t, 1, code)
+			assert.Contains(t, stdout, "41")
+			assert.Contains(t, stdout, "rejected")
+			assert.Contains(t, stderr, "were rejected")
+
+			file := filepath.Join(t.TempDir(), "cars.ndjson")
+			code, _, stderr = cmd("export", "-format", "ndjson", "-file", file)
+			require.Equal(t, 0, code, stderr)
+			body, err := ioutil.ReadFile(file)
+			require.NoError(t, err)
+			assert.Contains(t, string(body), `"id":"31"`)
+
+			code, stdout, stderr = cmd(completeIDsName)
+			require.Equal(t, 0, code, stderr)
+			assert.Contains(t, strings.Fields(stdout), "31")
+		})
+	}
+}
+
+func TestUsage(t *testing.T) {
+	tests := []struct {
+		args           []string
+		expectedCode   int
+		expectedStderr string
+	}{
+		{nil, 2, "Usage: carsctl <command>"},
+		{[]string{"help"}, 0, "Commands:"},
+		{[]string{"drive"}, 2, "carsctl: unknown command drive"},
+		{[]string{"get"}, 2, "carsctl get: get takes the car ID"},
+		{[]string{"get", "-h"}, 0, "Usage: carsctl get [flags] ID"},
+		{[]string{"get", "-wheels", "4", "12"}, 2, "flag provided but not defined: -wheels"},
+		{[]string{"get", "-o", "xml", "12"}, 2, "carsctl get: unknown output xml, use table, json, yaml"},
+		{[]string{"get", "-target", "ssh", "12"}, 2, "carsctl get: unknown target ssh, use api or gateway"},
+		{[]string{"create", "31"}, 2, "carsctl create: create requires -brand and -owner"},
+		{[]string{"import", "cars.xml"}, 2, "carsctl import: supply -format csv or ndjson"},
+		{[]string{"completion", "tcsh"}, 2, "carsctl completion: unknown shell tcsh, use bash, zsh or fish"},
+	}
+
+	for _, test := range tests {
+		code, _, stderr := carsctl("", test.args...)
+		assert.Equal(t, test.expectedCode, code, test.args)
+		assert.Contains(t, stderr, test.expectedStderr, test.args)
+	}
+}
+
+func TestGatewayStub(t *testing.T) {
+	backend, ok := os.LookupEnv("CARS_BACKEND")
+	if ok {
+		defer os.Setenv("CARS_BACKEND", backend)
+	}
+	os.Unsetenv("CARS_BACKEND")
+
+	for _, args := range [][]string{
+		{"create", "-brand", "Kia", "-owner", "o-ana", "31"},
+		{"transfer", "12", "o-ana"},
+		{"import", "-format", "csv", "-"},
+	} {
+		code, stdout, stderr := carsctl("id,brand,owner\n31,Kia,o-ana\n", append(args, "-target", targetGateway)...)
+		assert.Equal(t, 1, code, args)
+		assert.Empty(t, stdout, args)
+		assert.Contains(t, stderr, "the gateway target needs CARS_BACKEND=offline or -ledger", args)
+	}
+}
+
+func TestParseInterspersed(t *testing.T) {
+	tests := []struct {
+		args               []string
+		expectedPositional []string
+		expectedOutput     string
+	}{
+		{[]string{"-o", "json", "31", "Ana"}, []string{"31", "Ana"}, "json"},
+		{[]string{"31", "-o", "json", "Ana"}, []string{"31", "Ana"}, "json"},
+		{[]string{"31", "Ana", "-o", "json"}, []string{"31", "Ana"}, "json"},
+		{[]string{"31", "--", "-o", "json"}, []string{"31", "-o", "json"}, "table"},
+		{[]string{"-"}, []string{"-"}, "table"},
+	}
+
+	for _, test := range tests {
+		a := &app{}
+		fs := a.flagSet(&command{name: "test"})
+		positional, err := parseInterspersed(fs, test.args)
+		require.NoError(t, err)
+		assert.Equal(t, test.expectedPositional, positional, test.args)
+		assert.Equal(t, test.expectedOutput, a.output, test.args)
+	}
+}
+
+func TestCompletion(t *testing.T) {
+	for _, shell := range []string{"bash", "zsh", "fish"} {
+		code, stdout, stderr := carsctl("", "completion", shell)
+		require.Equal(t, 0, code, stderr)
+		assert.Contains(t, stdout, "transfer", shell)
+		assert.Contains(t, stdout, completeIDsName, shell)
+		assert.Contains(t, stdout, "ndjson", shell)
+	}
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Output formats
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+	outputYAML  = "yaml"
+)
+
+var outputs = []string{outputTable, outputJSON, outputYAML}
+
+// table is the tabular view of a result
+type table struct {
+	header []string
+	rows   [][]string
+}
+
+// printResult writes the value as JSON, YAML with the JSON field names or
+// the table
+func printResult(w io.Writer, output string, value interface{}, view func() table) error {
+	switch output {
+	case outputJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(value)
+	case outputYAML:
+		return writeYAML(w, value)
+	case outputTable:
+		t := view()
+		tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		writeRow(tw, t.header)
+		for _, row := range t.rows {
+			writeRow(tw, row)
+		}
+		return tw.Flush()
+	}
+
+	return fmt.Errorf("unknown output %s, use table, json or yaml", output)
+}
+
+// writeYAML goes through JSON so the fields keep their JSON names and
+// order, JSON is valid YAML so it is decoded into block style nodes
+func writeYAML(w io.Writer, value interface{}) error {
+	valueJSON, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	var node yaml.Node
+	if err := yaml.Unmarshal(valueJSON, &node); err != nil {
+		return err
+	}
+	blockStyle(&node)
+
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&node); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+func blockStyle(node *yaml.Node) {
+	node.Style &^= yaml.FlowStyle | yaml.DoubleQuotedStyle
+	for _, child := range node.Content {
+		blockStyle(child)
+	}
+}
+
+func writeRow(w io.Writer, cells []string) {
+	for i, cell := range cells {
+		if i > 0 {
+			io.WriteString(w, "\t")
+		}
+		io.WriteString(w, cell)
+	}
+	io.WriteString(w, "\n")
+}
+
+func itoa(n int) string {
+	return strconv.Itoa(n)
+}
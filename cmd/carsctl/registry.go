@@ -0,0 +1,181 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/yimialmonte/chaincode-cars/asset"
+	"github.com/yimialmonte/chaincode-cars/client"
+	"github.com/yimialmonte/chaincode-cars/rest/handler"
+	"github.com/yimialmonte/chaincode-cars/rest/repository"
+)
+
+// importBatchSize is the number of cars registered per transaction by the
+// gateway target, the same as the API import jobs
+const importBatchSize = 50
+
+// importPollInterval is the wait between two reads of an API import job
+var importPollInterval = 200 * time.Millisecond
+
+// registry is the car registry as seen by the commands, through the REST
+// API or straight to the ledger
+type registry interface {
+	ListCars(ctx context.Context) ([]*asset.Car, error)
+	CarsByOwner(ctx context.Context, owner string) ([]*asset.Car, error)
+	GetCar(ctx context.Context, id string) (*asset.Car, error)
+	CreateCar(ctx context.Context, car asset.Car) (*asset.Car, error)
+	Transfer(ctx context.Context, id, newOwner string, revision int) error
+	History(ctx context.Context, id string) ([]*asset.CarRecord, error)
+	ImportCars(ctx context.Context, body []byte, mediaType string) (*asset.ImportJob, error)
+	ExportCars(ctx context.Context, format string, w io.Writer) error
+	Close() error
+}
+
+// apiRegistry calls the REST API
+type apiRegistry struct {
+	*client.Client
+}
+
+// ImportCars sends the file as it is and waits for the import job
+func (a *apiRegistry) ImportCars(ctx context.Context, body []byte, mediaType string) (*asset.ImportJob, error) {
+	job, err := a.Client.ImportCars(ctx, bytes.NewReader(body), mediaType)
+	if err != nil {
+		return nil, err
+	}
+
+	for job.Status != asset.JobCompleted && job.Status != asset.JobFailed {
+		select {
+		case <-ctx.Done():
+			return job, fmt.Errorf("import job %s is %s, %w", job.ID, job.Status, ctx.Err())
+		case <-time.After(importPollInterval):
+		}
+
+		if job, err = a.Client.ImportJob(ctx, job.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	return job, nil
+}
+
+// Close ...
+func (a *apiRegistry) Close() error {
+	return nil
+}
+
+// gatewayRegistry submits the transactions with the repository of the API
+// server, the Fabric gateway or the offline ledger
+type gatewayRegistry struct {
+	store repository.Store
+}
+
+// ListCars ...
+func (g *gatewayRegistry) ListCars(ctx context.Context) ([]*asset.Car, error) {
+	return g.store.GetCars()
+}
+
+// CarsByOwner ...
+func (g *gatewayRegistry) CarsByOwner(ctx context.Context, owner string) ([]*asset.Car, error) {
+	return g.store.GetCarsByOwner(owner)
+}
+
+// GetCar ...
+func (g *gatewayRegistry) GetCar(ctx context.Context, id string) (*asset.Car, error) {
+	return g.store.GetCar(id)
+}
+
+// CreateCar ...
+func (g *gatewayRegistry) CreateCar(ctx context.Context, car asset.Car) (*asset.Car, error) {
+	return g.store.CreateCar(car)
+}
+
+// Transfer ...
+func (g *gatewayRegistry) Transfer(ctx context.Context, id, newOwner string, revision int) error {
+	return g.store.TransferCart(id, newOwner, revision)
+}
+
+// History ...
+func (g *gatewayRegistry) History(ctx context.Context, id string) ([]*asset.CarRecord, error) {
+	return g.store.GetCarHistory(id)
+}
+
+// ImportCars registers the cars batch by batch like an API import job,
+// every batch with all-or-nothing semantics
+func (g *gatewayRegistry) ImportCars(ctx context.Context, body []byte, mediaType string) (*asset.ImportJob, error) {
+	cars, err := handler.DecodeCars(bytes.NewReader(body), mediaType)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(cars) == 0 {
+		return nil, errors.New("the file has no cars")
+	}
+
+	job := &asset.ImportJob{Status: asset.JobRunning, Total: len(cars)}
+	for start := 0; start < len(cars); start += importBatchSize {
+		end := start + importBatchSize
+		if end > len(cars) {
+			end = len(cars)
+		}
+
+		if err := ctx.Err(); err != nil {
+			return job, err
+		}
+
+		report, err := g.store.CreateCars(cars[start:end])
+		if err != nil {
+			job.Status = asset.JobFailed
+			job.Error = err.Error()
+			return job, nil
+		}
+
+		job.Batches++
+		job.Processed += end - start
+		job.Reports = append(job.Reports, report)
+		if report.Created {
+			job.Created += end - start
+		} else {
+			job.Rejected += end - start
+		}
+	}
+
+	job.Status = asset.JobCompleted
+	return job, nil
+}
+
+// ExportCars writes the registry page by page
+func (g *gatewayRegistry) ExportCars(ctx context.Context, format string, w io.Writer) error {
+	write := handler.WriteCars(w, format)
+	bookmark := ""
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		page, err := g.store.GetCarsPage(importBatchSize, bookmark)
+		if err != nil {
+			return err
+		}
+
+		if err := write(page.Cars); err != nil {
+			return err
+		}
+
+		if page.Bookmark == "" {
+			return nil
+		}
+		bookmark = page.Bookmark
+	}
+}
+
+// Close ...
+func (g *gatewayRegistry) Close() error {
+	if closer, ok := g.store.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
@@ -12,4 +12,6 @@ require (
 	github.com/stretchr/testify v1.7.0
 	golang.org/x/net v0.0.0-20210805182204-aaa1db679c0d // indirect
 	golang.org/x/sys v0.0.0-20210809222454-d867a43fc93e // indirect
+	google.golang.org/grpc v1.23.0
+	gopkg.in/yaml.v3 v3.0.0-20200313102051-9f266ea9e77c
 )
@@ -0,0 +1,83 @@
+// Package blob stores the content of the car attachments outside of the
+// ledger.
+package blob
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+// ErrNotFound is returned by a Store when there is no blob for the key
+var ErrNotFound = errors.New("blob not found")
+
+// Store keeps blobs by key, implementations must be safe for concurrent use
+type Store interface {
+	Put(key string, content io.Reader) error
+	Get(key string) (io.ReadCloser, error)
+}
+
+// validKey restricts the keys to names that are safe as file names
+var validKey = regexp.MustCompile(`^[A-Za-z0-9_-]{1,128}$`)
+
+// FileStore keeps each blob in a file of a local directory
+type FileStore struct {
+	dir string
+}
+
+// NewFileStore creates the directory when it does not exist
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, err
+	}
+
+	return &FileStore{dir: dir}, nil
+}
+
+// Put writes the content to a temporary file renamed once complete, so a
+// reader never sees a partial blob
+func (s *FileStore) Put(key string, content io.Reader) error {
+	if !validKey.MatchString(key) {
+		return fmt.Errorf("invalid blob key %q", key)
+	}
+
+	tmp, err := ioutil.TempFile(s.dir, ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, content); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), filepath.Join(s.dir, key))
+}
+
+// Get ...
+func (s *FileStore) Get(key string) (io.ReadCloser, error) {
+	if !validKey.MatchString(key) {
+		return nil, fmt.Errorf("%w, invalid key %q", ErrNotFound, key)
+	}
+
+	f, err := os.Open(filepath.Join(s.dir, key))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("%w, key %s", ErrNotFound, key)
+	}
+
+	return f, err
+}
@@ -0,0 +1,41 @@
+package blob
+
+import (
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileStore(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewFileStore(filepath.Join(dir, "blobs"))
+	require.NoError(t, err)
+
+	require.NoError(t, store.Put("abc", strings.NewReader("invoice")))
+	require.NoError(t, store.Put("abc", strings.NewReader("invoice v2")))
+
+	content, err := store.Get("abc")
+	require.NoError(t, err)
+	defer content.Close()
+
+	data, err := ioutil.ReadAll(content)
+	require.NoError(t, err)
+	assert.Equal(t, "invoice v2", string(data))
+
+	files, err := ioutil.ReadDir(filepath.Join(dir, "blobs"))
+	require.NoError(t, err)
+	assert.Len(t, files, 1, "no temporary file is left")
+
+	_, err = store.Get("missing")
+	assert.True(t, errors.Is(err, ErrNotFound))
+
+	assert.EqualError(t, store.Put("../escape", strings.NewReader("x")), `invalid blob key "../escape"`)
+
+	_, err = store.Get("../escape")
+	assert.True(t, errors.Is(err, ErrNotFound))
+}
@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/yimialmonte/chaincode-cars/asset"
+)
+
+// ApprovalStore ...
+type ApprovalStore interface {
+	GetTransferApprovals(carID string) ([]*asset.TransferApproval, error)
+	ApproveTransfer(carID, owner, newOwner string, revision int) error
+}
+
+// GetTransferApprovals lists the approvals collected to transfer a co-owned
+// car
+type GetTransferApprovals struct {
+	Store ApprovalStore
+}
+
+func (g *GetTransferApprovals) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	approvals, err := g.Store.GetTransferApprovals(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, err.Error(), storeErrorCode(err))
+		return
+	}
+
+	approvalsJSON, err := json.Marshal(approvals)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(approvalsJSON)
+}
+
+// ApproveTransfer records the approval of a co-owner, given at the revision
+// of If-Match
+type ApproveTransfer struct {
+	Store ApprovalStore
+}
+
+func (g *ApproveTransfer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	revision, code, err := ifMatch(r)
+	if err != nil {
+		http.Error(w, err.Error(), code)
+		return
+	}
+
+	var req struct {
+		Owner    string `json:"owner"`
+		NewOwner string `json:"newOwner"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if strings.TrimSpace(req.Owner) == "" || strings.TrimSpace(req.NewOwner) == "" {
+		http.Error(w, errors.New("Supply owner and newOwner").Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := g.Store.ApproveTransfer(mux.Vars(r)["id"], req.Owner, req.NewOwner, revision); err != nil {
+		http.Error(w, err.Error(), storeErrorCode(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+}
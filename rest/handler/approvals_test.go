@@ -0,0 +1,106 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/yimialmonte/chaincode-cars/asset"
+)
+
+type testApprovalStore struct {
+	called      int
+	revision    int
+	approvals   []*asset.TransferApproval
+	errResponse error
+}
+
+func (t *testApprovalStore) GetTransferApprovals(carID string) ([]*asset.TransferApproval, error) {
+	t.called++
+	return t.approvals, t.errResponse
+}
+
+func (t *testApprovalStore) ApproveTransfer(carID, owner, newOwner string, revision int) error {
+	t.called++
+	t.revision = revision
+	return t.errResponse
+}
+
+func TestGetTransferApprovals(t *testing.T) {
+	tests := []struct {
+		approvals    []*asset.TransferApproval
+		expectedErr  error
+		expectedCode int
+		expectedRes  string
+	}{
+		{
+			[]*asset.TransferApproval{{CarID: "J1", Owner: "Ana", NewOwner: "Peter", Revision: 2, ApprovedAt: "2021-09-21T15:00:00Z"}},
+			nil,
+			http.StatusOK,
+			`[{"carId":"J1","owner":"Ana","newOwner":"Peter","revision":2,"approvedAt":"2021-09-21T15:00:00Z"}]`,
+		},
+		{
+			nil,
+			fmt.Errorf("%w, ID: J1", ErrCarNotFound),
+			http.StatusNotFound,
+			"car not found, ID: J1\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%v", test), func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/cars/J1/approvals", nil)
+			record := httptest.NewRecorder()
+
+			get := GetTransferApprovals{Store: &testApprovalStore{approvals: test.approvals, errResponse: test.expectedErr}}
+			get.ServeHTTP(record, r)
+
+			assert.Equal(t, test.expectedCode, record.Code)
+			assert.Equal(t, test.expectedRes, record.Body.String())
+		})
+	}
+}
+
+func TestApproveTransfer(t *testing.T) {
+	tests := []struct {
+		body         string
+		ifMatch      string
+		expectedErr  error
+		expectedCode int
+		expectedRes  string
+		expectedCall int
+	}{
+		{`{"owner":"Ana","newOwner":"Peter"}`, `"2"`, nil, http.StatusCreated, "", 1},
+		{`{"owner":"Ana","newOwner":"Peter"}`, `"1"`, fmt.Errorf("%w, car J1 is at revision 2", ErrPreconditionFailed), http.StatusPreconditionFailed, "car was modified, precondition failed, car J1 is at revision 2\n", 1},
+		{`{"owner":"Ana","newOwner":"Peter"}`, "", nil, http.StatusPreconditionRequired, "If-Match header is required\n", 0},
+		{`{"owner":"Ana","newOwner":"Peter"}`, `"2"`, fmt.Errorf("Ana does not hold a share of the car J1"), http.StatusInternalServerError, "Ana does not hold a share of the car J1\n", 1},
+		{`{"owner":"Ana"}`, `"2"`, nil, http.StatusBadRequest, "Supply owner and newOwner\n", 0},
+		{`{"owner":`, `"2"`, nil, http.StatusBadRequest, "unexpected EOF\n", 0},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%v", test), func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/cars/J1/approvals", strings.NewReader(test.body))
+			r = mux.SetURLVars(r, map[string]string{"id": "J1"})
+			if test.ifMatch != "" {
+				r.Header.Set("If-Match", test.ifMatch)
+			}
+			record := httptest.NewRecorder()
+
+			store := &testApprovalStore{errResponse: test.expectedErr}
+			approve := ApproveTransfer{Store: store}
+			approve.ServeHTTP(record, r)
+
+			assert.Equal(t, test.expectedCode, record.Code)
+			assert.Equal(t, test.expectedRes, record.Body.String())
+			assert.Equal(t, test.expectedCall, store.called)
+			if test.expectedCode == http.StatusCreated {
+				assert.Equal(t, 2, store.revision)
+			}
+		})
+	}
+}
@@ -0,0 +1,182 @@
+package handler
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/yimialmonte/chaincode-cars/asset"
+	"github.com/yimialmonte/chaincode-cars/rest/blob"
+)
+
+// maxAttachmentSize is the default limit of an uploaded document
+const maxAttachmentSize = 10 << 20
+
+// AttachmentStore ...
+type AttachmentStore interface {
+	GetCar(id string) (*asset.Car, error)
+	GetAttachments(carID string) ([]*asset.Attachment, error)
+	GetAttachment(carID, id string) (*asset.Attachment, error)
+	AnchorAttachment(attachment asset.Attachment) (*asset.Attachment, error)
+}
+
+// UploadAttachment stores the file form field in the blob store, keyed by
+// its SHA-256, and anchors the hash in the ledger. Nothing is stored for an
+// unknown car.
+type UploadAttachment struct {
+	Store   AttachmentStore
+	Blobs   blob.Store
+	MaxSize int64
+}
+
+func (g *UploadAttachment) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	maxSize := g.MaxSize
+	if maxSize <= 0 {
+		maxSize = maxAttachmentSize
+	}
+
+	// leave room for the multipart headers
+	r.Body = http.MaxBytesReader(w, r.Body, maxSize+64<<10)
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		http.Error(w, "Supply the document in the file form field, "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	if header.Size > maxSize {
+		http.Error(w, fmt.Sprintf("The document is larger than %d bytes", maxSize), http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	carID := mux.Vars(r)["id"]
+	if _, err := g.Store.GetCar(carID); err != nil {
+		http.Error(w, err.Error(), storeErrorCode(err))
+		return
+	}
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	sum := hex.EncodeToString(hash.Sum(nil))
+
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// the blob is stored first, an anchor never points to missing content
+	if err := g.Blobs.Put(sum, file); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	contentType := header.Header.Get("Content-Type")
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	attachment, err := g.Store.AnchorAttachment(asset.Attachment{
+		CarID:       carID,
+		Name:        header.Filename,
+		ContentType: contentType,
+		Size:        header.Size,
+		SHA256:      sum,
+	})
+	if err != nil {
+		http.Error(w, err.Error(), storeErrorCode(err))
+		return
+	}
+
+	attachmentJSON, err := json.Marshal(attachment)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", "/cars/"+carID+"/attachments/"+attachment.ID)
+	w.WriteHeader(http.StatusCreated)
+	w.Write(attachmentJSON)
+}
+
+// GetAttachments ...
+type GetAttachments struct {
+	Store AttachmentStore
+}
+
+func (g *GetAttachments) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	attachments, err := g.Store.GetAttachments(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, err.Error(), storeErrorCode(err))
+		return
+	}
+
+	attachmentsJSON, err := json.Marshal(attachments)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(attachmentsJSON)
+}
+
+// DownloadAttachment serves the document once its content matches the hash
+// anchored in the ledger
+type DownloadAttachment struct {
+	Store AttachmentStore
+	Blobs blob.Store
+}
+
+func (g *DownloadAttachment) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	attachment, err := g.Store.GetAttachment(vars["id"], vars["attachmentId"])
+	if err != nil {
+		http.Error(w, err.Error(), storeErrorCode(err))
+		return
+	}
+
+	content, err := g.Blobs.Get(attachment.SHA256)
+	if errors.Is(err, blob.ErrNotFound) {
+		http.Error(w, "the content of the attachment is missing", http.StatusInternalServerError)
+		return
+	}
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer content.Close()
+
+	// one byte more than anchored is enough to detect a changed blob
+	data, err := ioutil.ReadAll(io.LimitReader(content, attachment.Size+1))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	sum := sha256.Sum256(data)
+	if int64(len(data)) != attachment.Size || hex.EncodeToString(sum[:]) != attachment.SHA256 {
+		http.Error(w, "the attachment failed the integrity check, its content does not match the ledger", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", attachment.ContentType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Name}))
+	w.Header().Set("Digest", "sha-256="+base64.StdEncoding.EncodeToString(sum[:]))
+	io.Copy(w, bytes.NewReader(data))
+}
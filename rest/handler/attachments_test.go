@@ -0,0 +1,220 @@
+package handler
+
+import (
+	"bytes"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yimialmonte/chaincode-cars/asset"
+	"github.com/yimialmonte/chaincode-cars/rest/blob"
+)
+
+// invoiceSHA256 is the SHA-256 of "invoice"
+const invoiceSHA256 = "52d6e3de4fa0dcc29946695f93940c3e7f26f30e1e39f4b1a49ad98839112786"
+
+type testAttachmentStore struct {
+	called      int
+	anchored    *asset.Attachment
+	carErr      error
+	errResponse error
+}
+
+func (t *testAttachmentStore) GetCar(id string) (*asset.Car, error) {
+	if t.carErr != nil {
+		return nil, t.carErr
+	}
+	return &asset.Car{ID: id}, nil
+}
+
+func (t *testAttachmentStore) GetAttachments(carID string) ([]*asset.Attachment, error) {
+	t.called++
+	if t.anchored == nil {
+		return []*asset.Attachment{}, t.errResponse
+	}
+	return []*asset.Attachment{t.anchored}, t.errResponse
+}
+
+func (t *testAttachmentStore) GetAttachment(carID, id string) (*asset.Attachment, error) {
+	t.called++
+	if t.errResponse != nil {
+		return nil, t.errResponse
+	}
+	if t.anchored == nil || t.anchored.ID != id {
+		return nil, fmt.Errorf("%w ID: %s", ErrAttachmentNotFound, id)
+	}
+	return t.anchored, nil
+}
+
+func (t *testAttachmentStore) AnchorAttachment(attachment asset.Attachment) (*asset.Attachment, error) {
+	t.called++
+	if t.errResponse != nil {
+		return nil, t.errResponse
+	}
+
+	attachment.ID = "tx1"
+	attachment.AddedBy = "Org1MSP"
+	t.anchored = &attachment
+	return t.anchored, nil
+}
+
+func uploadRequest(t *testing.T, field, name, content string) *http.Request {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile(field, name)
+	require.NoError(t, err)
+	part.Write([]byte(content))
+	require.NoError(t, writer.Close())
+
+	r := httptest.NewRequest(http.MethodPost, "/cars/12/attachments", &body)
+	r.Header.Set("Content-Type", writer.FormDataContentType())
+	return mux.SetURLVars(r, map[string]string{"id": "12"})
+}
+
+func TestUploadAttachment(t *testing.T) {
+	tests := []struct {
+		field        string
+		content      string
+		carErr       error
+		expectedErr  error
+		expectedCode int
+		expectedRes  string
+		expectedCall int
+		expectedBlob bool
+	}{
+		{
+			"file",
+			"invoice",
+			nil,
+			nil,
+			http.StatusCreated,
+			`{"id":"tx1","carId":"12","name":"invoice.pdf","contentType":"application/octet-stream","size":7,"sha256":"` + invoiceSHA256 + `","addedBy":"Org1MSP","addedAt":""}`,
+			1,
+			true,
+		},
+		{
+			"file",
+			"invoice",
+			fmt.Errorf("%w ID: 12", ErrCarNotFound),
+			nil,
+			http.StatusNotFound,
+			"car not found ID: 12\n",
+			0,
+			false,
+		},
+		{
+			"file",
+			"invoice",
+			nil,
+			fmt.Errorf("%w ID: 12", ErrCarNotFound),
+			http.StatusNotFound,
+			"car not found ID: 12\n",
+			1,
+			true,
+		},
+		{
+			"file",
+			strings.Repeat("x", 32),
+			nil,
+			nil,
+			http.StatusRequestEntityTooLarge,
+			"The document is larger than 16 bytes\n",
+			0,
+			false,
+		},
+		{
+			"document",
+			"invoice",
+			nil,
+			nil,
+			http.StatusBadRequest,
+			"Supply the document in the file form field, http: no such file\n",
+			0,
+			false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%v", test), func(t *testing.T) {
+			blobs, err := blob.NewFileStore(t.TempDir())
+			require.NoError(t, err)
+
+			record := httptest.NewRecorder()
+			store := &testAttachmentStore{carErr: test.carErr, errResponse: test.expectedErr}
+			upload := UploadAttachment{Store: store, Blobs: blobs, MaxSize: 16}
+			upload.ServeHTTP(record, uploadRequest(t, test.field, "invoice.pdf", test.content))
+
+			assert.Equal(t, test.expectedCode, record.Code)
+			assert.Equal(t, test.expectedRes, record.Body.String())
+			assert.Equal(t, test.expectedCall, store.called)
+
+			content, err := blobs.Get(invoiceSHA256)
+			if err == nil {
+				content.Close()
+			}
+			assert.Equal(t, test.expectedBlob, err == nil, "the blob is only stored for a known car")
+			if test.expectedCode == http.StatusCreated {
+				assert.Equal(t, "/cars/12/attachments/tx1", record.Header().Get("Location"))
+			}
+		})
+	}
+}
+
+func TestDownloadAttachment(t *testing.T) {
+	tests := []struct {
+		id           string
+		stored       string
+		expectedCode int
+		expectedRes  string
+	}{
+		{"tx1", "invoice", http.StatusOK, "invoice"},
+		{"tx1", "tampered", http.StatusInternalServerError, "the attachment failed the integrity check, its content does not match the ledger\n"},
+		{"tx1", "", http.StatusInternalServerError, "the content of the attachment is missing\n"},
+		{"tx2", "invoice", http.StatusNotFound, "attachment not found ID: tx2\n"},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%v", test), func(t *testing.T) {
+			blobs, err := blob.NewFileStore(t.TempDir())
+			require.NoError(t, err)
+
+			if test.stored != "" {
+				require.NoError(t, blobs.Put(invoiceSHA256, strings.NewReader(test.stored)))
+			}
+
+			store := &testAttachmentStore{anchored: &asset.Attachment{ID: "tx1", CarID: "12", Name: "invoice.pdf", ContentType: "application/pdf", Size: 7, SHA256: invoiceSHA256}}
+
+			r := httptest.NewRequest(http.MethodGet, "/cars/12/attachments/"+test.id, nil)
+			r = mux.SetURLVars(r, map[string]string{"id": "12", "attachmentId": test.id})
+			record := httptest.NewRecorder()
+
+			download := DownloadAttachment{Store: store, Blobs: blobs}
+			download.ServeHTTP(record, r)
+
+			assert.Equal(t, test.expectedCode, record.Code)
+			assert.Equal(t, test.expectedRes, record.Body.String())
+			if test.expectedCode == http.StatusOK {
+				assert.Equal(t, "application/pdf", record.Header().Get("Content-Type"))
+				assert.Equal(t, `attachment; filename=invoice.pdf`, record.Header().Get("Content-Disposition"))
+				assert.Equal(t, "sha-256=Utbj3k+g3MKZRmlfk5QMPn8m8w4eOfSxpJrZiDkRJ4Y=", record.Header().Get("Digest"))
+			}
+		})
+	}
+}
+
+func TestGetAttachments(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/cars/12/attachments", nil)
+	record := httptest.NewRecorder()
+
+	get := GetAttachments{Store: &testAttachmentStore{anchored: &asset.Attachment{ID: "tx1", CarID: "12", Name: "invoice.pdf", ContentType: "application/pdf", Size: 7, SHA256: invoiceSHA256, AddedBy: "Org1MSP", AddedAt: "2021-09-21T15:00:00Z"}}}
+	get.ServeHTTP(record, r)
+
+	assert.Equal(t, http.StatusOK, record.Code)
+	assert.Equal(t, `[{"id":"tx1","carId":"12","name":"invoice.pdf","contentType":"application/pdf","size":7,"sha256":"`+invoiceSHA256+`","addedBy":"Org1MSP","addedAt":"2021-09-21T15:00:00Z"}]`, record.Body.String())
+}
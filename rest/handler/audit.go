@@ -0,0 +1,170 @@
+package handler
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yimialmonte/chaincode-cars/asset"
+)
+
+// auditPageSize is the default number of audit records per page
+const auditPageSize = 100
+
+// maxAuditPageSize is the largest page the chaincode returns
+const maxAuditPageSize = 1000
+
+// FormatJSON is the audit format returning one page as a JSON document
+const FormatJSON = "json"
+
+// AuditStore ...
+type AuditStore interface {
+	QueryAuditRecords(query asset.AuditQuery, pageSize int, bookmark string) (*asset.AuditPage, error)
+}
+
+// GetAudit searches the audit records, only for the callers with the
+// auditor role. A page is returned as JSON, as CSV every record from the
+// bookmark on is streamed.
+type GetAudit struct {
+	Store    AuditStore
+	Auth     Authorizer
+	PageSize int
+}
+
+func (g *GetAudit) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !hasRole(g.Auth, r, RoleAuditor) {
+		http.Error(w, "the auditor role is required", http.StatusForbidden)
+		return
+	}
+
+	query, pageSize, bookmark, err := g.parseAuditQuery(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	format := auditFormat(r)
+	if format == "" {
+		http.Error(w, "Supply format json or csv", http.StatusNotAcceptable)
+		return
+	}
+
+	page, err := g.Store.QueryAuditRecords(query, pageSize, bookmark)
+	if err != nil {
+		http.Error(w, err.Error(), storeErrorCode(err))
+		return
+	}
+
+	if format == FormatJSON {
+		pageJSON, err := json.Marshal(page)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(pageJSON)
+		return
+	}
+
+	filename := fmt.Sprintf("audit-%s.csv", time.Now().UTC().Format("20060102T150405Z"))
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+
+	writer := csv.NewWriter(w)
+	writer.Write([]string{"txId", "timestamp", "function", "carId", "callerMsp", "caller", "outcome", "args"})
+	for {
+		for _, record := range page.Records {
+			args, _ := json.Marshal(record.Args)
+			writer.Write([]string{record.TxID, record.Timestamp, record.Function, record.CarID, record.CallerMSP, record.Caller, record.Outcome, string(args)})
+		}
+
+		writer.Flush()
+		if writer.Error() != nil || page.Bookmark == "" {
+			// headers are already sent, on errors the client sees a
+			// truncated body
+			return
+		}
+
+		page, err = g.Store.QueryAuditRecords(query, pageSize, page.Bookmark)
+		if err != nil {
+			return
+		}
+	}
+}
+
+// parseAuditQuery accepts only the audit filters and the paging parameters
+func (g *GetAudit) parseAuditQuery(r *http.Request) (asset.AuditQuery, int, string, error) {
+	var query asset.AuditQuery
+	pageSize := g.PageSize
+	if pageSize <= 0 {
+		pageSize = auditPageSize
+	}
+
+	bookmark := ""
+	for name, values := range r.URL.Query() {
+		value := values[0]
+		switch name {
+		case "from":
+			query.From = value
+		case "to":
+			query.To = value
+		case "actor":
+			query.Actor = value
+		case "msp":
+			query.MSP = value
+		case "car":
+			query.CarID = value
+		case "bookmark":
+			bookmark = value
+		case "format":
+		case "pageSize":
+			n, err := strconv.Atoi(value)
+			if err != nil || n <= 0 || n > maxAuditPageSize {
+				return query, 0, "", fmt.Errorf("invalid pageSize %s, use 1 to %d", value, maxAuditPageSize)
+			}
+			pageSize = n
+		default:
+			return query, 0, "", fmt.Errorf("unknown audit parameter %s", name)
+		}
+	}
+
+	return query, pageSize, bookmark, query.Validate()
+}
+
+// auditFormat picks json or csv from the query string, falling back to the
+// Accept header, an empty result means the request can not be satisfied
+func auditFormat(r *http.Request) string {
+	if format := r.URL.Query().Get("format"); format != "" {
+		if format == FormatJSON || format == FormatCSV {
+			return format
+		}
+		return ""
+	}
+
+	accept := r.Header.Get("Accept")
+	if accept == "" {
+		return FormatJSON
+	}
+
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+
+		switch mediaType {
+		case "text/csv":
+			return FormatCSV
+		case "application/json", "*/*":
+			return FormatJSON
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,155 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yimialmonte/chaincode-cars/asset"
+)
+
+type testAuditStore struct {
+	records       []*asset.AuditRecord
+	calls         int
+	queryReceived asset.AuditQuery
+	sizeReceived  int
+	errResponse   error
+}
+
+func (t *testAuditStore) QueryAuditRecords(query asset.AuditQuery, pageSize int, bookmark string) (*asset.AuditPage, error) {
+	t.calls++
+	t.queryReceived = query
+	t.sizeReceived = pageSize
+	if t.errResponse != nil {
+		return nil, t.errResponse
+	}
+
+	start := 0
+	if bookmark != "" {
+		start, _ = strconv.Atoi(bookmark)
+	}
+
+	end := start + pageSize
+	if end >= len(t.records) {
+		return &asset.AuditPage{Records: t.records[start:]}, nil
+	}
+	return &asset.AuditPage{Records: t.records[start:end], Bookmark: strconv.Itoa(end)}, nil
+}
+
+func TestGetAudit(t *testing.T) {
+	auth := TokenAuthorizer{"auditor": {RoleAuditor}, "officer": {RolePrivacyOfficer}}
+	records := []*asset.AuditRecord{
+		{TxID: "tx1", Function: "admin:InitLedger", Args: []string{}, CallerMSP: "Org1MSP", Caller: "a1", Timestamp: "2024-01-01T00:01:00Z", Outcome: "success"},
+		{TxID: "tx2", Function: "transfer:TransferCart", Args: []string{"12", "Max"}, CarID: "12", CallerMSP: "Org1MSP", Caller: "a1", Timestamp: "2024-01-01T00:02:00Z", Outcome: "success"},
+		{TxID: "tx3", Function: "registry:PlaceLien", Args: []string{"12", "5000"}, CarID: "12", CallerMSP: "BankMSP", Caller: "b1", Timestamp: "2024-01-01T00:03:00Z", Outcome: "success"},
+	}
+
+	tests := []struct {
+		url           string
+		token         string
+		accept        string
+		storeErr      error
+		expectedCode  int
+		expectedType  string
+		expectedRes   string
+		expectedQuery asset.AuditQuery
+		expectedSize  int
+		expectedCalls int
+	}{
+		{
+			"/audit?from=2024-01-01T00:00:00Z&to=2024-01-02T00:00:00Z&actor=a1&msp=Org1MSP&car=12&pageSize=2",
+			"auditor", "", nil,
+			http.StatusOK, "application/json",
+			`{"records":[{"docType":"","txId":"tx1","function":"admin:InitLedger","args":[],"callerMsp":"Org1MSP","caller":"a1","timestamp":"2024-01-01T00:01:00Z","outcome":"success"},` +
+				`{"docType":"","txId":"tx2","function":"transfer:TransferCart","args":["12","Max"],"carId":"12","callerMsp":"Org1MSP","caller":"a1","timestamp":"2024-01-01T00:02:00Z","outcome":"success"}],"bookmark":"2"}`,
+			asset.AuditQuery{From: "2024-01-01T00:00:00Z", To: "2024-01-02T00:00:00Z", Actor: "a1", MSP: "Org1MSP", CarID: "12"}, 2, 1,
+		},
+		{
+			"/audit?format=csv&pageSize=2",
+			"auditor", "", nil,
+			http.StatusOK, "text/csv",
+			"txId,timestamp,function,carId,callerMsp,caller,outcome,args\n" +
+				"tx1,2024-01-01T00:01:00Z,admin:InitLedger,,Org1MSP,a1,success,[]\n" +
+				"tx2,2024-01-01T00:02:00Z,transfer:TransferCart,12,Org1MSP,a1,success,\"[\"\"12\"\",\"\"Max\"\"]\"\n" +
+				"tx3,2024-01-01T00:03:00Z,registry:PlaceLien,12,BankMSP,b1,success,\"[\"\"12\"\",\"\"5000\"\"]\"\n",
+			asset.AuditQuery{}, 2, 2,
+		},
+		{
+			"/audit?car=12&bookmark=2",
+			"auditor", "text/csv", nil,
+			http.StatusOK, "text/csv",
+			"txId,timestamp,function,carId,callerMsp,caller,outcome,args\n" +
+				"tx3,2024-01-01T00:03:00Z,registry:PlaceLien,12,BankMSP,b1,success,\"[\"\"12\"\",\"\"5000\"\"]\"\n",
+			asset.AuditQuery{CarID: "12"}, 100, 1,
+		},
+		{"/audit", "officer", "", nil, http.StatusForbidden, "", "the auditor role is required\n", asset.AuditQuery{}, 0, 0},
+		{"/audit", "", "", nil, http.StatusForbidden, "", "the auditor role is required\n", asset.AuditQuery{}, 0, 0},
+		{"/audit?from=yesterday", "auditor", "", nil, http.StatusBadRequest, "", "invalid from yesterday, use RFC 3339\n", asset.AuditQuery{}, 0, 0},
+		{"/audit?pageSize=5000", "auditor", "", nil, http.StatusBadRequest, "", "invalid pageSize 5000, use 1 to 1000\n", asset.AuditQuery{}, 0, 0},
+		{"/audit?function=Mint", "auditor", "", nil, http.StatusBadRequest, "", "unknown audit parameter function\n", asset.AuditQuery{}, 0, 0},
+		{"/audit?format=xml", "auditor", "", nil, http.StatusNotAcceptable, "", "Supply format json or csv\n", asset.AuditQuery{}, 0, 0},
+		{
+			"/audit", "auditor", "", fmt.Errorf("%w, the caller does not have the auditor role", ErrForbidden),
+			http.StatusForbidden, "", "forbidden, the caller does not have the auditor role\n", asset.AuditQuery{}, 100, 1,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.url, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, test.url, nil)
+			r.Header.Set("Authorization", "Bearer "+test.token)
+			if test.accept != "" {
+				r.Header.Set("Accept", test.accept)
+			}
+			record := httptest.NewRecorder()
+
+			store := &testAuditStore{records: records, errResponse: test.storeErr}
+			audit := GetAudit{Store: store, Auth: auth}
+			audit.ServeHTTP(record, r)
+
+			assert.Equal(t, test.expectedCode, record.Code)
+			assert.Equal(t, test.expectedRes, record.Body.String())
+			assert.Equal(t, test.expectedCalls, store.calls)
+			if test.expectedType != "" {
+				assert.Equal(t, test.expectedType, record.Header().Get("Content-Type"))
+			}
+			if test.expectedCalls > 0 {
+				assert.Equal(t, test.expectedQuery, store.queryReceived)
+				assert.Equal(t, test.expectedSize, store.sizeReceived)
+			}
+		})
+	}
+}
+
+func TestGetAuditPages(t *testing.T) {
+	store := &testAuditStore{records: []*asset.AuditRecord{{TxID: "tx1"}, {TxID: "tx2"}, {TxID: "tx3"}}}
+	audit := GetAudit{Store: store, Auth: TokenAuthorizer{"auditor": {RoleAuditor}}, PageSize: 2}
+
+	var txIDs []string
+	bookmark := ""
+	for {
+		r := httptest.NewRequest(http.MethodGet, "/audit?bookmark="+bookmark, nil)
+		r.Header.Set("Authorization", "Bearer auditor")
+		record := httptest.NewRecorder()
+		audit.ServeHTTP(record, r)
+		require.Equal(t, http.StatusOK, record.Code)
+
+		var page asset.AuditPage
+		require.NoError(t, json.Unmarshal(record.Body.Bytes(), &page))
+		for _, r := range page.Records {
+			txIDs = append(txIDs, r.TxID)
+		}
+
+		if page.Bookmark == "" {
+			break
+		}
+		bookmark = page.Bookmark
+	}
+
+	assert.Equal(t, []string{"tx1", "tx2", "tx3"}, txIDs)
+}
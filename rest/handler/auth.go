@@ -0,0 +1,47 @@
+package handler
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+)
+
+// Roles of the API callers
+const (
+	RolePIIReader      = "pii-reader"
+	RolePrivacyOfficer = "privacy-officer"
+	RoleAuditor        = "auditor"
+)
+
+// Authorizer tells the roles of the caller of a request
+type Authorizer interface {
+	HasRole(r *http.Request, role string) bool
+}
+
+// TokenAuthorizer grants roles to the bearer tokens of the Authorization
+// header
+type TokenAuthorizer map[string][]string
+
+// HasRole ...
+func (t TokenAuthorizer) HasRole(r *http.Request, role string) bool {
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if token == "" || token == r.Header.Get("Authorization") {
+		return false
+	}
+
+	granted := false
+	for known, roles := range t {
+		if subtle.ConstantTimeCompare([]byte(known), []byte(token)) == 1 {
+			for _, r := range roles {
+				granted = granted || r == role
+			}
+		}
+	}
+
+	return granted
+}
+
+// hasRole is false when there is no authorizer
+func hasRole(auth Authorizer, r *http.Request, role string) bool {
+	return auth != nil && auth.HasRole(r, role)
+}
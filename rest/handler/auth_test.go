@@ -0,0 +1,36 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenAuthorizer(t *testing.T) {
+	auth := TokenAuthorizer{"s3cret": {RolePIIReader}, "officer": {RolePIIReader, RolePrivacyOfficer}}
+
+	tests := []struct {
+		header   string
+		role     string
+		expected bool
+	}{
+		{"Bearer s3cret", RolePIIReader, true},
+		{"Bearer s3cret", RolePrivacyOfficer, false},
+		{"Bearer officer", RolePrivacyOfficer, true},
+		{"Bearer s3cre", RolePIIReader, false},
+		{"s3cret", RolePIIReader, false},
+		{"Bearer ", RolePIIReader, false},
+		{"", RolePIIReader, false},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%v", test), func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/owners/acme", nil)
+			r.Header.Set("Authorization", test.header)
+			assert.Equal(t, test.expected, auth.HasRole(r, test.role))
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package handler
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/json"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/yimialmonte/chaincode-cars/asset"
+	"github.com/yimialmonte/chaincode-cars/certificate"
+)
+
+// CarRecorder ...
+type CarRecorder interface {
+	GetCarRecord(id string) (*asset.CarRecord, error)
+}
+
+// GetCertificate issues a signed ownership certificate of the car, as
+// JSON-LD by default or as PDF when asked with format=pdf or the Accept header
+type GetCertificate struct {
+	Store  CarRecorder
+	Issuer *certificate.Issuer
+}
+
+func (g *GetCertificate) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	pdf, ok := wantsPDF(r)
+	if !ok {
+		http.Error(w, "Supply format jsonld or pdf", http.StatusNotAcceptable)
+		return
+	}
+
+	record, err := g.Store.GetCarRecord(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, err.Error(), storeErrorCode(err))
+		return
+	}
+
+	cert, err := g.Issuer.Issue(record)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if pdf {
+		var doc bytes.Buffer
+		if err := certificate.RenderPDF(&doc, cert); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		filename := "certificate-" + record.Car.ID + ".pdf"
+		w.Header().Set("Content-Type", "application/pdf")
+		w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+		w.Write(doc.Bytes())
+		return
+	}
+
+	certJSON, err := json.Marshal(cert)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/ld+json")
+	w.Write(certJSON)
+}
+
+// wantsPDF tells whether the PDF rendering was asked, the second result is
+// false when no supported format was asked
+func wantsPDF(r *http.Request) (bool, bool) {
+	switch r.URL.Query().Get("format") {
+	case "pdf":
+		return true, true
+	case "jsonld":
+		return false, true
+	case "":
+	default:
+		return false, false
+	}
+
+	accept := r.Header.Get("Accept")
+	if accept == "" {
+		return false, true
+	}
+
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+
+		switch mediaType {
+		case "application/pdf":
+			return true, true
+		case "application/ld+json", "application/json", "*/*":
+			return false, true
+		}
+	}
+
+	return false, false
+}
+
+// GetCertificateKey publishes the public key verifying the certificates
+type GetCertificateKey struct {
+	Issuer *certificate.Issuer
+}
+
+func (g *GetCertificateKey) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	keyPEM, err := certificate.EncodePublicKey(g.Issuer.Key.Public().(ed25519.PublicKey))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-pem-file")
+	w.Write(keyPEM)
+}
@@ -0,0 +1,160 @@
+package handler
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yimialmonte/chaincode-cars/asset"
+	"github.com/yimialmonte/chaincode-cars/certificate"
+)
+
+type testRecorder struct {
+	record      *asset.CarRecord
+	errResponse error
+}
+
+func (t *testRecorder) GetCarRecord(id string) (*asset.CarRecord, error) {
+	return t.record, t.errResponse
+}
+
+func TestGetCertificate(t *testing.T) {
+	issuer := certificate.NewIssuer("urn:test", ed25519.NewKeyFromSeed(bytes.Repeat([]byte{1}, ed25519.SeedSize)))
+	carRecord := &asset.CarRecord{Car: &asset.Car{ID: "12", Brand: "Toyota", Owner: "Juan"}, TxID: "abc", BlockNumber: 4}
+
+	tests := []struct {
+		url          string
+		accept       string
+		storeErr     error
+		expectedCode int
+		expectedType string
+	}{
+		{"/cars/12/certificate", "", nil, http.StatusOK, "application/ld+json"},
+		{"/cars/12/certificate?format=pdf", "", nil, http.StatusOK, "application/pdf"},
+		{"/cars/12/certificate", "application/pdf", nil, http.StatusOK, "application/pdf"},
+		{"/cars/12/certificate?format=docx", "", nil, http.StatusNotAcceptable, "text/plain; charset=utf-8"},
+		{"/cars/12/certificate", "", fmt.Errorf("%w, ID: 12", ErrCarNotFound), http.StatusNotFound, "text/plain; charset=utf-8"},
+		{"/cars/12/certificate", "", fmt.Errorf("%w, no ledger records", ErrNotSupported), http.StatusNotImplemented, "text/plain; charset=utf-8"},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%v", test), func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, test.url, nil)
+			r = mux.SetURLVars(r, map[string]string{"id": "12"})
+			if test.accept != "" {
+				r.Header.Set("Accept", test.accept)
+			}
+			record := httptest.NewRecorder()
+
+			get := GetCertificate{Store: &testRecorder{record: carRecord, errResponse: test.storeErr}, Issuer: issuer}
+			get.ServeHTTP(record, r)
+
+			assert.Equal(t, test.expectedCode, record.Code)
+			assert.Equal(t, test.expectedType, record.Header().Get("Content-Type"))
+
+			switch test.expectedType {
+			case "application/pdf":
+				assert.True(t, strings.HasPrefix(record.Body.String(), "%PDF-"))
+				assert.Equal(t, "attachment; filename=certificate-12.pdf", record.Header().Get("Content-Disposition"))
+			case "application/ld+json":
+				var cert certificate.Certificate
+				require.NoError(t, json.Unmarshal(record.Body.Bytes(), &cert))
+				assert.Equal(t, "abc", cert.Ledger.TxID)
+				assert.Equal(t, uint64(4), cert.Ledger.BlockNumber)
+				assert.NotEmpty(t, cert.Proof.ProofValue)
+			}
+		})
+	}
+}
+
+func TestGetCertificateKey(t *testing.T) {
+	issuer := certificate.NewIssuer("urn:test", ed25519.NewKeyFromSeed(bytes.Repeat([]byte{1}, ed25519.SeedSize)))
+
+	record := httptest.NewRecorder()
+	get := GetCertificateKey{Issuer: issuer}
+	get.ServeHTTP(record, httptest.NewRequest(http.MethodGet, "/certificates/key", nil))
+
+	assert.Equal(t, http.StatusOK, record.Code)
+	assert.True(t, strings.HasPrefix(record.Body.String(), "-----BEGIN PUBLIC KEY-----"))
+}
+
+func TestVerifyCertificate(t *testing.T) {
+	issuer := certificate.NewIssuer("urn:test", ed25519.NewKeyFromSeed(bytes.Repeat([]byte{1}, ed25519.SeedSize)))
+	cert, err := issuer.Issue(&asset.CarRecord{Car: &asset.Car{ID: "12", Brand: "Toyota", Owner: "Juan", TransfersCount: 1}, TxID: "abc"})
+	require.NoError(t, err)
+	certJSON, err := json.Marshal(cert)
+	require.NoError(t, err)
+
+	tests := []struct {
+		body         string
+		car          *asset.Car
+		storeErr     error
+		expectedCode int
+		expectedRes  string
+	}{
+		{
+			string(certJSON),
+			&asset.Car{ID: "12", Brand: "Toyota", Owner: "Juan", TransfersCount: 1},
+			nil,
+			http.StatusOK,
+			`{"valid":true,"carId":"12","current":true,"ownerMatches":true,"transfersCountMatches":true}`,
+		},
+		{
+			string(certJSON),
+			&asset.Car{ID: "12", Brand: "Toyota", Owner: "Max", TransfersCount: 2},
+			nil,
+			http.StatusOK,
+			`{"valid":true,"carId":"12","current":false,"ownerMatches":false,"transfersCountMatches":false}`,
+		},
+		{
+			string(certJSON),
+			nil,
+			fmt.Errorf("%w, ID: 12", ErrCarNotFound),
+			http.StatusOK,
+			`{"valid":true,"carId":"12","current":false,"ownerMatches":false,"transfersCountMatches":false}`,
+		},
+		{
+			strings.Replace(string(certJSON), `"owner":"Juan"`, `"owner":"Max"`, 1),
+			nil,
+			nil,
+			http.StatusOK,
+			`{"valid":false,"error":"invalid certificate signature","current":false,"ownerMatches":false,"transfersCountMatches":false}`,
+		},
+		{
+			string(certJSON),
+			nil,
+			fmt.Errorf("internal server error"),
+			http.StatusInternalServerError,
+			"internal server error\n",
+		},
+		{
+			`{"id":`,
+			nil,
+			nil,
+			http.StatusBadRequest,
+			"malformed certificate, unexpected end of JSON input\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%v", test.expectedRes), func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/verify", strings.NewReader(test.body))
+			record := httptest.NewRecorder()
+
+			store := &testCartStore{carResponse: test.car, errResponse: test.storeErr}
+			verify := VerifyCertificate{Store: store, Key: issuer.Key.Public().(ed25519.PublicKey)}
+			verify.ServeHTTP(record, r)
+
+			assert.Equal(t, test.expectedCode, record.Code)
+			assert.Equal(t, test.expectedRes, record.Body.String())
+		})
+	}
+}
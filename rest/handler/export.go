@@ -0,0 +1,161 @@
+package handler
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yimialmonte/chaincode-cars/asset"
+)
+
+// exportPageSize is the number of cars fetched from the ledger per query
+const exportPageSize = 100
+
+// Export formats
+const (
+	FormatCSV    = "csv"
+	FormatNDJSON = "ndjson"
+)
+
+var exportContentTypes = map[string]string{
+	FormatCSV:    "text/csv",
+	FormatNDJSON: "application/x-ndjson",
+}
+
+// CarPager ...
+type CarPager interface {
+	GetCarsPage(pageSize int, bookmark string) (*asset.CarsPage, error)
+}
+
+// ExportCars streams the whole registry page by page. Once the headers are
+// sent a failure aborts the connection, so the client does not take a
+// truncated export for a complete one.
+type ExportCars struct {
+	Store    CarPager
+	PageSize int
+}
+
+func (g *ExportCars) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	format := exportFormat(r)
+	if format == "" {
+		http.Error(w, "Supply format csv or ndjson", http.StatusNotAcceptable)
+		return
+	}
+
+	size := g.PageSize
+	if size <= 0 {
+		size = exportPageSize
+	}
+
+	page, err := g.Store.GetCarsPage(size, "")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	filename := fmt.Sprintf("cars-%s.%s", time.Now().UTC().Format("20060102T150405Z"), format)
+	w.Header().Set("Content-Type", exportContentTypes[format])
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+
+	write := WriteCars(w, format)
+
+	flusher, _ := w.(http.Flusher)
+	for {
+		if err := write(page.Cars); err != nil {
+			panic(http.ErrAbortHandler)
+		}
+
+		if flusher != nil {
+			flusher.Flush()
+		}
+
+		if page.Bookmark == "" {
+			return
+		}
+
+		page, err = g.Store.GetCarsPage(size, page.Bookmark)
+		if err != nil {
+			panic(http.ErrAbortHandler)
+		}
+	}
+}
+
+// exportFormat picks the format from the query string, falling back to the
+// Accept header, an empty result means the request can not be satisfied
+func exportFormat(r *http.Request) string {
+	if format := r.URL.Query().Get("format"); format != "" {
+		if _, ok := exportContentTypes[format]; ok {
+			return format
+		}
+		return ""
+	}
+
+	accept := r.Header.Get("Accept")
+	if accept == "" {
+		return FormatNDJSON
+	}
+
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+
+		switch mediaType {
+		case "text/csv":
+			return FormatCSV
+		case "application/x-ndjson", "application/ndjson", "*/*":
+			return FormatNDJSON
+		}
+	}
+
+	return ""
+}
+
+// WriteCars returns a function writing pages of cars in the export format,
+// csv or ndjson, the CSV header is written before the first page
+func WriteCars(w io.Writer, format string) func(cars []*asset.Car) error {
+	if format == FormatCSV {
+		return writeCSV(w)
+	}
+	return writeNDJSON(w)
+}
+
+func writeNDJSON(w io.Writer) func(cars []*asset.Car) error {
+	encoder := json.NewEncoder(w)
+	return func(cars []*asset.Car) error {
+		for _, car := range cars {
+			if err := encoder.Encode(car); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+func writeCSV(w io.Writer) func(cars []*asset.Car) error {
+	writer := csv.NewWriter(w)
+	header := false
+	return func(cars []*asset.Car) error {
+		if !header {
+			header = true
+			writer.Write([]string{"id", "brand", "owner", "year", "transfersCount", "revision", "type", "engineCC", "axles", "payloadKg"})
+		}
+
+		for _, car := range cars {
+			writer.Write([]string{
+				car.ID, car.Brand, car.Owner, strconv.Itoa(car.Year), strconv.Itoa(car.TransfersCount), strconv.Itoa(car.Revision),
+				car.VehicleType(), strconv.Itoa(car.EngineCC), strconv.Itoa(car.Axles), strconv.Itoa(car.PayloadKg),
+			})
+		}
+
+		writer.Flush()
+		return writer.Error()
+	}
+}
@@ -0,0 +1,149 @@
+package handler
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yimialmonte/chaincode-cars/asset"
+)
+
+type testPager struct {
+	cars        []*asset.Car
+	calls       int
+	errResponse error
+	failFrom    int
+}
+
+func (t *testPager) GetCarsPage(pageSize int, bookmark string) (*asset.CarsPage, error) {
+	t.calls++
+	if t.errResponse != nil && t.calls >= t.failFrom {
+		return nil, t.errResponse
+	}
+
+	start := 0
+	if bookmark != "" {
+		start, _ = strconv.Atoi(bookmark)
+	}
+
+	end := start + pageSize
+	if end >= len(t.cars) {
+		return &asset.CarsPage{Cars: t.cars[start:]}, nil
+	}
+	return &asset.CarsPage{Cars: t.cars[start:end], Bookmark: strconv.Itoa(end)}, nil
+}
+
+func TestExportCars(t *testing.T) {
+	cars := []*asset.Car{
+		{ID: "1", Brand: "Toyota", Owner: "Juan"},
+		{ID: "2", Brand: "Honda", Owner: "Max", TransfersCount: 1, Revision: 1},
+		{ID: "3", Brand: "Kia", Owner: "Ana"},
+	}
+
+	tests := []struct {
+		url          string
+		accept       string
+		storeErr     error
+		expectedCode int
+		expectedType string
+		expectedRes  string
+		expectedCall int
+	}{
+		{
+			"/cars/export?format=csv",
+			"",
+			nil,
+			http.StatusOK,
+			"text/csv",
+			"id,brand,owner,year,transfersCount,revision,type,engineCC,axles,payloadKg\n1,Toyota,Juan,0,0,0,car,0,0,0\n2,Honda,Max,0,1,1,car,0,0,0\n3,Kia,Ana,0,0,0,car,0,0,0\n",
+			2,
+		},
+		{
+			"/cars/export",
+			"application/x-ndjson",
+			nil,
+			http.StatusOK,
+			"application/x-ndjson",
+			`{"id":"1","brand":"Toyota","owner":"Juan","transfersCount":0}` + "\n" +
+				`{"id":"2","brand":"Honda","owner":"Max","transfersCount":1,"revision":1}` + "\n" +
+				`{"id":"3","brand":"Kia","owner":"Ana","transfersCount":0}` + "\n",
+			2,
+		},
+		{
+			"/cars/export",
+			"application/xml, text/csv;q=0.9",
+			nil,
+			http.StatusOK,
+			"text/csv",
+			"id,brand,owner,year,transfersCount,revision,type,engineCC,axles,payloadKg\n1,Toyota,Juan,0,0,0,car,0,0,0\n2,Honda,Max,0,1,1,car,0,0,0\n3,Kia,Ana,0,0,0,car,0,0,0\n",
+			2,
+		},
+		{
+			"/cars/export?format=parquet",
+			"",
+			nil,
+			http.StatusNotAcceptable,
+			"text/plain; charset=utf-8",
+			"Supply format csv or ndjson\n",
+			0,
+		},
+		{
+			"/cars/export?format=csv",
+			"",
+			fmt.Errorf("internal server error"),
+			http.StatusInternalServerError,
+			"text/plain; charset=utf-8",
+			"internal server error\n",
+			1,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%v", test), func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, test.url, nil)
+			if test.accept != "" {
+				r.Header.Set("Accept", test.accept)
+			}
+			record := httptest.NewRecorder()
+
+			store := &testPager{cars: cars, errResponse: test.storeErr}
+			export := ExportCars{Store: store, PageSize: 2}
+			export.ServeHTTP(record, r)
+
+			assert.Equal(t, test.expectedCode, record.Code)
+			assert.Equal(t, test.expectedType, record.Header().Get("Content-Type"))
+			assert.Equal(t, test.expectedRes, record.Body.String())
+			assert.Equal(t, test.expectedCall, store.calls)
+			if test.expectedCode == http.StatusOK {
+				assert.Regexp(t, `^attachment; filename=cars-\d{8}T\d{6}Z\.(csv|ndjson)$`, record.Header().Get("Content-Disposition"))
+			}
+		})
+	}
+}
+
+func TestExportCarsAborted(t *testing.T) {
+	cars := []*asset.Car{
+		{ID: "1", Brand: "Toyota", Owner: "Juan"},
+		{ID: "2", Brand: "Honda", Owner: "Max"},
+		{ID: "3", Brand: "Kia", Owner: "Ana"},
+	}
+	store := &testPager{cars: cars, errResponse: fmt.Errorf("ledger unavailable"), failFrom: 2}
+	server := httptest.NewServer(&ExportCars{Store: store, PageSize: 2})
+	defer server.Close()
+
+	res, err := http.Get(server.URL + "/cars/export?format=ndjson")
+	require.NoError(t, err)
+	defer res.Body.Close()
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+
+	body, err := ioutil.ReadAll(res.Body)
+	assert.Error(t, err, "a failed page aborts the stream")
+	assert.Contains(t, string(body), `"id":"1"`)
+	assert.NotContains(t, string(body), `"id":"3"`)
+	assert.Equal(t, 2, store.calls)
+}
@@ -4,17 +4,102 @@ import (
 	"encoding/json"
 	"errors"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/gorilla/mux"
 	"github.com/yimialmonte/chaincode-cars/asset"
 )
 
+// ErrCarNotFound is returned by a CarStore when the car does not exist
+var ErrCarNotFound = errors.New("car not found")
+
+// ErrLienNotFound is returned by a LienStore when the lien does not exist
+var ErrLienNotFound = errors.New("lien not found")
+
+// ErrAttachmentNotFound is returned by an AttachmentStore when the
+// attachment does not exist
+var ErrAttachmentNotFound = errors.New("attachment not found")
+
+// ErrOwnerNotFound is returned by an OwnerStore when the owner does not
+// exist
+var ErrOwnerNotFound = errors.New("owner not found")
+
+// ErrOwnerExists is returned by an OwnerStore when the owner ID is taken
+var ErrOwnerExists = errors.New("owner already exists")
+
+// ErrCarExists is returned by a CarCreator when the car ID is taken
+var ErrCarExists = errors.New("car already exists")
+
+// ErrInvalidCar is returned by a CarCreator when the ledger rejects the car,
+// like an unknown owner
+var ErrInvalidCar = errors.New("invalid car")
+
+// ErrPreconditionFailed is returned by a CarStore when the expected revision
+// of the car does not match the one in the ledger
+var ErrPreconditionFailed = errors.New("car was modified, precondition failed")
+
+// ErrForbidden is returned by a store when the identity of the API is not
+// allowed to submit the transaction
+var ErrForbidden = errors.New("forbidden")
+
+// ErrNotSupported is returned by a store when its backend has no data for
+// the request, like the ledger records of the fabric stub
+var ErrNotSupported = errors.New("not supported by the store")
+
 // CarStore ...
 type CarStore interface {
 	GetCars() ([]*asset.Car, error)
 	GetCarsByOwner(owner string) ([]*asset.Car, error)
-	TransferCart(id, owner string) error
+	GetCar(id string) (*asset.Car, error)
+	TransferCart(id, owner string, revision int) error
+}
+
+// CarCreator registers a car and returns it as stored in the ledger
+type CarCreator interface {
+	CreateCar(car asset.Car) (*asset.Car, error)
+}
+
+// etag returns the entity tag of the car revision
+func etag(car *asset.Car) string {
+	return `"` + strconv.Itoa(car.Revision) + `"`
+}
+
+// ifMatch parses the If-Match header into the expected car revision
+func ifMatch(r *http.Request) (int, int, error) {
+	header := strings.TrimSpace(r.Header.Get("If-Match"))
+	if header == "" {
+		return 0, http.StatusPreconditionRequired, errors.New("If-Match header is required")
+	}
+
+	header = strings.TrimPrefix(header, "W/")
+	revision, err := strconv.Atoi(strings.Trim(header, `"`))
+	if err != nil || revision < 0 {
+		return 0, http.StatusBadRequest, errors.New("invalid If-Match header")
+	}
+
+	return revision, 0, nil
+}
+
+// storeErrorCode maps errors returned by a CarStore to status codes
+func storeErrorCode(err error) int {
+	switch {
+	case errors.Is(err, ErrCarNotFound), errors.Is(err, ErrLienNotFound), errors.Is(err, ErrAttachmentNotFound),
+		errors.Is(err, ErrOwnerNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, ErrOwnerExists), errors.Is(err, ErrCarExists):
+		return http.StatusConflict
+	case errors.Is(err, ErrInvalidCar):
+		return http.StatusBadRequest
+	case errors.Is(err, ErrPreconditionFailed):
+		return http.StatusPreconditionFailed
+	case errors.Is(err, ErrForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, ErrNotSupported):
+		return http.StatusNotImplemented
+	default:
+		return http.StatusInternalServerError
+	}
 }
 
 // GetAllCars
@@ -45,8 +130,7 @@ type GetCarsOwner struct {
 }
 
 func (g *GetCarsOwner) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	name := mux.Vars(r)["name"]
-	cars, err := g.Store.GetCarsByOwner(name)
+	cars, err := g.Store.GetCarsByOwner(mux.Vars(r)["id"])
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -62,16 +146,85 @@ func (g *GetCarsOwner) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	w.Write(carsJSON)
 }
 
+// GetCar ...
+type GetCar struct {
+	Store CarStore
+}
+
+func (g *GetCar) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	car, err := g.Store.GetCar(id)
+	if err != nil {
+		http.Error(w, err.Error(), storeErrorCode(err))
+		return
+	}
+
+	carJSON, err := json.Marshal(car)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", etag(car))
+	w.Write(carJSON)
+}
+
+// CreateCar registers the car of the path, it is rejected with a conflict
+// when the ID is taken
+type CreateCar struct {
+	Store CarCreator
+}
+
+func (g *CreateCar) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var car asset.Car
+	if err := json.NewDecoder(r.Body).Decode(&car); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	id := mux.Vars(r)["id"]
+	if car.ID != "" && car.ID != id {
+		http.Error(w, "the car ID does not match the path", http.StatusBadRequest)
+		return
+	}
+	car.ID = id
+
+	created, err := g.Store.CreateCar(car)
+	if err != nil {
+		http.Error(w, err.Error(), storeErrorCode(err))
+		return
+	}
+
+	carJSON, err := json.Marshal(created)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", etag(created))
+	w.Header().Set("Location", "/cars/"+id)
+	w.WriteHeader(http.StatusCreated)
+	w.Write(carJSON)
+}
+
 // TransferCarOwner ...
 type TransferCarOwner struct {
 	Store CarStore
 }
 
 func (g *TransferCarOwner) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	revision, code, err := ifMatch(r)
+	if err != nil {
+		http.Error(w, err.Error(), code)
+		return
+	}
+
 	var car asset.Car
 	decoder := json.NewDecoder(r.Body)
 
-	err := decoder.Decode(&car)
+	err = decoder.Decode(&car)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
@@ -82,9 +235,9 @@ func (g *TransferCarOwner) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = g.Store.TransferCart(car.ID, car.Owner)
+	err = g.Store.TransferCart(car.ID, car.Owner, revision)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), storeErrorCode(err))
 		return
 	}
 
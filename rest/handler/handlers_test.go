@@ -7,6 +7,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/gorilla/mux"
 	"github.com/stretchr/testify/assert"
 	"github.com/yimialmonte/chaincode-cars/asset"
 )
@@ -14,7 +15,10 @@ import (
 type testCartStore struct {
 	called       int
 	carsResponse []*asset.Car
+	carResponse  *asset.Car
 	errResponse  error
+	revision     int
+	owner        string
 }
 
 func (t *testCartStore) GetCars() ([]*asset.Car, error) {
@@ -24,13 +28,21 @@ func (t *testCartStore) GetCars() ([]*asset.Car, error) {
 
 func (t *testCartStore) GetCarsByOwner(owner string) ([]*asset.Car, error) {
 	t.called++
+	t.owner = owner
 	return t.carsResponse, t.errResponse
 }
 
-func (t *testCartStore) TransferCart(id, owner string) error {
+func (t *testCartStore) GetCar(id string) (*asset.Car, error) {
 	t.called++
+	return t.carResponse, t.errResponse
+}
+
+func (t *testCartStore) TransferCart(id, owner string, revision int) error {
+	t.called++
+	t.revision = revision
 	return t.errResponse
 }
+
 func TestGetAllCars(t *testing.T) {
 	tests := []struct {
 		response     []*asset.Car
@@ -109,6 +121,7 @@ func TestGetCarsOwner(t *testing.T) {
 	for _, test := range tests {
 		t.Run(fmt.Sprintf("%v", test), func(t *testing.T) {
 			r := httptest.NewRequest(http.MethodGet, "/cars/owner/juan", nil)
+			r = mux.SetURLVars(r, map[string]string{"id": "juan"})
 			record := httptest.NewRecorder()
 
 			store := &testCartStore{carsResponse: test.response, errResponse: test.expectedErr}
@@ -118,47 +131,142 @@ func TestGetCarsOwner(t *testing.T) {
 
 			assert.Equal(t, test.expectedCode, record.Code)
 			assert.Equal(t, store.called, 1)
+			assert.Equal(t, "juan", store.owner)
 			assert.Equal(t, test.expectedRes, record.Body.String())
 		})
 	}
 }
 
-func TestTransferCarOwner(t *testing.T) {
+func TestGetCar(t *testing.T) {
 	tests := []struct {
-		requestBody  string
+		response     *asset.Car
 		expectedErr  error
 		expectedCode int
-		respond      string
+		expectedRes  string
+		expectedTag  string
+	}{
+		{
+			&asset.Car{ID: "000", Brand: "Toyota", Owner: "Max", TransfersCount: 1, Revision: 2},
+			nil,
+			http.StatusOK,
+			`{"id":"000","brand":"Toyota","owner":"Max","transfersCount":1,"revision":2}`,
+			`"2"`,
+		},
+		{
+			nil,
+			fmt.Errorf("%w, ID: 000", ErrCarNotFound),
+			http.StatusNotFound,
+			"car not found, ID: 000\n",
+			"",
+		},
+		{
+			nil,
+			fmt.Errorf("internal server error"),
+			http.StatusInternalServerError,
+			"internal server error\n",
+			"",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%v", test), func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/cars/000", nil)
+			record := httptest.NewRecorder()
+
+			store := &testCartStore{carResponse: test.response, errResponse: test.expectedErr}
+			car := GetCar{Store: store}
+
+			car.ServeHTTP(record, r)
+
+			assert.Equal(t, test.expectedCode, record.Code)
+			assert.Equal(t, store.called, 1)
+			assert.Equal(t, test.expectedRes, record.Body.String())
+			assert.Equal(t, test.expectedTag, record.Header().Get("ETag"))
+		})
+	}
+}
+
+func TestTransferCarOwner(t *testing.T) {
+	tests := []struct {
+		requestBody      string
+		ifMatch          string
+		expectedErr      error
+		expectedCode     int
+		respond          string
+		expectedRevision int
 	}{
 		{
 			`{"id":"000","owner":"Max"}`,
+			`"3"`,
 			nil,
 			http.StatusCreated,
 			"",
+			3,
 		},
 		{
 			`{"id":"000","owner":"Max"}`,
+			`W/"1"`,
+			nil,
+			http.StatusCreated,
+			"",
+			1,
+		},
+		{
+			`{"id":"000","owner":"Max"}`,
+			`"0"`,
 			fmt.Errorf("internal server error"),
 			http.StatusInternalServerError,
 			fmt.Sprintf("internal server error\n"),
+			0,
+		},
+		{
+			`{"id":"000","owner":"Max"}`,
+			`"0"`,
+			ErrPreconditionFailed,
+			http.StatusPreconditionFailed,
+			"car was modified, precondition failed\n",
+			0,
+		},
+		{
+			`{"id":"000","owner":"Max"}`,
+			"",
+			nil,
+			http.StatusPreconditionRequired,
+			"If-Match header is required\n",
+			0,
+		},
+		{
+			`{"id":"000","owner":"Max"}`,
+			`"abc"`,
+			nil,
+			http.StatusBadRequest,
+			"invalid If-Match header\n",
+			0,
 		},
 		{
 			`{"id":"","owner":""}`,
+			`"0"`,
 			nil,
 			http.StatusBadRequest,
 			fmt.Sprintf("Supply Car ID and Owner\n"),
+			0,
 		},
 		{
 			`{"id":"","owner"}`,
+			`"0"`,
 			nil,
 			http.StatusBadRequest,
 			fmt.Sprintf("invalid character '}' after object key\n"),
+			0,
 		},
 	}
 
 	for _, test := range tests {
 		t.Run(fmt.Sprintf("%v", test), func(t *testing.T) {
 			r := httptest.NewRequest(http.MethodPost, "/cars", strings.NewReader(test.requestBody))
+			if test.ifMatch != "" {
+				r.Header.Set("If-Match", test.ifMatch)
+			}
 			record := httptest.NewRecorder()
 
 			store := &testCartStore{errResponse: test.expectedErr}
@@ -168,6 +276,64 @@ func TestTransferCarOwner(t *testing.T) {
 
 			assert.Equal(t, test.expectedCode, record.Code)
 			assert.Equal(t, test.respond, record.Body.String())
+			assert.Equal(t, test.expectedRevision, store.revision)
+		})
+	}
+}
+
+type testCarCreator struct {
+	called      int
+	received    asset.Car
+	errResponse error
+}
+
+func (t *testCarCreator) CreateCar(car asset.Car) (*asset.Car, error) {
+	t.called++
+	t.received = car
+	if t.errResponse != nil {
+		return nil, t.errResponse
+	}
+
+	car.Revision = 2
+	car.TransfersCount = 1
+	return &car, nil
+}
+
+func TestCreateCar(t *testing.T) {
+	tests := []struct {
+		body         string
+		storeErr     error
+		expectedCode int
+		expectedRes  string
+		expectedCall int
+	}{
+		{`{"brand":"Kia","owner":"Ana","year":2018}`, nil, http.StatusCreated, `{"id":"31","brand":"Kia","owner":"Ana","transfersCount":1,"year":2018,"revision":2}`, 1},
+		{`{"id":"31","brand":"Kia","owner":"Ana","revision":7}`, nil, http.StatusCreated, `{"id":"31","brand":"Kia","owner":"Ana","transfersCount":1,"revision":2}`, 1},
+		{`{"id":"32","brand":"Kia","owner":"Ana"}`, nil, http.StatusBadRequest, "the car ID does not match the path\n", 0},
+		{`{"brand":`, nil, http.StatusBadRequest, "unexpected EOF\n", 0},
+		{`{"brand":"Kia","owner":"Ana"}`, fmt.Errorf("%w, ID: 31", ErrCarExists), http.StatusConflict, "car already exists, ID: 31\n", 1},
+		{`{"brand":"Kia","owner":"Nobody"}`, fmt.Errorf("%w, owner does not exist ID: Nobody", ErrInvalidCar), http.StatusBadRequest, "invalid car, owner does not exist ID: Nobody\n", 1},
+		{`{"brand":"Kia","owner":"Ana"}`, fmt.Errorf("%w, organization Org2MSP is not allowed", ErrForbidden), http.StatusForbidden, "forbidden, organization Org2MSP is not allowed\n", 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.body, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPut, "/cars/31", strings.NewReader(test.body))
+			r = mux.SetURLVars(r, map[string]string{"id": "31"})
+			record := httptest.NewRecorder()
+
+			store := &testCarCreator{errResponse: test.storeErr}
+			create := CreateCar{Store: store}
+			create.ServeHTTP(record, r)
+
+			assert.Equal(t, test.expectedCode, record.Code)
+			assert.Equal(t, test.expectedRes, record.Body.String())
+			assert.Equal(t, test.expectedCall, store.called)
+			if record.Code == http.StatusCreated {
+				assert.Equal(t, "31", store.received.ID)
+				assert.Equal(t, "/cars/31", record.Header().Get("Location"))
+				assert.Equal(t, `"2"`, record.Header().Get("ETag"), "the tag of the stored car")
+			}
 		})
 	}
 }
@@ -0,0 +1,40 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/yimialmonte/chaincode-cars/asset"
+)
+
+// CarHistorian ...
+type CarHistorian interface {
+	GetCarHistory(id string) ([]*asset.CarRecord, error)
+}
+
+// GetCarHistory returns every version of the car, newest first
+type GetCarHistory struct {
+	Store CarHistorian
+}
+
+func (g *GetCarHistory) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	records, err := g.Store.GetCarHistory(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, err.Error(), storeErrorCode(err))
+		return
+	}
+
+	if records == nil {
+		records = []*asset.CarRecord{}
+	}
+
+	recordsJSON, err := json.Marshal(records)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(recordsJSON)
+}
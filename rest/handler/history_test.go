@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/yimialmonte/chaincode-cars/asset"
+)
+
+type testHistorian struct {
+	records     []*asset.CarRecord
+	idReceived  string
+	errResponse error
+}
+
+func (t *testHistorian) GetCarHistory(id string) ([]*asset.CarRecord, error) {
+	t.idReceived = id
+	return t.records, t.errResponse
+}
+
+func TestGetCarHistory(t *testing.T) {
+	tests := []struct {
+		records      []*asset.CarRecord
+		storeErr     error
+		expectedCode int
+		expectedRes  string
+	}{
+		{
+			[]*asset.CarRecord{
+				{Car: &asset.Car{ID: "12", Brand: "Toyota", Owner: "Max", TransfersCount: 1, Revision: 1}, TxID: "tx2", Timestamp: "2021-09-21T16:00:00Z", BlockNumber: 2},
+				{Car: &asset.Car{ID: "12", Brand: "Toyota", Owner: "Juan"}, TxID: "tx1", Timestamp: "2021-09-21T15:00:00Z", BlockNumber: 1},
+			},
+			nil,
+			http.StatusOK,
+			`[{"car":{"id":"12","brand":"Toyota","owner":"Max","transfersCount":1,"revision":1},"txId":"tx2","timestamp":"2021-09-21T16:00:00Z","blockNumber":2},` +
+				`{"car":{"id":"12","brand":"Toyota","owner":"Juan","transfersCount":0},"txId":"tx1","timestamp":"2021-09-21T15:00:00Z","blockNumber":1}]`,
+		},
+		{nil, nil, http.StatusOK, "[]"},
+		{nil, fmt.Errorf("%w, ID: 12", ErrCarNotFound), http.StatusNotFound, "car not found, ID: 12\n"},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%v", test.expectedRes), func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/cars/12/history", nil)
+			r = mux.SetURLVars(r, map[string]string{"id": "12"})
+			record := httptest.NewRecorder()
+
+			store := &testHistorian{records: test.records, errResponse: test.storeErr}
+			history := GetCarHistory{Store: store}
+			history.ServeHTTP(record, r)
+
+			assert.Equal(t, test.expectedCode, record.Code)
+			assert.Equal(t, test.expectedRes, record.Body.String())
+			assert.Equal(t, "12", store.idReceived)
+		})
+	}
+}
@@ -0,0 +1,394 @@
+package handler
+
+import (
+	"crypto/rand"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/yimialmonte/chaincode-cars/asset"
+)
+
+// defaultBatchSize is the number of cars sent to the ledger per transaction
+const defaultBatchSize = 50
+
+// defaultMaxImportSize caps the import bodies, 10 MiB
+const defaultMaxImportSize = 10 << 20
+
+// Defaults of the eviction of the finished import jobs
+const (
+	defaultJobTTL          = time.Hour
+	defaultMaxFinishedJobs = 1000
+)
+
+// CarImporter ...
+type CarImporter interface {
+	CreateCars(cars []asset.Car) (*asset.BatchReport, error)
+}
+
+// ImportJobs keeps the import jobs in memory. A finished job is evicted
+// TTL after it finished, and the oldest ones once there are more than
+// MaxFinished.
+type ImportJobs struct {
+	TTL         time.Duration
+	MaxFinished int
+
+	mu       sync.RWMutex
+	jobs     map[string]*asset.ImportJob
+	finished map[string]time.Time
+	clock    func() time.Time
+}
+
+// NewImportJobs ...
+func NewImportJobs() *ImportJobs {
+	return &ImportJobs{
+		TTL:         defaultJobTTL,
+		MaxFinished: defaultMaxFinishedJobs,
+		jobs:        map[string]*asset.ImportJob{},
+		finished:    map[string]time.Time{},
+		clock:       time.Now,
+	}
+}
+
+// Get returns a copy of the job
+func (j *ImportJobs) Get(id string) (*asset.ImportJob, bool) {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+
+	job, ok := j.jobs[id]
+	if !ok {
+		return nil, false
+	}
+
+	copied := *job
+	copied.Reports = append([]*asset.BatchReport{}, job.Reports...)
+	return &copied, true
+}
+
+func (j *ImportJobs) add(job *asset.ImportJob) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.evict()
+	j.jobs[job.ID] = job
+}
+
+func (j *ImportJobs) update(id string, fn func(job *asset.ImportJob)) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	fn(j.jobs[id])
+}
+
+// finish updates the job a last time and starts its TTL
+func (j *ImportJobs) finish(id string, fn func(job *asset.ImportJob)) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	fn(j.jobs[id])
+	j.finished[id] = j.clock()
+	j.evict()
+}
+
+// evict removes the expired finished jobs, then the oldest ones above
+// MaxFinished, the running jobs are always kept
+func (j *ImportJobs) evict() {
+	now := j.clock()
+	var ids []string
+	for id, at := range j.finished {
+		if now.Sub(at) > j.TTL {
+			delete(j.jobs, id)
+			delete(j.finished, id)
+			continue
+		}
+		ids = append(ids, id)
+	}
+
+	if len(ids) <= j.MaxFinished {
+		return
+	}
+
+	sort.Slice(ids, func(a, b int) bool { return j.finished[ids[a]].Before(j.finished[ids[b]]) })
+	for _, id := range ids[:len(ids)-j.MaxFinished] {
+		delete(j.jobs, id)
+		delete(j.finished, id)
+	}
+}
+
+// run sends the cars to the store batch by batch, every batch is registered
+// with all-or-nothing semantics
+func (j *ImportJobs) run(id string, store CarImporter, batches [][]asset.Car) {
+	j.update(id, func(job *asset.ImportJob) { job.Status = asset.JobRunning })
+
+	for _, batch := range batches {
+		report, err := store.CreateCars(batch)
+		if err != nil {
+			j.finish(id, func(job *asset.ImportJob) {
+				job.Status = asset.JobFailed
+				job.Error = err.Error()
+			})
+			return
+		}
+
+		j.update(id, func(job *asset.ImportJob) {
+			job.Processed += len(batch)
+			job.Reports = append(job.Reports, report)
+			if report.Created {
+				job.Created += len(batch)
+			} else {
+				job.Rejected += len(batch)
+			}
+		})
+	}
+
+	j.finish(id, func(job *asset.ImportJob) { job.Status = asset.JobCompleted })
+}
+
+// ImportCars registers the cars of the body in the background, the body is
+// capped to MaxSize bytes, defaultMaxImportSize when zero
+type ImportCars struct {
+	Store     CarImporter
+	Jobs      *ImportJobs
+	BatchSize int
+	MaxSize   int64
+}
+
+func (g *ImportCars) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		http.Error(w, "Supply Content-Type text/csv or application/x-ndjson", http.StatusUnsupportedMediaType)
+		return
+	}
+
+	maxSize := g.MaxSize
+	if maxSize <= 0 {
+		maxSize = defaultMaxImportSize
+	}
+
+	body := &limitedReader{r: r.Body, n: maxSize}
+	cars, err := DecodeCars(body, mediaType)
+	if body.exceeded {
+		http.Error(w, fmt.Sprintf("the import is larger than %d bytes", maxSize), http.StatusRequestEntityTooLarge)
+		return
+	}
+	if errors.Is(err, errUnsupportedMediaType) {
+		http.Error(w, "Supply Content-Type text/csv or application/x-ndjson", http.StatusUnsupportedMediaType)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if len(cars) == 0 {
+		http.Error(w, errors.New("Supply at least one car").Error(), http.StatusBadRequest)
+		return
+	}
+
+	size := g.BatchSize
+	if size <= 0 {
+		size = defaultBatchSize
+	}
+
+	var batches [][]asset.Car
+	for start := 0; start < len(cars); start += size {
+		end := start + size
+		if end > len(cars) {
+			end = len(cars)
+		}
+		batches = append(batches, cars[start:end])
+	}
+
+	id, err := newJobID()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	job := &asset.ImportJob{ID: id, Status: asset.JobPending, Total: len(cars), Batches: len(batches)}
+	g.Jobs.add(job)
+	snapshot, _ := g.Jobs.Get(id)
+
+	go g.Jobs.run(id, g.Store, batches)
+
+	jobJSON, err := json.Marshal(snapshot)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", "/cars/imports/"+id)
+	w.WriteHeader(http.StatusAccepted)
+	w.Write(jobJSON)
+}
+
+// GetImportJob ...
+type GetImportJob struct {
+	Jobs *ImportJobs
+}
+
+func (g *GetImportJob) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	job, ok := g.Jobs.Get(mux.Vars(r)["id"])
+	if !ok {
+		http.Error(w, "import job not found", http.StatusNotFound)
+		return
+	}
+
+	jobJSON, err := json.Marshal(job)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(jobJSON)
+}
+
+var errUnsupportedMediaType = errors.New("unsupported media type, use text/csv or application/x-ndjson")
+
+// DecodeCars reads the cars of an import, the media type is text/csv or
+// application/x-ndjson
+func DecodeCars(body io.Reader, mediaType string) ([]asset.Car, error) {
+	switch mediaType {
+	case "text/csv":
+		return decodeCarsCSV(body)
+	case "application/x-ndjson", "application/ndjson":
+		return decodeCarsNDJSON(body)
+	}
+
+	return nil, errUnsupportedMediaType
+}
+
+// decodeCarsCSV reads cars from a CSV with an id,brand,owner header row and
+// the optional year, type, engineCC, axles and payloadKg columns
+func decodeCarsCSV(body io.Reader) ([]asset.Car, error) {
+	reader := csv.NewReader(body)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	for _, name := range []string{"id", "brand", "owner"} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("missing CSV column %s", name)
+		}
+	}
+
+	var cars []asset.Car
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return cars, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		car := asset.Car{
+			ID:    record[columns["id"]],
+			Brand: record[columns["brand"]],
+			Owner: record[columns["owner"]],
+		}
+
+		if i, ok := columns["type"]; ok {
+			car.Type = strings.TrimSpace(record[i])
+		}
+
+		numbers := []struct {
+			name  string
+			value *int
+		}{
+			{"year", &car.Year},
+			{"engineCC", &car.EngineCC},
+			{"axles", &car.Axles},
+			{"payloadKg", &car.PayloadKg},
+		}
+
+		for _, number := range numbers {
+			i, ok := columns[strings.ToLower(number.name)]
+			if !ok || strings.TrimSpace(record[i]) == "" {
+				continue
+			}
+
+			*number.value, err = strconv.Atoi(strings.TrimSpace(record[i]))
+			if err != nil {
+				return nil, fmt.Errorf("line %d, invalid %s %s", len(cars)+2, number.name, record[i])
+			}
+		}
+
+		cars = append(cars, car)
+	}
+}
+
+// decodeCarsNDJSON reads cars from newline delimited JSON
+func decodeCarsNDJSON(body io.Reader) ([]asset.Car, error) {
+	decoder := json.NewDecoder(body)
+
+	var cars []asset.Car
+	for {
+		var car asset.Car
+		err := decoder.Decode(&car)
+		if err == io.EOF {
+			return cars, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d, %v", len(cars)+1, err)
+		}
+
+		cars = append(cars, car)
+	}
+}
+
+// limitedReader reads up to n bytes of r, exceeded is set when r is longer
+type limitedReader struct {
+	r        io.Reader
+	n        int64
+	exceeded bool
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if l.n <= 0 {
+		// one more byte tells a body of exactly n bytes from a longer one
+		if n, _ := io.ReadFull(l.r, make([]byte, 1)); n > 0 {
+			l.exceeded = true
+			return 0, errors.New("the import is too large")
+		}
+		return 0, io.EOF
+	}
+
+	if int64(len(p)) > l.n {
+		p = p[:l.n]
+	}
+
+	n, err := l.r.Read(p)
+	l.n -= int64(n)
+	return n, err
+}
+
+func newJobID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
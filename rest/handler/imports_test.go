@@ -0,0 +1,334 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yimialmonte/chaincode-cars/asset"
+)
+
+type testImporter struct {
+	mu          sync.Mutex
+	batches     [][]asset.Car
+	created     bool
+	errResponse error
+}
+
+func (t *testImporter) CreateCars(cars []asset.Car) (*asset.BatchReport, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.batches = append(t.batches, cars)
+	if t.errResponse != nil {
+		return nil, t.errResponse
+	}
+
+	report := &asset.BatchReport{Created: t.created}
+	for i, car := range cars {
+		report.Items = append(report.Items, &asset.BatchItem{Index: i, ID: car.ID})
+	}
+	return report, nil
+}
+
+func waitJob(t *testing.T, jobs *ImportJobs, id string) *asset.ImportJob {
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		job, ok := jobs.Get(id)
+		require.True(t, ok)
+		if job.Status == asset.JobCompleted || job.Status == asset.JobFailed {
+			return job
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	t.Fatalf("job %s did not finish", id)
+	return nil
+}
+
+func TestImportCars(t *testing.T) {
+	tests := []struct {
+		contentType     string
+		body            string
+		created         bool
+		storeErr        error
+		expectedCode    int
+		expectedRes     string
+		expectedBatches int
+		expectedStatus  string
+		expectedCreated int
+	}{
+		{
+			"text/csv",
+			"id,brand,owner\n1,Toyota,Juan\n2,Honda,Max\n3,Kia,Ana\n",
+			true,
+			nil,
+			http.StatusAccepted,
+			"",
+			2,
+			asset.JobCompleted,
+			3,
+		},
+		{
+			"application/x-ndjson",
+			`{"id":"1","brand":"Toyota","owner":"Juan"}` + "\n" + `{"id":"2","brand":"Honda","owner":"Max"}` + "\n",
+			false,
+			nil,
+			http.StatusAccepted,
+			"",
+			1,
+			asset.JobCompleted,
+			0,
+		},
+		{
+			"text/csv; charset=utf-8",
+			"owner,id,brand\nJuan,1,Toyota\n",
+			true,
+			fmt.Errorf("connection failed"),
+			http.StatusAccepted,
+			"",
+			1,
+			asset.JobFailed,
+			0,
+		},
+		{
+			"text/csv",
+			"id,owner\n1,Juan\n",
+			true,
+			nil,
+			http.StatusBadRequest,
+			"missing CSV column brand\n",
+			0,
+			"",
+			0,
+		},
+		{
+			"application/x-ndjson",
+			`{"id":"1"` + "\n",
+			true,
+			nil,
+			http.StatusBadRequest,
+			"line 1, unexpected EOF\n",
+			0,
+			"",
+			0,
+		},
+		{
+			"text/csv",
+			"id,brand,owner\n",
+			true,
+			nil,
+			http.StatusBadRequest,
+			"Supply at least one car\n",
+			0,
+			"",
+			0,
+		},
+		{
+			"application/json",
+			`[]`,
+			true,
+			nil,
+			http.StatusUnsupportedMediaType,
+			"Supply Content-Type text/csv or application/x-ndjson\n",
+			0,
+			"",
+			0,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%v", test), func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/cars:import", strings.NewReader(test.body))
+			r.Header.Set("Content-Type", test.contentType)
+			record := httptest.NewRecorder()
+
+			store := &testImporter{created: test.created, errResponse: test.storeErr}
+			jobs := NewImportJobs()
+			imp := ImportCars{Store: store, Jobs: jobs, BatchSize: 2}
+
+			imp.ServeHTTP(record, r)
+
+			assert.Equal(t, test.expectedCode, record.Code)
+			if test.expectedCode != http.StatusAccepted {
+				assert.Equal(t, test.expectedRes, record.Body.String())
+				return
+			}
+
+			var accepted asset.ImportJob
+			require.NoError(t, json.Unmarshal(record.Body.Bytes(), &accepted))
+			assert.Equal(t, "/cars/imports/"+accepted.ID, record.Header().Get("Location"))
+
+			job := waitJob(t, jobs, accepted.ID)
+			assert.Equal(t, test.expectedStatus, job.Status)
+			assert.Equal(t, test.expectedCreated, job.Created)
+			assert.Equal(t, test.expectedBatches, len(store.batches))
+		})
+	}
+}
+
+func TestGetImportJob(t *testing.T) {
+	jobs := NewImportJobs()
+	jobs.add(&asset.ImportJob{ID: "abc", Status: asset.JobCompleted, Total: 1, Batches: 1, Processed: 1, Created: 1})
+
+	tests := []struct {
+		id           string
+		expectedCode int
+		expectedRes  string
+	}{
+		{
+			"abc",
+			http.StatusOK,
+			`{"id":"abc","status":"completed","total":1,"batches":1,"processed":1,"created":1,"rejected":0,"reports":[]}`,
+		},
+		{
+			"xyz",
+			http.StatusNotFound,
+			"import job not found\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%v", test), func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/cars/imports/"+test.id, nil)
+			r = mux.SetURLVars(r, map[string]string{"id": test.id})
+			record := httptest.NewRecorder()
+
+			get := GetImportJob{Jobs: jobs}
+			get.ServeHTTP(record, r)
+
+			assert.Equal(t, test.expectedCode, record.Code)
+			assert.Equal(t, test.expectedRes, record.Body.String())
+		})
+	}
+}
+
+func TestImportCarsTooLarge(t *testing.T) {
+	body := "id,brand,owner\n1,Toyota,Juan\n"
+	tests := []struct {
+		maxSize      int64
+		expectedCode int
+	}{
+		{int64(len(body)), http.StatusAccepted},
+		{int64(len(body) - 1), http.StatusRequestEntityTooLarge},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%v", test), func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/cars:import", strings.NewReader(body))
+			r.Header.Set("Content-Type", "text/csv")
+			record := httptest.NewRecorder()
+
+			jobs := NewImportJobs()
+			imp := ImportCars{Store: &testImporter{created: true}, Jobs: jobs, MaxSize: test.maxSize}
+			imp.ServeHTTP(record, r)
+
+			assert.Equal(t, test.expectedCode, record.Code, record.Body.String())
+			if test.expectedCode == http.StatusAccepted {
+				var accepted asset.ImportJob
+				require.NoError(t, json.Unmarshal(record.Body.Bytes(), &accepted))
+				waitJob(t, jobs, accepted.ID)
+			}
+		})
+	}
+}
+
+func TestImportJobsEviction(t *testing.T) {
+	now := time.Unix(0, 0)
+	jobs := NewImportJobs()
+	jobs.MaxFinished = 2
+	jobs.clock = func() time.Time { return now }
+
+	for _, id := range []string{"a", "b", "c"} {
+		jobs.add(&asset.ImportJob{ID: id, Status: asset.JobPending})
+		jobs.finish(id, func(job *asset.ImportJob) { job.Status = asset.JobCompleted })
+		now = now.Add(time.Minute)
+	}
+	jobs.add(&asset.ImportJob{ID: "running", Status: asset.JobRunning})
+
+	_, ok := jobs.Get("a")
+	assert.False(t, ok, "the oldest finished job is evicted above MaxFinished")
+	for _, id := range []string{"b", "c", "running"} {
+		_, ok := jobs.Get(id)
+		assert.True(t, ok, id)
+	}
+
+	now = now.Add(defaultJobTTL)
+	jobs.add(&asset.ImportJob{ID: "d", Status: asset.JobPending})
+
+	for id, expected := range map[string]bool{"b": false, "c": false, "running": true, "d": true} {
+		_, ok := jobs.Get(id)
+		assert.Equal(t, expected, ok, id)
+	}
+}
+
+func TestExportImportRoundTrip(t *testing.T) {
+	truck := &asset.Car{ID: "T1", Brand: "Volvo", Owner: "Cargo SA", Year: 2019, Type: asset.VehicleTruck, Axles: 3, PayloadKg: 18000}
+
+	exported := httptest.NewRecorder()
+	export := ExportCars{Store: &testPager{cars: []*asset.Car{truck}}}
+	export.ServeHTTP(exported, httptest.NewRequest(http.MethodGet, "/cars/export?format=ndjson", nil))
+	require.Equal(t, http.StatusOK, exported.Code)
+
+	r := httptest.NewRequest(http.MethodPost, "/cars:import", exported.Body)
+	r.Header.Set("Content-Type", "application/x-ndjson")
+	record := httptest.NewRecorder()
+
+	store := &testImporter{created: true}
+	jobs := NewImportJobs()
+	imp := ImportCars{Store: store, Jobs: jobs}
+	imp.ServeHTTP(record, r)
+	require.Equal(t, http.StatusAccepted, record.Code, record.Body.String())
+
+	var accepted asset.ImportJob
+	require.NoError(t, json.Unmarshal(record.Body.Bytes(), &accepted))
+	assert.Equal(t, asset.JobCompleted, waitJob(t, jobs, accepted.ID).Status)
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	assert.Equal(t, [][]asset.Car{{*truck}}, store.batches, "the vehicle fields survive the round trip")
+}
+
+func TestDecodeCarsCSV(t *testing.T) {
+	tests := []struct {
+		body         string
+		expectedCars []asset.Car
+		expectedErr  string
+	}{
+		{
+			"id,brand,owner,year,type,engineCC,axles,payloadKg\n1,Toyota,Juan,2015,,,,\nT1,Volvo,Max,,truck,,3,18000\nM1,Ducati,Ana,2020,motorcycle,937,,\n",
+			[]asset.Car{
+				{ID: "1", Brand: "Toyota", Owner: "Juan", Year: 2015},
+				{ID: "T1", Brand: "Volvo", Owner: "Max", Type: "truck", Axles: 3, PayloadKg: 18000},
+				{ID: "M1", Brand: "Ducati", Owner: "Ana", Year: 2020, Type: "motorcycle", EngineCC: 937},
+			},
+			"",
+		},
+		{
+			"id,brand,owner,axles\nT1,Volvo,Max,three\n",
+			nil,
+			"line 2, invalid axles three",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.body, func(t *testing.T) {
+			cars, err := decodeCarsCSV(strings.NewReader(test.body))
+			if test.expectedErr != "" {
+				assert.EqualError(t, err, test.expectedErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, test.expectedCars, cars)
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/yimialmonte/chaincode-cars/asset"
+)
+
+// LienStore ...
+type LienStore interface {
+	GetLiens(carID string) ([]*asset.Lien, error)
+	PlaceLien(carID string, amount int64, revision int) (*asset.Lien, error)
+	ReleaseLien(carID, lienID string, revision int) error
+}
+
+// GetLiens ...
+type GetLiens struct {
+	Store LienStore
+}
+
+func (g *GetLiens) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	liens, err := g.Store.GetLiens(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, err.Error(), storeErrorCode(err))
+		return
+	}
+
+	liensJSON, err := json.Marshal(liens)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(liensJSON)
+}
+
+// PlaceLien encumbers the car at the revision of If-Match
+type PlaceLien struct {
+	Store LienStore
+}
+
+func (g *PlaceLien) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	revision, code, err := ifMatch(r)
+	if err != nil {
+		http.Error(w, err.Error(), code)
+		return
+	}
+
+	var req struct {
+		Amount int64 `json:"amount"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if req.Amount <= 0 {
+		http.Error(w, errors.New("Supply a positive amount").Error(), http.StatusBadRequest)
+		return
+	}
+
+	carID := mux.Vars(r)["id"]
+	lien, err := g.Store.PlaceLien(carID, req.Amount, revision)
+	if err != nil {
+		http.Error(w, err.Error(), storeErrorCode(err))
+		return
+	}
+
+	lienJSON, err := json.Marshal(lien)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", "/cars/"+carID+"/liens/"+lien.ID)
+	w.WriteHeader(http.StatusCreated)
+	w.Write(lienJSON)
+}
+
+// ReleaseLien releases the lien of the car at the revision of If-Match
+type ReleaseLien struct {
+	Store LienStore
+}
+
+func (g *ReleaseLien) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	revision, code, err := ifMatch(r)
+	if err != nil {
+		http.Error(w, err.Error(), code)
+		return
+	}
+
+	vars := mux.Vars(r)
+	if err := g.Store.ReleaseLien(vars["id"], vars["lienId"], revision); err != nil {
+		http.Error(w, err.Error(), storeErrorCode(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
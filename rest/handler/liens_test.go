@@ -0,0 +1,189 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/yimialmonte/chaincode-cars/asset"
+)
+
+type testLienStore struct {
+	called       int
+	revision     int
+	liens        []*asset.Lien
+	lienResponse *asset.Lien
+	errResponse  error
+}
+
+func (t *testLienStore) GetLiens(carID string) ([]*asset.Lien, error) {
+	t.called++
+	return t.liens, t.errResponse
+}
+
+func (t *testLienStore) PlaceLien(carID string, amount int64, revision int) (*asset.Lien, error) {
+	t.called++
+	t.revision = revision
+	return t.lienResponse, t.errResponse
+}
+
+func (t *testLienStore) ReleaseLien(carID, lienID string, revision int) error {
+	t.called++
+	t.revision = revision
+	return t.errResponse
+}
+
+func TestGetLiens(t *testing.T) {
+	tests := []struct {
+		liens        []*asset.Lien
+		expectedErr  error
+		expectedCode int
+		expectedRes  string
+	}{
+		{
+			[]*asset.Lien{{ID: "tx1", CarID: "12", Lender: "BankMSP", Amount: 100, Status: asset.LienActive, PlacedAt: "2021-09-21T15:00:00Z"}},
+			nil,
+			http.StatusOK,
+			`[{"id":"tx1","carId":"12","lender":"BankMSP","amount":100,"status":"active","placedAt":"2021-09-21T15:00:00Z"}]`,
+		},
+		{
+			nil,
+			fmt.Errorf("%w, ID: 12", ErrCarNotFound),
+			http.StatusNotFound,
+			"car not found, ID: 12\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%v", test), func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/cars/12/liens", nil)
+			record := httptest.NewRecorder()
+
+			get := GetLiens{Store: &testLienStore{liens: test.liens, errResponse: test.expectedErr}}
+			get.ServeHTTP(record, r)
+
+			assert.Equal(t, test.expectedCode, record.Code)
+			assert.Equal(t, test.expectedRes, record.Body.String())
+		})
+	}
+}
+
+func TestPlaceLien(t *testing.T) {
+	tests := []struct {
+		body         string
+		ifMatch      string
+		expectedErr  error
+		expectedCode int
+		expectedRes  string
+		expectedCall int
+	}{
+		{
+			`{"amount":100}`,
+			`"3"`,
+			nil,
+			http.StatusCreated,
+			`{"id":"tx1","carId":"12","lender":"BankMSP","amount":100,"status":"active","placedAt":""}`,
+			1,
+		},
+		{
+			`{"amount":100}`,
+			`"2"`,
+			fmt.Errorf("%w, car 12 is at revision 3", ErrPreconditionFailed),
+			http.StatusPreconditionFailed,
+			"car was modified, precondition failed, car 12 is at revision 3\n",
+			1,
+		},
+		{
+			`{"amount":100}`,
+			"",
+			nil,
+			http.StatusPreconditionRequired,
+			"If-Match header is required\n",
+			0,
+		},
+		{
+			`{"amount":100}`,
+			`"3"`,
+			fmt.Errorf("%w, the caller does not have the lender role", ErrForbidden),
+			http.StatusForbidden,
+			"forbidden, the caller does not have the lender role\n",
+			1,
+		},
+		{
+			`{"amount":-1}`,
+			`"3"`,
+			nil,
+			http.StatusBadRequest,
+			"Supply a positive amount\n",
+			0,
+		},
+		{
+			`{"amount":`,
+			`"3"`,
+			nil,
+			http.StatusBadRequest,
+			"unexpected EOF\n",
+			0,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%v", test), func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/cars/12/liens", strings.NewReader(test.body))
+			r = mux.SetURLVars(r, map[string]string{"id": "12"})
+			if test.ifMatch != "" {
+				r.Header.Set("If-Match", test.ifMatch)
+			}
+			record := httptest.NewRecorder()
+
+			store := &testLienStore{
+				lienResponse: &asset.Lien{ID: "tx1", CarID: "12", Lender: "BankMSP", Amount: 100, Status: asset.LienActive},
+				errResponse:  test.expectedErr,
+			}
+			place := PlaceLien{Store: store}
+			place.ServeHTTP(record, r)
+
+			assert.Equal(t, test.expectedCode, record.Code)
+			assert.Equal(t, test.expectedRes, record.Body.String())
+			assert.Equal(t, test.expectedCall, store.called)
+			if test.expectedCode == http.StatusCreated {
+				assert.Equal(t, "/cars/12/liens/tx1", record.Header().Get("Location"))
+				assert.Equal(t, 3, store.revision)
+			}
+		})
+	}
+}
+
+func TestReleaseLien(t *testing.T) {
+	tests := []struct {
+		ifMatch      string
+		expectedErr  error
+		expectedCode int
+	}{
+		{`"1"`, nil, http.StatusNoContent},
+		{`"0"`, fmt.Errorf("%w, car 12 is at revision 1", ErrPreconditionFailed), http.StatusPreconditionFailed},
+		{"", nil, http.StatusPreconditionRequired},
+		{`"1"`, fmt.Errorf("%w, the lien tx1 belongs to BankMSP", ErrForbidden), http.StatusForbidden},
+		{`"1"`, fmt.Errorf("internal server error"), http.StatusInternalServerError},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%v", test), func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodDelete, "/cars/12/liens/tx1", nil)
+			r = mux.SetURLVars(r, map[string]string{"id": "12", "lienId": "tx1"})
+			if test.ifMatch != "" {
+				r.Header.Set("If-Match", test.ifMatch)
+			}
+			record := httptest.NewRecorder()
+
+			release := ReleaseLien{Store: &testLienStore{errResponse: test.expectedErr}}
+			release.ServeHTTP(record, r)
+
+			assert.Equal(t, test.expectedCode, record.Code)
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/yimialmonte/chaincode-cars/asset"
+)
+
+// OdometerStore ...
+type OdometerStore interface {
+	GetOdometerReadings(carID string) ([]*asset.OdometerReading, error)
+}
+
+// maxKmPerDay is more than a car covers in a day, a bigger increase between
+// two readings is marked as an anomaly
+const maxKmPerDay = 1500
+
+// OdometerTimeline is the mileage history of a car
+type OdometerTimeline struct {
+	CarID    string           `json:"carId"`
+	Readings []*OdometerEntry `json:"readings"`
+}
+
+// OdometerEntry is a reading of the timeline, Anomaly explains why the
+// increase since the previous reading is suspicious
+type OdometerEntry struct {
+	*asset.OdometerReading
+	Anomaly string `json:"anomaly,omitempty"`
+}
+
+// GetOdometer returns the readings of the car oldest first
+type GetOdometer struct {
+	Store OdometerStore
+}
+
+func (g *GetOdometer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	carID := mux.Vars(r)["id"]
+	readings, err := g.Store.GetOdometerReadings(carID)
+	if err != nil {
+		http.Error(w, err.Error(), storeErrorCode(err))
+		return
+	}
+
+	timeline := OdometerTimeline{CarID: carID, Readings: odometerEntries(readings)}
+
+	timelineJSON, err := json.Marshal(timeline)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(timelineJSON)
+}
+
+// odometerEntries marks the readings increasing more than maxKmPerDay per
+// day since the previous one, a day at least
+func odometerEntries(readings []*asset.OdometerReading) []*OdometerEntry {
+	entries := make([]*OdometerEntry, 0, len(readings))
+	for i, reading := range readings {
+		entry := &OdometerEntry{OdometerReading: reading}
+		entries = append(entries, entry)
+		if i == 0 {
+			continue
+		}
+
+		previous := readings[i-1]
+		from, errFrom := time.Parse(time.RFC3339, previous.RecordedAt)
+		to, errTo := time.Parse(time.RFC3339, reading.RecordedAt)
+		if errFrom != nil || errTo != nil {
+			continue
+		}
+
+		days := to.Sub(from).Hours() / 24
+		if days < 1 {
+			days = 1
+		}
+
+		if km := reading.Km - previous.Km; float64(km) > maxKmPerDay*days {
+			entry.Anomaly = fmt.Sprintf("%d km in %.1f days since the previous reading", km, days)
+		}
+	}
+
+	return entries
+}
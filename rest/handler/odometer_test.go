@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/yimialmonte/chaincode-cars/asset"
+)
+
+type testOdometerStore struct {
+	readingsResponse []*asset.OdometerReading
+	errResponse      error
+}
+
+func (t *testOdometerStore) GetOdometerReadings(carID string) ([]*asset.OdometerReading, error) {
+	return t.readingsResponse, t.errResponse
+}
+
+func TestGetOdometer(t *testing.T) {
+	tests := []struct {
+		response     []*asset.OdometerReading
+		expectedErr  error
+		expectedCode int
+		expectedRes  string
+	}{
+		{
+			[]*asset.OdometerReading{
+				{CarID: "12", Km: 52000, Source: "inspection", RecordedAt: "2021-09-21T15:00:00Z", TxID: "tx1"},
+				{CarID: "12", Km: 61000, Source: "workshop", RecordedAt: "2022-03-01T10:00:00Z", TxID: "tx2"},
+			},
+			nil,
+			http.StatusOK,
+			`{"carId":"12","readings":[{"carId":"12","km":52000,"source":"inspection","recordedAt":"2021-09-21T15:00:00Z","txId":"tx1"},{"carId":"12","km":61000,"source":"workshop","recordedAt":"2022-03-01T10:00:00Z","txId":"tx2"}]}`,
+		},
+		{
+			[]*asset.OdometerReading{
+				{CarID: "12", Km: 52000, Source: "inspection", RecordedAt: "2021-09-21T15:00:00Z", TxID: "tx1"},
+				{CarID: "12", Km: 52900, Source: "owner", RecordedAt: "2021-09-21T18:00:00Z", TxID: "tx2"},
+				{CarID: "12", Km: 90000, Source: "workshop", RecordedAt: "2021-09-23T18:00:00Z", TxID: "tx3"},
+			},
+			nil,
+			http.StatusOK,
+			`{"carId":"12","readings":[{"carId":"12","km":52000,"source":"inspection","recordedAt":"2021-09-21T15:00:00Z","txId":"tx1"},{"carId":"12","km":52900,"source":"owner","recordedAt":"2021-09-21T18:00:00Z","txId":"tx2"},{"carId":"12","km":90000,"source":"workshop","recordedAt":"2021-09-23T18:00:00Z","txId":"tx3","anomaly":"37100 km in 2.0 days since the previous reading"}]}`,
+		},
+		{
+			nil,
+			nil,
+			http.StatusOK,
+			`{"carId":"12","readings":[]}`,
+		},
+		{
+			nil,
+			fmt.Errorf("%w ID: 12", ErrCarNotFound),
+			http.StatusNotFound,
+			"car not found ID: 12\n",
+		},
+		{
+			nil,
+			fmt.Errorf("internal server error"),
+			http.StatusInternalServerError,
+			"internal server error\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%v", test), func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/cars/12/odometer", nil)
+			r = mux.SetURLVars(r, map[string]string{"id": "12"})
+			record := httptest.NewRecorder()
+
+			get := GetOdometer{Store: &testOdometerStore{readingsResponse: test.response, errResponse: test.expectedErr}}
+			get.ServeHTTP(record, r)
+
+			assert.Equal(t, test.expectedCode, record.Code)
+			assert.Equal(t, test.expectedRes, record.Body.String())
+		})
+	}
+}
@@ -0,0 +1,163 @@
+package handler
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/yimialmonte/chaincode-cars/asset"
+)
+
+// OwnerStore ...
+type OwnerStore interface {
+	GetOwner(id string) (*asset.Owner, error)
+	ResolveOwner(id string) (*asset.OwnerPII, error)
+	CreateOwner(owner asset.OwnerRegistration) error
+	UpdateOwner(owner asset.OwnerRegistration) error
+	EraseOwner(id string) error
+}
+
+// ownerResponse is the public record of an owner, with the name for the
+// callers allowed to resolve it
+type ownerResponse struct {
+	*asset.Owner
+	Name string `json:"name,omitempty"`
+}
+
+// GetOwner returns the pseudonymous record of the owner, the name is only
+// resolved for the callers with the pii-reader role
+type GetOwner struct {
+	Store OwnerStore
+	Auth  Authorizer
+}
+
+func (g *GetOwner) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	owner, err := g.Store.GetOwner(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, err.Error(), storeErrorCode(err))
+		return
+	}
+
+	response := ownerResponse{Owner: owner}
+	if !owner.Erased && hasRole(g.Auth, r, RolePIIReader) {
+		pii, err := g.Store.ResolveOwner(owner.ID)
+		if err != nil {
+			http.Error(w, err.Error(), storeErrorCode(err))
+			return
+		}
+		response.Name = pii.Name
+	}
+
+	ownerJSON, err := json.Marshal(response)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(ownerJSON)
+}
+
+// CreateOwner registers an owner, cars can only be registered or
+// transferred to registered owners. Without an id a pseudonymous one is
+// generated, names should not be used as ids since they are public.
+type CreateOwner struct {
+	Store OwnerStore
+}
+
+func (g *CreateOwner) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var owner asset.OwnerRegistration
+	if err := json.NewDecoder(r.Body).Decode(&owner); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if owner.ID == "" {
+		id, err := pseudonym()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		owner.ID = id
+	}
+
+	if err := owner.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := g.Store.CreateOwner(owner); err != nil {
+		http.Error(w, err.Error(), storeErrorCode(err))
+		return
+	}
+
+	ownerJSON, err := json.Marshal(asset.Owner{ID: owner.ID, Type: owner.Type})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Location", "/owners/"+owner.ID)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	w.Write(ownerJSON)
+}
+
+// UpdateOwner ...
+type UpdateOwner struct {
+	Store OwnerStore
+}
+
+func (g *UpdateOwner) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var owner asset.OwnerRegistration
+	if err := json.NewDecoder(r.Body).Decode(&owner); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	owner.ID = mux.Vars(r)["id"]
+	if err := owner.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := g.Store.UpdateOwner(owner); err != nil {
+		http.Error(w, err.Error(), storeErrorCode(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// EraseOwner deletes the personal data of the owner, only for the callers
+// with the privacy-officer role
+type EraseOwner struct {
+	Store OwnerStore
+	Auth  Authorizer
+}
+
+func (g *EraseOwner) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !hasRole(g.Auth, r, RolePrivacyOfficer) {
+		http.Error(w, "the privacy-officer role is required", http.StatusForbidden)
+		return
+	}
+
+	if err := g.Store.EraseOwner(mux.Vars(r)["id"]); err != nil {
+		http.Error(w, err.Error(), storeErrorCode(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// pseudonym returns a random owner id
+func pseudonym() (string, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+
+	return "o-" + hex.EncodeToString(id), nil
+}
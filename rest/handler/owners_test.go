@@ -0,0 +1,210 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/yimialmonte/chaincode-cars/asset"
+)
+
+type testOwnerStore struct {
+	called        int
+	ownerResponse *asset.Owner
+	piiResponse   *asset.OwnerPII
+	ownerReceived asset.OwnerRegistration
+	idReceived    string
+	errResponse   error
+}
+
+func (t *testOwnerStore) GetOwner(id string) (*asset.Owner, error) {
+	t.called++
+	return t.ownerResponse, t.errResponse
+}
+
+func (t *testOwnerStore) ResolveOwner(id string) (*asset.OwnerPII, error) {
+	t.called++
+	return t.piiResponse, t.errResponse
+}
+
+func (t *testOwnerStore) CreateOwner(owner asset.OwnerRegistration) error {
+	t.called++
+	t.ownerReceived = owner
+	return t.errResponse
+}
+
+func (t *testOwnerStore) UpdateOwner(owner asset.OwnerRegistration) error {
+	t.called++
+	t.ownerReceived = owner
+	return t.errResponse
+}
+
+func (t *testOwnerStore) EraseOwner(id string) error {
+	t.called++
+	t.idReceived = id
+	return t.errResponse
+}
+
+func TestGetOwner(t *testing.T) {
+	auth := TokenAuthorizer{"s3cret": {RolePIIReader}}
+	acme := &asset.Owner{ID: "o-acme", Type: asset.OwnerCompany}
+	pii := &asset.OwnerPII{ID: "o-acme", Name: "Acme Rentals SL"}
+
+	tests := []struct {
+		owner        *asset.Owner
+		token        string
+		expectedErr  error
+		expectedCode int
+		expectedRes  string
+		expectedCall int
+	}{
+		{acme, "", nil, http.StatusOK, `{"id":"o-acme","type":"company"}`, 1},
+		{acme, "other", nil, http.StatusOK, `{"id":"o-acme","type":"company"}`, 1},
+		{acme, "s3cret", nil, http.StatusOK, `{"id":"o-acme","type":"company","name":"Acme Rentals SL"}`, 2},
+		{&asset.Owner{ID: "o-acme", Type: asset.OwnerCompany, Erased: true}, "s3cret", nil, http.StatusOK, `{"id":"o-acme","type":"company","erased":true}`, 1},
+		{
+			nil,
+			"s3cret",
+			fmt.Errorf("%w, owner does not exist ID: o-acme", ErrOwnerNotFound),
+			http.StatusNotFound,
+			"owner not found, owner does not exist ID: o-acme\n",
+			1,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%v", test), func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/owners/o-acme", nil)
+			r.Header.Set("Authorization", "Bearer "+test.token)
+			record := httptest.NewRecorder()
+
+			store := &testOwnerStore{ownerResponse: test.owner, piiResponse: pii, errResponse: test.expectedErr}
+			get := GetOwner{Store: store, Auth: auth}
+			get.ServeHTTP(record, r)
+
+			assert.Equal(t, test.expectedCode, record.Code)
+			assert.Equal(t, test.expectedRes, record.Body.String())
+			assert.Equal(t, test.expectedCall, store.called)
+		})
+	}
+}
+
+func TestCreateOwner(t *testing.T) {
+	tests := []struct {
+		body         string
+		expectedErr  error
+		expectedCode int
+		expectedRes  string
+		expectedCall int
+	}{
+		{`{"id":"o-acme","name":"Acme Rentals SL","type":"company"}`, nil, http.StatusCreated, `{"id":"o-acme","type":"company"}`, 1},
+		{`{"id":"o-acme","name":"Acme Rentals SL","type":"company"}`, fmt.Errorf("%w, the owner with id o-acme already exist", ErrOwnerExists), http.StatusConflict, "owner already exists, the owner with id o-acme already exist\n", 1},
+		{`{"id":"o-acme","name":"Acme Rentals SL","type":"robot"}`, nil, http.StatusBadRequest, "unknown owner type \"robot\", use person or company\n", 0},
+		{`{"id":"o-acme","type":"company"}`, nil, http.StatusBadRequest, "the owner id and name are required\n", 0},
+		{`{"id":`, nil, http.StatusBadRequest, "unexpected EOF\n", 0},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%v", test), func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/owners", strings.NewReader(test.body))
+			record := httptest.NewRecorder()
+
+			store := &testOwnerStore{errResponse: test.expectedErr}
+			create := CreateOwner{Store: store}
+			create.ServeHTTP(record, r)
+
+			assert.Equal(t, test.expectedCode, record.Code)
+			assert.Equal(t, test.expectedRes, record.Body.String())
+			assert.Equal(t, test.expectedCall, store.called)
+			if test.expectedCode == http.StatusCreated {
+				assert.Equal(t, "/owners/o-acme", record.Header().Get("Location"))
+				assert.Equal(t, "Acme Rentals SL", store.ownerReceived.Name)
+			}
+		})
+	}
+}
+
+func TestCreateOwnerPseudonym(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "/owners", strings.NewReader(`{"name":"Ana García","type":"person"}`))
+	record := httptest.NewRecorder()
+
+	store := &testOwnerStore{}
+	create := CreateOwner{Store: store}
+	create.ServeHTTP(record, r)
+
+	assert.Equal(t, http.StatusCreated, record.Code)
+	assert.Regexp(t, `^o-[0-9a-f]{32}$`, store.ownerReceived.ID)
+	assert.Equal(t, "/owners/"+store.ownerReceived.ID, record.Header().Get("Location"))
+	assert.NotContains(t, record.Body.String(), "Ana")
+}
+
+func TestUpdateOwner(t *testing.T) {
+	tests := []struct {
+		body         string
+		expectedErr  error
+		expectedCode int
+		expectedCall int
+	}{
+		{`{"id":"other","name":"Acme Rentals SA","type":"company"}`, nil, http.StatusNoContent, 1},
+		{`{"name":"Acme Rentals SA","type":"company"}`, fmt.Errorf("%w, the owner o-acme is linked to another identity", ErrForbidden), http.StatusForbidden, 1},
+		{`{"name":"","type":"company"}`, nil, http.StatusBadRequest, 0},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%v", test), func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPut, "/owners/o-acme", strings.NewReader(test.body))
+			r = mux.SetURLVars(r, map[string]string{"id": "o-acme"})
+			record := httptest.NewRecorder()
+
+			store := &testOwnerStore{errResponse: test.expectedErr}
+			update := UpdateOwner{Store: store}
+			update.ServeHTTP(record, r)
+
+			assert.Equal(t, test.expectedCode, record.Code)
+			assert.Equal(t, test.expectedCall, store.called)
+			if test.expectedCall == 1 {
+				assert.Equal(t, "o-acme", store.ownerReceived.ID, "the path sets the ID")
+			}
+		})
+	}
+}
+
+func TestEraseOwner(t *testing.T) {
+	auth := TokenAuthorizer{"reader": {RolePIIReader}, "officer": {RolePrivacyOfficer}}
+
+	tests := []struct {
+		token        string
+		expectedErr  error
+		expectedCode int
+		expectedCall int
+	}{
+		{"officer", nil, http.StatusNoContent, 1},
+		{"officer", fmt.Errorf("%w, owner does not exist ID: o-acme", ErrOwnerNotFound), http.StatusNotFound, 1},
+		{"officer", fmt.Errorf("%w, the caller does not have the privacy-officer role", ErrForbidden), http.StatusForbidden, 1},
+		{"reader", nil, http.StatusForbidden, 0},
+		{"", nil, http.StatusForbidden, 0},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%v", test), func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodDelete, "/owners/o-acme/personal-data", nil)
+			r = mux.SetURLVars(r, map[string]string{"id": "o-acme"})
+			r.Header.Set("Authorization", "Bearer "+test.token)
+			record := httptest.NewRecorder()
+
+			store := &testOwnerStore{errResponse: test.expectedErr}
+			erase := EraseOwner{Store: store, Auth: auth}
+			erase.ServeHTTP(record, r)
+
+			assert.Equal(t, test.expectedCode, record.Code)
+			assert.Equal(t, test.expectedCall, store.called)
+			if test.expectedCall == 1 {
+				assert.Equal(t, "o-acme", store.idReceived)
+			}
+		})
+	}
+}
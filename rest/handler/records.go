@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/yimialmonte/chaincode-cars/asset"
+)
+
+// ServiceRecordStore ...
+type ServiceRecordStore interface {
+	GetServiceRecords(carID string) ([]*asset.ServiceRecord, error)
+	AddServiceRecord(record asset.ServiceRecord) (*asset.ServiceRecord, error)
+}
+
+// GetServiceRecords ...
+type GetServiceRecords struct {
+	Store ServiceRecordStore
+}
+
+func (g *GetServiceRecords) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	records, err := g.Store.GetServiceRecords(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, err.Error(), storeErrorCode(err))
+		return
+	}
+
+	recordsJSON, err := json.Marshal(records)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(recordsJSON)
+}
+
+// AddServiceRecord logs a service or inspection, the workshop is the identity
+// submitting the transaction
+type AddServiceRecord struct {
+	Store ServiceRecordStore
+}
+
+func (g *AddServiceRecord) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Type         string `json:"type"`
+		Date         string `json:"date"`
+		DocumentHash string `json:"documentHash"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	record := asset.ServiceRecord{CarID: mux.Vars(r)["id"], Type: req.Type, Date: req.Date, DocumentHash: req.DocumentHash}
+	if err := record.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	created, err := g.Store.AddServiceRecord(record)
+	if err != nil {
+		http.Error(w, err.Error(), storeErrorCode(err))
+		return
+	}
+
+	recordJSON, err := json.Marshal(created)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	w.Write(recordJSON)
+}
@@ -0,0 +1,139 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/yimialmonte/chaincode-cars/asset"
+)
+
+const testDocumentHash = "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
+
+type testServiceRecordStore struct {
+	called         int
+	records        []*asset.ServiceRecord
+	recordReceived asset.ServiceRecord
+	errResponse    error
+}
+
+func (t *testServiceRecordStore) GetServiceRecords(carID string) ([]*asset.ServiceRecord, error) {
+	t.called++
+	return t.records, t.errResponse
+}
+
+func (t *testServiceRecordStore) AddServiceRecord(record asset.ServiceRecord) (*asset.ServiceRecord, error) {
+	t.called++
+	t.recordReceived = record
+	if t.errResponse != nil {
+		return nil, t.errResponse
+	}
+
+	record.ID = "tx1"
+	record.Workshop = "WorkshopMSP"
+	return &record, nil
+}
+
+func TestGetServiceRecords(t *testing.T) {
+	tests := []struct {
+		records      []*asset.ServiceRecord
+		expectedErr  error
+		expectedCode int
+		expectedRes  string
+	}{
+		{
+			[]*asset.ServiceRecord{{ID: "tx1", CarID: "12", Workshop: "WorkshopMSP", Date: "2021-09-01", Type: asset.RecordInspection, DocumentHash: testDocumentHash, RecordedAt: "2021-09-21T15:00:00Z"}},
+			nil,
+			http.StatusOK,
+			`[{"id":"tx1","carId":"12","workshop":"WorkshopMSP","date":"2021-09-01","type":"inspection","documentHash":"` + testDocumentHash + `","recordedAt":"2021-09-21T15:00:00Z"}]`,
+		},
+		{
+			nil,
+			fmt.Errorf("%w, ID: 12", ErrCarNotFound),
+			http.StatusNotFound,
+			"car not found, ID: 12\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%v", test), func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/cars/12/records", nil)
+			record := httptest.NewRecorder()
+
+			get := GetServiceRecords{Store: &testServiceRecordStore{records: test.records, errResponse: test.expectedErr}}
+			get.ServeHTTP(record, r)
+
+			assert.Equal(t, test.expectedCode, record.Code)
+			assert.Equal(t, test.expectedRes, record.Body.String())
+		})
+	}
+}
+
+func TestAddServiceRecord(t *testing.T) {
+	tests := []struct {
+		body         string
+		expectedErr  error
+		expectedCode int
+		expectedRes  string
+		expectedCall int
+	}{
+		{
+			`{"type":"maintenance","date":"2021-09-01","documentHash":"` + testDocumentHash + `"}`,
+			nil,
+			http.StatusCreated,
+			`{"id":"tx1","carId":"12","workshop":"WorkshopMSP","date":"2021-09-01","type":"maintenance","documentHash":"` + testDocumentHash + `"}`,
+			1,
+		},
+		{
+			`{"type":"maintenance","date":"2021-09-01","documentHash":"` + testDocumentHash + `"}`,
+			fmt.Errorf("%w, organization Org1MSP is not allowed", ErrForbidden),
+			http.StatusForbidden,
+			"forbidden, organization Org1MSP is not allowed\n",
+			1,
+		},
+		{
+			`{"type":"wash","date":"2021-09-01","documentHash":"` + testDocumentHash + `"}`,
+			nil,
+			http.StatusBadRequest,
+			"unknown record type \"wash\", use maintenance, repair or inspection\n",
+			0,
+		},
+		{
+			`{"type":"repair","date":"2021-09-01","documentHash":"abc"}`,
+			nil,
+			http.StatusBadRequest,
+			"the document hash must be a hex SHA-256\n",
+			0,
+		},
+		{
+			`{"type":`,
+			nil,
+			http.StatusBadRequest,
+			"unexpected EOF\n",
+			0,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%v", test), func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/cars/12/records", strings.NewReader(test.body))
+			r = mux.SetURLVars(r, map[string]string{"id": "12"})
+			record := httptest.NewRecorder()
+
+			store := &testServiceRecordStore{errResponse: test.expectedErr}
+			add := AddServiceRecord{Store: store}
+			add.ServeHTTP(record, r)
+
+			assert.Equal(t, test.expectedCode, record.Code)
+			assert.Equal(t, test.expectedRes, record.Body.String())
+			assert.Equal(t, test.expectedCall, store.called)
+			if test.expectedCall == 1 {
+				assert.Equal(t, "12", store.recordReceived.CarID)
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/yimialmonte/chaincode-cars/asset"
+)
+
+// CarSearcher ...
+type CarSearcher interface {
+	QueryCars(query asset.CarQuery) ([]*asset.Car, error)
+}
+
+// SearchCars maps the query string to a rich query over the cars
+type SearchCars struct {
+	Store CarSearcher
+}
+
+func (g *SearchCars) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	query, err := parseCarQuery(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	cars, err := g.Store.QueryCars(query)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	carsJSON, err := json.Marshal(cars)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(carsJSON)
+}
+
+// parseCarQuery accepts only the whitelisted search parameters
+func parseCarQuery(r *http.Request) (asset.CarQuery, error) {
+	var query asset.CarQuery
+	ranges := map[string]**int{
+		"minTransfers": &query.MinTransfers,
+		"maxTransfers": &query.MaxTransfers,
+		"minYear":      &query.MinYear,
+		"maxYear":      &query.MaxYear,
+	}
+
+	for name, values := range r.URL.Query() {
+		value := values[0]
+		switch name {
+		case "brand":
+			query.Brand = value
+		case "owner":
+			query.Owner = value
+		case "type":
+			query.Type = value
+		default:
+			field, ok := ranges[name]
+			if !ok {
+				return query, fmt.Errorf("unknown search parameter %s", name)
+			}
+
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				return query, fmt.Errorf("invalid %s %s", name, value)
+			}
+			*field = &n
+		}
+	}
+
+	return query, query.Validate()
+}
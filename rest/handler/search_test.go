@@ -0,0 +1,118 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yimialmonte/chaincode-cars/asset"
+)
+
+type testSearcher struct {
+	called       int
+	query        asset.CarQuery
+	carsResponse []*asset.Car
+	errResponse  error
+}
+
+func (t *testSearcher) QueryCars(query asset.CarQuery) ([]*asset.Car, error) {
+	t.called++
+	t.query = query
+	return t.carsResponse, t.errResponse
+}
+
+func TestSearchCars(t *testing.T) {
+	one, three := 1, 3
+
+	tests := []struct {
+		url           string
+		response      []*asset.Car
+		expectedErr   error
+		expectedCode  int
+		expectedRes   string
+		expectedQuery asset.CarQuery
+		expectedCalls int
+	}{
+		{
+			"/cars/search?brand=Honda&minTransfers=1&maxTransfers=3",
+			[]*asset.Car{{ID: "000", Brand: "Honda", Owner: "Max", TransfersCount: 2}},
+			nil,
+			http.StatusOK,
+			`[{"id":"000","brand":"Honda","owner":"Max","transfersCount":2}]`,
+			asset.CarQuery{Brand: "Honda", MinTransfers: &one, MaxTransfers: &three},
+			1,
+		},
+		{
+			"/cars/search?owner=Max",
+			[]*asset.Car{},
+			fmt.Errorf("internal server error"),
+			http.StatusInternalServerError,
+			"internal server error\n",
+			asset.CarQuery{Owner: "Max"},
+			1,
+		},
+		{
+			"/cars/search?type=truck",
+			[]*asset.Car{{ID: "T1", Brand: "Volvo", Owner: "Max", Type: "truck", Axles: 3, PayloadKg: 18000}},
+			nil,
+			http.StatusOK,
+			`[{"id":"T1","brand":"Volvo","owner":"Max","transfersCount":0,"type":"truck","axles":3,"payloadKg":18000}]`,
+			asset.CarQuery{Type: "truck"},
+			1,
+		},
+		{
+			"/cars/search?type=boat",
+			nil,
+			nil,
+			http.StatusBadRequest,
+			"unknown vehicle type \"boat\"\n",
+			asset.CarQuery{},
+			0,
+		},
+		{
+			"/cars/search?color=red",
+			nil,
+			nil,
+			http.StatusBadRequest,
+			"unknown search parameter color\n",
+			asset.CarQuery{},
+			0,
+		},
+		{
+			"/cars/search?minYear=old",
+			nil,
+			nil,
+			http.StatusBadRequest,
+			"invalid minYear old\n",
+			asset.CarQuery{},
+			0,
+		},
+		{
+			"/cars/search",
+			nil,
+			nil,
+			http.StatusBadRequest,
+			"supply at least one search criterion\n",
+			asset.CarQuery{},
+			0,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%v", test.url), func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, test.url, nil)
+			record := httptest.NewRecorder()
+
+			store := &testSearcher{carsResponse: test.response, errResponse: test.expectedErr}
+			search := SearchCars{Store: store}
+			search.ServeHTTP(record, r)
+
+			assert.Equal(t, test.expectedCode, record.Code)
+			assert.Equal(t, test.expectedRes, record.Body.String())
+			assert.Equal(t, test.expectedCalls, store.called)
+			assert.Equal(t, test.expectedQuery, store.query)
+		})
+	}
+}
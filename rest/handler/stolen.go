@@ -0,0 +1,35 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/yimialmonte/chaincode-cars/asset"
+)
+
+// StolenLister ...
+type StolenLister interface {
+	GetStolenCars() ([]*asset.Car, error)
+}
+
+// GetStolenCars lists the cars reported stolen, for checkpoints
+type GetStolenCars struct {
+	Store StolenLister
+}
+
+func (g *GetStolenCars) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	cars, err := g.Store.GetStolenCars()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	carsJSON, err := json.Marshal(cars)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(carsJSON)
+}
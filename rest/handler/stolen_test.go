@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yimialmonte/chaincode-cars/asset"
+)
+
+type testStolenLister struct {
+	carsResponse []*asset.Car
+	errResponse  error
+}
+
+func (t *testStolenLister) GetStolenCars() ([]*asset.Car, error) {
+	return t.carsResponse, t.errResponse
+}
+
+func TestGetStolenCars(t *testing.T) {
+	tests := []struct {
+		response     []*asset.Car
+		expectedErr  error
+		expectedCode int
+		expectedRes  string
+	}{
+		{
+			[]*asset.Car{{ID: "12", Brand: "Toyota", Owner: "Juan", Stolen: true, StolenReport: "reported by PoliceMSP at 2021-09-21T15:00:00Z"}},
+			nil,
+			http.StatusOK,
+			`[{"id":"12","brand":"Toyota","owner":"Juan","transfersCount":0,"stolen":true,"stolenReport":"reported by PoliceMSP at 2021-09-21T15:00:00Z"}]`,
+		},
+		{
+			[]*asset.Car{},
+			nil,
+			http.StatusOK,
+			"[]",
+		},
+		{
+			nil,
+			fmt.Errorf("internal server error"),
+			http.StatusInternalServerError,
+			"internal server error\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%v", test), func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/cars/stolen", nil)
+			record := httptest.NewRecorder()
+
+			get := GetStolenCars{Store: &testStolenLister{carsResponse: test.response, errResponse: test.expectedErr}}
+			get.ServeHTTP(record, r)
+
+			assert.Equal(t, test.expectedCode, record.Code)
+			assert.Equal(t, test.expectedRes, record.Body.String())
+		})
+	}
+}
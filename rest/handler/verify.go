@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/yimialmonte/chaincode-cars/asset"
+	"github.com/yimialmonte/chaincode-cars/certificate"
+)
+
+// maxCertificateSize bounds the body of a verification request
+const maxCertificateSize = 1 << 20
+
+// CarGetter ...
+type CarGetter interface {
+	GetCar(id string) (*asset.Car, error)
+}
+
+// Verification is the result of checking an ownership certificate
+type Verification struct {
+	Valid bool   `json:"valid"`
+	Error string `json:"error,omitempty"`
+	CarID string `json:"carId,omitempty"`
+	certificate.Status
+}
+
+// VerifyCertificate checks the signature of a certificate and compares it
+// with the car in the ledger
+type VerifyCertificate struct {
+	Store CarGetter
+	Key   ed25519.PublicKey
+}
+
+func (g *VerifyCertificate) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxCertificateSize))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var result Verification
+	cert, err := certificate.Verify(body, g.Key)
+	switch {
+	case errors.Is(err, certificate.ErrMalformedDocument):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case err != nil:
+		result.Error = err.Error()
+	default:
+		result.Valid = true
+		result.CarID = cert.CredentialSubject.Car.ID
+
+		car, err := g.Store.GetCar(result.CarID)
+		if err != nil && !errors.Is(err, ErrCarNotFound) {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		result.Status = certificate.Compare(cert, car)
+	}
+
+	resultJSON, err := json.Marshal(result)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(resultJSON)
+}
@@ -0,0 +1,416 @@
+package repository
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/yimialmonte/chaincode-cars/asset"
+	"github.com/yimialmonte/chaincode-cars/chaincode"
+	"github.com/yimialmonte/chaincode-cars/chaincode/memstub"
+	"github.com/yimialmonte/chaincode-cars/rest/handler"
+)
+
+// Offline runs the chaincode in-process against a ledger persisted in a
+// local file, so the API works without a Fabric network. The transactions
+// go through the contract dispatcher like on a peer, audit hooks included.
+type Offline struct {
+	chaincode *contractapi.ContractChaincode
+	ledger    *memstub.Ledger
+	store     *memstub.FileStore
+}
+
+// NewOffline opens the ledger file at path, a new ledger is seeded with
+// InitLedger. The transactions are submitted as the creator identity.
+func NewOffline(path string, creator []byte) (*Offline, error) {
+	cc, err := chaincode.NewChaincode(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	store, err := memstub.OpenFileStore(path)
+	if err != nil {
+		return nil, err
+	}
+
+	ledger, err := memstub.Open(store, memstub.WithCreator(creator))
+	if err != nil {
+		store.Close()
+		return nil, err
+	}
+
+	o := &Offline{chaincode: cc, ledger: ledger, store: store}
+
+	cars, err := o.GetCars()
+	if err != nil {
+		store.Close()
+		return nil, err
+	}
+
+	if len(cars) == 0 {
+		if err := o.submit(nil, "admin:InitLedger"); err != nil {
+			store.Close()
+			return nil, err
+		}
+	}
+
+	return o, nil
+}
+
+// Close ...
+func (o *Offline) Close() error {
+	return o.store.Close()
+}
+
+// submit invokes the function as a transaction committed to the ledger and
+// decodes its result into out
+func (o *Offline) submit(out interface{}, function string, args ...interface{}) error {
+	return o.invoke(true, nil, out, function, args...)
+}
+
+// submitTransient submits the function like submit with the transient data
+// of the proposal
+func (o *Offline) submitTransient(transient map[string][]byte, out interface{}, function string, args ...interface{}) error {
+	return o.invoke(true, transient, out, function, args...)
+}
+
+// evaluate invokes the function without committing it, like a query
+// evaluated on a peer
+func (o *Offline) evaluate(out interface{}, function string, args ...interface{}) error {
+	return o.invoke(false, nil, out, function, args...)
+}
+
+// invoke runs the function in a new transaction and maps the contract
+// errors to the ones the handlers understand. The arguments are passed
+// like a client SDK does, strings as is and the rest as JSON.
+func (o *Offline) invoke(commit bool, transient map[string][]byte, out interface{}, function string, args ...interface{}) error {
+	stringArgs := make([]string, 0, len(args))
+	for _, arg := range args {
+		if s, ok := arg.(string); ok {
+			stringArgs = append(stringArgs, s)
+			continue
+		}
+
+		argJSON, err := json.Marshal(arg)
+		if err != nil {
+			return err
+		}
+		stringArgs = append(stringArgs, string(argJSON))
+	}
+
+	stub := o.ledger.NewTransaction(function, stringArgs...)
+	stub.SetTransient(transient)
+
+	response := o.chaincode.Invoke(stub)
+	if response.Status != shim.OK {
+		return storeError(errors.New(response.Message))
+	}
+
+	if commit {
+		if err := stub.Commit(); err != nil {
+			return storeError(err)
+		}
+	}
+
+	if out == nil || len(response.Payload) == 0 {
+		return nil
+	}
+
+	return json.Unmarshal(response.Payload, out)
+}
+
+// storeError maps the errors of the chaincode, the responses only carry
+// their messages
+func storeError(err error) error {
+	message := err.Error()
+	switch {
+	case strings.HasPrefix(message, "car does not exist"):
+		return fmt.Errorf("%w, %v", handler.ErrCarNotFound, err)
+	case strings.HasPrefix(message, "lien does not exist"):
+		return fmt.Errorf("%w, %v", handler.ErrLienNotFound, err)
+	case strings.HasPrefix(message, "owner does not exist"):
+		return fmt.Errorf("%w, %v", handler.ErrOwnerNotFound, err)
+	case strings.HasPrefix(message, "the owner with id"):
+		return fmt.Errorf("%w, %v", handler.ErrOwnerExists, err)
+	case strings.HasPrefix(message, "attachment does not exist"):
+		return fmt.Errorf("%w, %v", handler.ErrAttachmentNotFound, err)
+	case strings.HasPrefix(message, chaincode.ErrAccessDenied.Error()):
+		return fmt.Errorf("%w, %v", handler.ErrForbidden, err)
+	case strings.HasPrefix(message, chaincode.ErrRevisionMismatch.Error()), errors.Is(err, memstub.ErrReadConflict), errors.Is(err, memstub.ErrPhantomRead):
+		return fmt.Errorf("%w, %v", handler.ErrPreconditionFailed, err)
+	}
+
+	return err
+}
+
+// GetCars ...
+func (o *Offline) GetCars() ([]*asset.Car, error) {
+	var cars []*asset.Car
+	err := o.evaluate(&cars, "registry:GetCars")
+	return cars, err
+}
+
+// GetCarsByOwner ...
+func (o *Offline) GetCarsByOwner(owner string) ([]*asset.Car, error) {
+	var cars []*asset.Car
+	err := o.evaluate(&cars, "registry:GetCarsByOwner", owner)
+	return cars, err
+}
+
+// GetCar ...
+func (o *Offline) GetCar(id string) (*asset.Car, error) {
+	var car *asset.Car
+	err := o.evaluate(&car, "registry:GetCar", id)
+	return car, err
+}
+
+// TransferCart ...
+func (o *Offline) TransferCart(id, owner string, revision int) error {
+	return o.submit(nil, "transfer:TransferCarIfMatch", id, owner, revision)
+}
+
+// CreateCars ...
+func (o *Offline) CreateCars(cars []asset.Car) (*asset.BatchReport, error) {
+	var report *asset.BatchReport
+	err := o.submit(&report, "registry:CreateCars", cars)
+	return report, err
+}
+
+// CreateCar registers the car and reads it back from the ledger
+func (o *Offline) CreateCar(car asset.Car) (*asset.Car, error) {
+	report, err := o.CreateCars([]asset.Car{car})
+	if err != nil {
+		return nil, err
+	}
+
+	if item := report.Items[0]; !report.Created {
+		if item.Exists {
+			return nil, fmt.Errorf("%w, ID: %s", handler.ErrCarExists, car.ID)
+		}
+		return nil, fmt.Errorf("%w, %s", handler.ErrInvalidCar, item.Error)
+	}
+
+	return o.GetCar(car.ID)
+}
+
+// GetCarsPage ...
+func (o *Offline) GetCarsPage(pageSize int, bookmark string) (*asset.CarsPage, error) {
+	var page *asset.CarsPage
+	err := o.evaluate(&page, "registry:GetCarsPage", pageSize, bookmark)
+	return page, err
+}
+
+// QueryCars ...
+func (o *Offline) QueryCars(query asset.CarQuery) ([]*asset.Car, error) {
+	queryJSON, err := json.Marshal(query)
+	if err != nil {
+		return nil, err
+	}
+
+	var cars []*asset.Car
+	err = o.evaluate(&cars, "registry:QueryCars", string(queryJSON))
+	return cars, err
+}
+
+// GetCarRecord ...
+func (o *Offline) GetCarRecord(id string) (*asset.CarRecord, error) {
+	var record *asset.CarRecord
+	if err := o.evaluate(&record, "registry:GetCarRecord", id); err != nil {
+		return nil, err
+	}
+
+	record.BlockNumber, _ = o.ledger.BlockNumber(record.TxID)
+	return record, nil
+}
+
+// GetCarHistory ...
+func (o *Offline) GetCarHistory(id string) ([]*asset.CarRecord, error) {
+	var records []*asset.CarRecord
+	if err := o.evaluate(&records, "registry:GetCarHistory", id); err != nil {
+		return nil, err
+	}
+
+	for _, record := range records {
+		record.BlockNumber, _ = o.ledger.BlockNumber(record.TxID)
+	}
+	return records, nil
+}
+
+// GetLiens ...
+func (o *Offline) GetLiens(carID string) ([]*asset.Lien, error) {
+	if _, err := o.GetCar(carID); err != nil {
+		return nil, err
+	}
+
+	var liens []*asset.Lien
+	err := o.evaluate(&liens, "registry:GetLiens", carID)
+	return liens, err
+}
+
+// PlaceLien ...
+func (o *Offline) PlaceLien(carID string, amount int64, revision int) (*asset.Lien, error) {
+	var lien *asset.Lien
+	err := o.submit(&lien, "registry:PlaceLienIfMatch", carID, amount, revision)
+	return lien, err
+}
+
+// ReleaseLien ...
+func (o *Offline) ReleaseLien(carID, lienID string, revision int) error {
+	return o.submit(nil, "registry:ReleaseLienIfMatch", carID, lienID, revision)
+}
+
+// GetStolenCars ...
+func (o *Offline) GetStolenCars() ([]*asset.Car, error) {
+	var cars []*asset.Car
+	err := o.evaluate(&cars, "registry:GetStolenCars")
+	return cars, err
+}
+
+// GetOdometerReadings ...
+func (o *Offline) GetOdometerReadings(carID string) ([]*asset.OdometerReading, error) {
+	if _, err := o.GetCar(carID); err != nil {
+		return nil, err
+	}
+
+	var readings []*asset.OdometerReading
+	err := o.evaluate(&readings, "registry:GetOdometerReadings", carID)
+	return readings, err
+}
+
+// GetServiceRecords ...
+func (o *Offline) GetServiceRecords(carID string) ([]*asset.ServiceRecord, error) {
+	if _, err := o.GetCar(carID); err != nil {
+		return nil, err
+	}
+
+	var records []*asset.ServiceRecord
+	err := o.evaluate(&records, "registry:GetServiceRecords", carID)
+	return records, err
+}
+
+// AddServiceRecord ...
+func (o *Offline) AddServiceRecord(record asset.ServiceRecord) (*asset.ServiceRecord, error) {
+	var created *asset.ServiceRecord
+	err := o.submit(&created, "registry:AddServiceRecord", record.CarID, record.Type, record.Date, record.DocumentHash)
+	return created, err
+}
+
+// GetAttachments ...
+func (o *Offline) GetAttachments(carID string) ([]*asset.Attachment, error) {
+	if _, err := o.GetCar(carID); err != nil {
+		return nil, err
+	}
+
+	var attachments []*asset.Attachment
+	err := o.evaluate(&attachments, "registry:GetAttachments", carID)
+	return attachments, err
+}
+
+// GetAttachment ...
+func (o *Offline) GetAttachment(carID, id string) (*asset.Attachment, error) {
+	var attachment *asset.Attachment
+	err := o.evaluate(&attachment, "registry:GetAttachment", carID, id)
+	return attachment, err
+}
+
+// AnchorAttachment ...
+func (o *Offline) AnchorAttachment(attachment asset.Attachment) (*asset.Attachment, error) {
+	var anchored *asset.Attachment
+	err := o.submit(&anchored, "registry:AnchorAttachment", attachment.CarID, attachment.Name, attachment.ContentType, attachment.Size, attachment.SHA256)
+	return anchored, err
+}
+
+// GetTransferApprovals ...
+func (o *Offline) GetTransferApprovals(carID string) ([]*asset.TransferApproval, error) {
+	if _, err := o.GetCar(carID); err != nil {
+		return nil, err
+	}
+
+	var approvals []*asset.TransferApproval
+	err := o.evaluate(&approvals, "transfer:GetTransferApprovals", carID)
+	return approvals, err
+}
+
+// ApproveTransfer ...
+func (o *Offline) ApproveTransfer(carID, owner, newOwner string, revision int) error {
+	return o.submit(nil, "transfer:ApproveTransferIfMatch", carID, owner, newOwner, revision)
+}
+
+// GetOwner ...
+func (o *Offline) GetOwner(id string) (*asset.Owner, error) {
+	var owner *asset.Owner
+	err := o.evaluate(&owner, "registry:GetOwner", id)
+	return owner, err
+}
+
+// ResolveOwner ...
+func (o *Offline) ResolveOwner(id string) (*asset.OwnerPII, error) {
+	var pii *asset.OwnerPII
+	err := o.evaluate(&pii, "registry:GetOwnerPII", id)
+	return pii, err
+}
+
+// CreateOwner ...
+func (o *Offline) CreateOwner(owner asset.OwnerRegistration) error {
+	transient, err := ownerTransient(owner)
+	if err != nil {
+		return err
+	}
+
+	return o.submitTransient(transient, nil, "registry:CreateOwner", owner.ID, owner.Type)
+}
+
+// UpdateOwner ...
+func (o *Offline) UpdateOwner(owner asset.OwnerRegistration) error {
+	transient, err := ownerTransient(owner)
+	if err != nil {
+		return err
+	}
+
+	return o.submitTransient(transient, nil, "registry:UpdateOwner", owner.ID, owner.Type)
+}
+
+// EraseOwner erases the personal data of the owner and purges its previous
+// versions from the ledger file
+func (o *Offline) EraseOwner(id string) error {
+	if err := o.submit(nil, "admin:EraseOwner", id); err != nil {
+		return err
+	}
+
+	return o.ledger.PurgePrivateData(chaincode.OwnerCollection, id)
+}
+
+// QueryAuditRecords ...
+func (o *Offline) QueryAuditRecords(query asset.AuditQuery, pageSize int, bookmark string) (*asset.AuditPage, error) {
+	queryJSON, err := json.Marshal(query)
+	if err != nil {
+		return nil, err
+	}
+
+	var page *asset.AuditPage
+	err = o.evaluate(&page, "admin:QueryAuditRecords", string(queryJSON), pageSize, bookmark)
+	return page, err
+}
+
+// ownerTransient passes the personal data of the owner out of the
+// transaction arguments, without an identity the linked one is kept. An
+// identity goes with a new random salt hashing it.
+func ownerTransient(owner asset.OwnerRegistration) (map[string][]byte, error) {
+	transient := map[string][]byte{"name": []byte(owner.Name)}
+	if owner.Identity == "" {
+		return transient, nil
+	}
+
+	salt := make([]byte, 32)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+
+	transient["identity"] = []byte(owner.Identity)
+	transient["salt"] = salt
+	return transient, nil
+}
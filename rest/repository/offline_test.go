@@ -0,0 +1,238 @@
+package repository
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yimialmonte/chaincode-cars/asset"
+	"github.com/yimialmonte/chaincode-cars/chaincode/memstub"
+	"github.com/yimialmonte/chaincode-cars/rest/handler"
+)
+
+func TestOffline(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cars.db")
+	creator, err := memstub.NewIdentity("Org1MSP", "api", map[string]string{"role": "registrar"})
+	require.NoError(t, err)
+	lender, err := memstub.NewIdentity("Org1MSP", "api", map[string]string{"role": "lender"})
+	require.NoError(t, err)
+
+	store, err := NewOffline(path, creator)
+	require.NoError(t, err)
+
+	cars, err := store.GetCars()
+	require.NoError(t, err)
+	assert.Len(t, cars, 2, "a new ledger is seeded")
+
+	report, err := store.CreateCars([]asset.Car{{ID: "31", Brand: "Kia", Owner: "Ana", Year: 2018}})
+	require.NoError(t, err)
+	assert.False(t, report.Created)
+	assert.Equal(t, "owner does not exist ID: Ana", report.Items[0].Error)
+
+	for _, id := range []string{"Ana", "Max"} {
+		require.NoError(t, store.CreateOwner(asset.OwnerRegistration{ID: id, Name: id, Type: asset.OwnerPerson}))
+	}
+
+	err = store.CreateOwner(asset.OwnerRegistration{ID: "Max", Name: "Max", Type: asset.OwnerPerson})
+	assert.True(t, errors.Is(err, handler.ErrOwnerExists))
+
+	err = store.TransferCart("12", "Nobody", 0)
+	assert.True(t, errors.Is(err, handler.ErrOwnerNotFound))
+
+	report, err = store.CreateCars([]asset.Car{{ID: "31", Brand: "Kia", Owner: "Ana", Year: 2018}})
+	require.NoError(t, err)
+	assert.True(t, report.Created)
+
+	require.NoError(t, store.TransferCart("31", "Max", 0))
+
+	err = store.TransferCart("31", "Peter", 0)
+	assert.True(t, errors.Is(err, handler.ErrPreconditionFailed))
+
+	_, err = store.GetCar("99")
+	assert.True(t, errors.Is(err, handler.ErrCarNotFound))
+	require.NoError(t, store.Close())
+
+	store, err = NewOffline(path, creator)
+	require.NoError(t, err)
+	defer store.Close()
+
+	car, err := store.GetCar("31")
+	require.NoError(t, err)
+	assert.Equal(t, &asset.Car{ID: "31", Brand: "Kia", Owner: "Max", Year: 2018, TransfersCount: 1, Revision: 1}, car)
+
+	history, err := store.GetCarHistory("31")
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	assert.Equal(t, car, history[0].Car)
+	assert.Equal(t, "Ana", history[1].Car.Owner)
+	assert.True(t, history[0].BlockNumber > history[1].BlockNumber)
+
+	cars, err = store.GetCars()
+	require.NoError(t, err)
+	assert.Len(t, cars, 3, "the ledger is not seeded twice")
+
+	store.ledger.SetCreator(lender)
+	_, err = store.PlaceLien("31", 5000, 0)
+	assert.True(t, errors.Is(err, handler.ErrPreconditionFailed), "the car is at revision 1")
+
+	lien, err := store.PlaceLien("31", 5000, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "Org1MSP", lien.Lender)
+
+	store.ledger.SetCreator(creator)
+	err = store.TransferCart("31", "Peter", 2)
+	assert.EqualError(t, err, "unable to process transaction, car 31 has 1 active liens")
+
+	store.ledger.SetCreator(lender)
+
+	err = store.ReleaseLien("31", "unknown", 2)
+	assert.True(t, errors.Is(err, handler.ErrLienNotFound))
+	err = store.ReleaseLien("31", lien.ID, 1)
+	assert.True(t, errors.Is(err, handler.ErrPreconditionFailed))
+	require.NoError(t, store.ReleaseLien("31", lien.ID, 2))
+
+	car, err = store.GetCar("31")
+	require.NoError(t, err)
+
+	year := 2015
+	cars, err = store.QueryCars(asset.CarQuery{MinYear: &year})
+	require.NoError(t, err)
+	assert.Equal(t, []*asset.Car{car}, cars)
+
+	_, err = store.CreateCar(asset.Car{ID: "31", Brand: "Kia", Owner: "Ana"})
+	assert.True(t, errors.Is(err, handler.ErrCarExists))
+
+	_, err = store.CreateCar(asset.Car{ID: "32", Brand: "Kia", Owner: "Nobody"})
+	assert.True(t, errors.Is(err, handler.ErrInvalidCar))
+	assert.EqualError(t, err, "invalid car, owner does not exist ID: Nobody")
+
+	created, err := store.CreateCar(asset.Car{ID: "32", Brand: "Kia", Owner: "Ana", Revision: 7})
+	require.NoError(t, err)
+	assert.Equal(t, &asset.Car{ID: "32", Brand: "Kia", Owner: "Ana"}, created, "the car is read back from the ledger")
+}
+
+func TestOfflineUpdateOwner(t *testing.T) {
+	creator, err := memstub.NewIdentity("Org1MSP", "api", nil)
+	require.NoError(t, err)
+
+	var clientID string
+	require.NoError(t, memstub.New(memstub.WithCreator(creator)).Run(func(ctx contractapi.TransactionContextInterface) (err error) {
+		clientID, err = ctx.GetClientIdentity().GetID()
+		return err
+	}))
+
+	store, err := NewOffline(filepath.Join(t.TempDir(), "cars.db"), creator)
+	require.NoError(t, err)
+	defer store.Close()
+
+	require.NoError(t, store.CreateOwner(asset.OwnerRegistration{ID: "o-1", Name: "Ana", Type: asset.OwnerPerson, Identity: clientID}))
+	require.NoError(t, store.UpdateOwner(asset.OwnerRegistration{ID: "o-1", Name: "Ana García", Type: asset.OwnerPerson}))
+
+	pii, err := store.ResolveOwner("o-1")
+	require.NoError(t, err)
+	assert.Equal(t, "Ana García", pii.Name)
+	assert.Equal(t, clientID, pii.Identity, "a name-only update keeps the linked identity")
+}
+
+func TestOfflineEraseOwner(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cars.db")
+	creator, err := memstub.NewIdentity("Org1MSP", "api", map[string]string{"role": "privacy-officer"})
+	require.NoError(t, err)
+
+	store, err := NewOffline(path, creator)
+	require.NoError(t, err)
+	defer store.Close()
+
+	require.NoError(t, store.CreateOwner(asset.OwnerRegistration{ID: "o-1", Name: "Ana García", Type: asset.OwnerPerson}))
+
+	pii, err := store.ResolveOwner("o-1")
+	require.NoError(t, err)
+	assert.Equal(t, "Ana García", pii.Name)
+
+	err = store.EraseOwner("o-2")
+	assert.True(t, errors.Is(err, handler.ErrOwnerNotFound))
+
+	require.NoError(t, store.EraseOwner("o-1"))
+
+	owner, err := store.GetOwner("o-1")
+	require.NoError(t, err)
+	assert.True(t, owner.Erased)
+
+	file, err := memstub.OpenFileStore(path)
+	require.NoError(t, err)
+	defer file.Close()
+
+	blocks, err := file.Load()
+	require.NoError(t, err)
+	for _, block := range blocks {
+		for _, write := range block.PrivateWrites["ownerPII"] {
+			assert.NotContains(t, string(write.Value), "Ana", "the name is purged from the file")
+		}
+	}
+}
+
+func TestOfflineQueryAuditRecords(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cars.db")
+	auditor, err := memstub.NewIdentity("Org1MSP", "api", map[string]string{"role": "auditor"})
+	require.NoError(t, err)
+	registrar, err := memstub.NewIdentity("Org1MSP", "api", map[string]string{"role": "registrar"})
+	require.NoError(t, err)
+
+	store, err := NewOffline(path, registrar)
+	require.NoError(t, err)
+	defer store.Close()
+
+	seeded, err := store.GetCar("22")
+	require.NoError(t, err)
+	require.NoError(t, store.TransferCart("12", seeded.Owner, 0))
+	store.ledger.SetCreator(auditor)
+	_, err = store.GetCar("12")
+	require.NoError(t, err)
+
+	record, err := store.GetCarRecord("12")
+	require.NoError(t, err)
+
+	page, err := store.QueryAuditRecords(asset.AuditQuery{CarID: "12"}, 10, "")
+	require.NoError(t, err)
+	require.Len(t, page.Records, 1, "the evaluated GetCar is not committed")
+	assert.Equal(t, record.TxID, page.Records[0].TxID)
+	assert.Equal(t, "transfer:TransferCarIfMatch", page.Records[0].Function)
+	assert.Equal(t, "Org1MSP", page.Records[0].CallerMSP)
+
+	page, err = store.QueryAuditRecords(asset.AuditQuery{MSP: "Org1MSP"}, 10, "")
+	require.NoError(t, err)
+	var functions []string
+	for _, record := range page.Records {
+		functions = append(functions, record.Function)
+	}
+	assert.ElementsMatch(t, []string{"admin:InitLedger", "transfer:TransferCarIfMatch"}, functions, "the seeding is audited")
+
+	page, err = store.QueryAuditRecords(asset.AuditQuery{CarID: "22"}, 10, "")
+	require.NoError(t, err)
+	assert.Empty(t, page.Records)
+
+	dealer, err := memstub.NewIdentity("Org1MSP", "dealer", nil)
+	require.NoError(t, err)
+	store.ledger.SetCreator(dealer)
+	_, err = store.QueryAuditRecords(asset.AuditQuery{}, 10, "")
+	assert.True(t, errors.Is(err, handler.ErrForbidden))
+}
+
+func TestOpen(t *testing.T) {
+	store, err := Open(Config{})
+	require.NoError(t, err)
+	assert.IsType(t, &Car{}, store)
+
+	_, err = Open(Config{Backend: "couchdb"})
+	assert.EqualError(t, err, "unknown CARS_BACKEND couchdb")
+
+	store, err = Open(Config{Backend: "offline", LedgerFile: filepath.Join(t.TempDir(), "cars.db"), OfflineRole: "auditor"})
+	require.NoError(t, err)
+	defer store.(*Offline).Close()
+
+	_, err = store.QueryAuditRecords(asset.AuditQuery{}, 10, "")
+	assert.NoError(t, err, "the identity has the role")
+}
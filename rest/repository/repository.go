@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/yimialmonte/chaincode-cars/chaincode/memstub"
+	"github.com/yimialmonte/chaincode-cars/rest/handler"
+)
+
+// Store is implemented by every repository backing the API
+type Store interface {
+	handler.CarStore
+	handler.CarImporter
+	handler.CarCreator
+	handler.CarPager
+	handler.CarSearcher
+	handler.CarRecorder
+	handler.CarHistorian
+	handler.LienStore
+	handler.StolenLister
+	handler.OdometerStore
+	handler.ServiceRecordStore
+	handler.AttachmentStore
+	handler.ApprovalStore
+	handler.OwnerStore
+	handler.AuditStore
+}
+
+// Config selects the repository, Backend is fabric or offline. The offline
+// one runs the chaincode in-process against LedgerFile as an identity of
+// OfflineMSP with the OfflineRole role attribute.
+type Config struct {
+	Backend     string
+	LedgerFile  string
+	OfflineMSP  string
+	OfflineRole string
+}
+
+// ConfigFromEnv reads CARS_BACKEND, CARS_LEDGER_FILE, CARS_OFFLINE_MSP and
+// CARS_OFFLINE_ROLE
+func ConfigFromEnv() Config {
+	return Config{
+		Backend:     os.Getenv("CARS_BACKEND"),
+		LedgerFile:  os.Getenv("CARS_LEDGER_FILE"),
+		OfflineMSP:  os.Getenv("CARS_OFFLINE_MSP"),
+		OfflineRole: os.Getenv("CARS_OFFLINE_ROLE"),
+	}
+}
+
+// Open returns the repository of the config, fabric by default, the
+// offline ledger is cars-ledger.db as an identity of Org1MSP by default
+func Open(config Config) (Store, error) {
+	switch config.Backend {
+	case "", "fabric":
+		return &Car{}, nil
+	case "offline":
+		path := config.LedgerFile
+		if path == "" {
+			path = "cars-ledger.db"
+		}
+
+		mspID := config.OfflineMSP
+		if mspID == "" {
+			mspID = "Org1MSP"
+		}
+
+		attrs := map[string]string{}
+		if config.OfflineRole != "" {
+			attrs["role"] = config.OfflineRole
+		}
+
+		creator, err := memstub.NewIdentity(mspID, "api", attrs)
+		if err != nil {
+			return nil, err
+		}
+
+		return NewOffline(path, creator)
+	default:
+		return nil, fmt.Errorf("unknown CARS_BACKEND %s", config.Backend)
+	}
+}
@@ -0,0 +1,908 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: cars.proto
+
+package rpc
+
+import (
+	context "context"
+	fmt "fmt"
+	proto "github.com/golang/protobuf/proto"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	math "math"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
+
+type CarEvent_Type int32
+
+const (
+	CarEvent_TYPE_UNSPECIFIED CarEvent_Type = 0
+	CarEvent_ADDED            CarEvent_Type = 1
+	CarEvent_MODIFIED         CarEvent_Type = 2
+	CarEvent_DELETED          CarEvent_Type = 3
+)
+
+var CarEvent_Type_name = map[int32]string{
+	0: "TYPE_UNSPECIFIED",
+	1: "ADDED",
+	2: "MODIFIED",
+	3: "DELETED",
+}
+
+var CarEvent_Type_value = map[string]int32{
+	"TYPE_UNSPECIFIED": 0,
+	"ADDED":            1,
+	"MODIFIED":         2,
+	"DELETED":          3,
+}
+
+func (x CarEvent_Type) String() string {
+	return proto.EnumName(CarEvent_Type_name, int32(x))
+}
+
+func (CarEvent_Type) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_8351e959ca30fd84, []int{9, 0}
+}
+
+// Share is the percentage of a car held by a co-owner.
+type Share struct {
+	Owner                string   `protobuf:"bytes,1,opt,name=owner,proto3" json:"owner,omitempty"`
+	Percent              int32    `protobuf:"varint,2,opt,name=percent,proto3" json:"percent,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Share) Reset()         { *m = Share{} }
+func (m *Share) String() string { return proto.CompactTextString(m) }
+func (*Share) ProtoMessage()    {}
+func (*Share) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8351e959ca30fd84, []int{0}
+}
+
+func (m *Share) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Share.Unmarshal(m, b)
+}
+func (m *Share) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Share.Marshal(b, m, deterministic)
+}
+func (m *Share) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Share.Merge(m, src)
+}
+func (m *Share) XXX_Size() int {
+	return xxx_messageInfo_Share.Size(m)
+}
+func (m *Share) XXX_DiscardUnknown() {
+	xxx_messageInfo_Share.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Share proto.InternalMessageInfo
+
+func (m *Share) GetOwner() string {
+	if m != nil {
+		return m.Owner
+	}
+	return ""
+}
+
+func (m *Share) GetPercent() int32 {
+	if m != nil {
+		return m.Percent
+	}
+	return 0
+}
+
+// Car is a car of the registry.
+type Car struct {
+	Id                   string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Brand                string   `protobuf:"bytes,2,opt,name=brand,proto3" json:"brand,omitempty"`
+	Owner                string   `protobuf:"bytes,3,opt,name=owner,proto3" json:"owner,omitempty"`
+	Owners               []*Share `protobuf:"bytes,4,rep,name=owners,proto3" json:"owners,omitempty"`
+	TransfersCount       int32    `protobuf:"varint,5,opt,name=transfers_count,json=transfersCount,proto3" json:"transfers_count,omitempty"`
+	Year                 int32    `protobuf:"varint,6,opt,name=year,proto3" json:"year,omitempty"`
+	Type                 string   `protobuf:"bytes,7,opt,name=type,proto3" json:"type,omitempty"`
+	EngineCc             int32    `protobuf:"varint,8,opt,name=engine_cc,json=engineCc,proto3" json:"engine_cc,omitempty"`
+	Axles                int32    `protobuf:"varint,9,opt,name=axles,proto3" json:"axles,omitempty"`
+	PayloadKg            int32    `protobuf:"varint,10,opt,name=payload_kg,json=payloadKg,proto3" json:"payload_kg,omitempty"`
+	ActiveLiens          int32    `protobuf:"varint,11,opt,name=active_liens,json=activeLiens,proto3" json:"active_liens,omitempty"`
+	Stolen               bool     `protobuf:"varint,12,opt,name=stolen,proto3" json:"stolen,omitempty"`
+	StolenReport         string   `protobuf:"bytes,13,opt,name=stolen_report,json=stolenReport,proto3" json:"stolen_report,omitempty"`
+	Revision             int32    `protobuf:"varint,14,opt,name=revision,proto3" json:"revision,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Car) Reset()         { *m = Car{} }
+func (m *Car) String() string { return proto.CompactTextString(m) }
+func (*Car) ProtoMessage()    {}
+func (*Car) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8351e959ca30fd84, []int{1}
+}
+
+func (m *Car) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Car.Unmarshal(m, b)
+}
+func (m *Car) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Car.Marshal(b, m, deterministic)
+}
+func (m *Car) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Car.Merge(m, src)
+}
+func (m *Car) XXX_Size() int {
+	return xxx_messageInfo_Car.Size(m)
+}
+func (m *Car) XXX_DiscardUnknown() {
+	xxx_messageInfo_Car.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Car proto.InternalMessageInfo
+
+func (m *Car) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *Car) GetBrand() string {
+	if m != nil {
+		return m.Brand
+	}
+	return ""
+}
+
+func (m *Car) GetOwner() string {
+	if m != nil {
+		return m.Owner
+	}
+	return ""
+}
+
+func (m *Car) GetOwners() []*Share {
+	if m != nil {
+		return m.Owners
+	}
+	return nil
+}
+
+func (m *Car) GetTransfersCount() int32 {
+	if m != nil {
+		return m.TransfersCount
+	}
+	return 0
+}
+
+func (m *Car) GetYear() int32 {
+	if m != nil {
+		return m.Year
+	}
+	return 0
+}
+
+func (m *Car) GetType() string {
+	if m != nil {
+		return m.Type
+	}
+	return ""
+}
+
+func (m *Car) GetEngineCc() int32 {
+	if m != nil {
+		return m.EngineCc
+	}
+	return 0
+}
+
+func (m *Car) GetAxles() int32 {
+	if m != nil {
+		return m.Axles
+	}
+	return 0
+}
+
+func (m *Car) GetPayloadKg() int32 {
+	if m != nil {
+		return m.PayloadKg
+	}
+	return 0
+}
+
+func (m *Car) GetActiveLiens() int32 {
+	if m != nil {
+		return m.ActiveLiens
+	}
+	return 0
+}
+
+func (m *Car) GetStolen() bool {
+	if m != nil {
+		return m.Stolen
+	}
+	return false
+}
+
+func (m *Car) GetStolenReport() string {
+	if m != nil {
+		return m.StolenReport
+	}
+	return ""
+}
+
+func (m *Car) GetRevision() int32 {
+	if m != nil {
+		return m.Revision
+	}
+	return 0
+}
+
+type GetCarsRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetCarsRequest) Reset()         { *m = GetCarsRequest{} }
+func (m *GetCarsRequest) String() string { return proto.CompactTextString(m) }
+func (*GetCarsRequest) ProtoMessage()    {}
+func (*GetCarsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8351e959ca30fd84, []int{2}
+}
+
+func (m *GetCarsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetCarsRequest.Unmarshal(m, b)
+}
+func (m *GetCarsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetCarsRequest.Marshal(b, m, deterministic)
+}
+func (m *GetCarsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetCarsRequest.Merge(m, src)
+}
+func (m *GetCarsRequest) XXX_Size() int {
+	return xxx_messageInfo_GetCarsRequest.Size(m)
+}
+func (m *GetCarsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetCarsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetCarsRequest proto.InternalMessageInfo
+
+type GetCarsResponse struct {
+	Cars                 []*Car   `protobuf:"bytes,1,rep,name=cars,proto3" json:"cars,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetCarsResponse) Reset()         { *m = GetCarsResponse{} }
+func (m *GetCarsResponse) String() string { return proto.CompactTextString(m) }
+func (*GetCarsResponse) ProtoMessage()    {}
+func (*GetCarsResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8351e959ca30fd84, []int{3}
+}
+
+func (m *GetCarsResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetCarsResponse.Unmarshal(m, b)
+}
+func (m *GetCarsResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetCarsResponse.Marshal(b, m, deterministic)
+}
+func (m *GetCarsResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetCarsResponse.Merge(m, src)
+}
+func (m *GetCarsResponse) XXX_Size() int {
+	return xxx_messageInfo_GetCarsResponse.Size(m)
+}
+func (m *GetCarsResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetCarsResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetCarsResponse proto.InternalMessageInfo
+
+func (m *GetCarsResponse) GetCars() []*Car {
+	if m != nil {
+		return m.Cars
+	}
+	return nil
+}
+
+type GetCarsByOwnerRequest struct {
+	Owner                string   `protobuf:"bytes,1,opt,name=owner,proto3" json:"owner,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetCarsByOwnerRequest) Reset()         { *m = GetCarsByOwnerRequest{} }
+func (m *GetCarsByOwnerRequest) String() string { return proto.CompactTextString(m) }
+func (*GetCarsByOwnerRequest) ProtoMessage()    {}
+func (*GetCarsByOwnerRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8351e959ca30fd84, []int{4}
+}
+
+func (m *GetCarsByOwnerRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetCarsByOwnerRequest.Unmarshal(m, b)
+}
+func (m *GetCarsByOwnerRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetCarsByOwnerRequest.Marshal(b, m, deterministic)
+}
+func (m *GetCarsByOwnerRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetCarsByOwnerRequest.Merge(m, src)
+}
+func (m *GetCarsByOwnerRequest) XXX_Size() int {
+	return xxx_messageInfo_GetCarsByOwnerRequest.Size(m)
+}
+func (m *GetCarsByOwnerRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetCarsByOwnerRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetCarsByOwnerRequest proto.InternalMessageInfo
+
+func (m *GetCarsByOwnerRequest) GetOwner() string {
+	if m != nil {
+		return m.Owner
+	}
+	return ""
+}
+
+type GetCarRequest struct {
+	Id                   string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetCarRequest) Reset()         { *m = GetCarRequest{} }
+func (m *GetCarRequest) String() string { return proto.CompactTextString(m) }
+func (*GetCarRequest) ProtoMessage()    {}
+func (*GetCarRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8351e959ca30fd84, []int{5}
+}
+
+func (m *GetCarRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetCarRequest.Unmarshal(m, b)
+}
+func (m *GetCarRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetCarRequest.Marshal(b, m, deterministic)
+}
+func (m *GetCarRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetCarRequest.Merge(m, src)
+}
+func (m *GetCarRequest) XXX_Size() int {
+	return xxx_messageInfo_GetCarRequest.Size(m)
+}
+func (m *GetCarRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetCarRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetCarRequest proto.InternalMessageInfo
+
+func (m *GetCarRequest) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+type TransferCartRequest struct {
+	Id    string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Owner string `protobuf:"bytes,2,opt,name=owner,proto3" json:"owner,omitempty"`
+	// revision is the revision of the car the transfer was decided on.
+	Revision             int32    `protobuf:"varint,3,opt,name=revision,proto3" json:"revision,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *TransferCartRequest) Reset()         { *m = TransferCartRequest{} }
+func (m *TransferCartRequest) String() string { return proto.CompactTextString(m) }
+func (*TransferCartRequest) ProtoMessage()    {}
+func (*TransferCartRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8351e959ca30fd84, []int{6}
+}
+
+func (m *TransferCartRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_TransferCartRequest.Unmarshal(m, b)
+}
+func (m *TransferCartRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_TransferCartRequest.Marshal(b, m, deterministic)
+}
+func (m *TransferCartRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TransferCartRequest.Merge(m, src)
+}
+func (m *TransferCartRequest) XXX_Size() int {
+	return xxx_messageInfo_TransferCartRequest.Size(m)
+}
+func (m *TransferCartRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_TransferCartRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TransferCartRequest proto.InternalMessageInfo
+
+func (m *TransferCartRequest) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *TransferCartRequest) GetOwner() string {
+	if m != nil {
+		return m.Owner
+	}
+	return ""
+}
+
+func (m *TransferCartRequest) GetRevision() int32 {
+	if m != nil {
+		return m.Revision
+	}
+	return 0
+}
+
+type TransferCartResponse struct {
+	Car                  *Car     `protobuf:"bytes,1,opt,name=car,proto3" json:"car,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *TransferCartResponse) Reset()         { *m = TransferCartResponse{} }
+func (m *TransferCartResponse) String() string { return proto.CompactTextString(m) }
+func (*TransferCartResponse) ProtoMessage()    {}
+func (*TransferCartResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8351e959ca30fd84, []int{7}
+}
+
+func (m *TransferCartResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_TransferCartResponse.Unmarshal(m, b)
+}
+func (m *TransferCartResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_TransferCartResponse.Marshal(b, m, deterministic)
+}
+func (m *TransferCartResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TransferCartResponse.Merge(m, src)
+}
+func (m *TransferCartResponse) XXX_Size() int {
+	return xxx_messageInfo_TransferCartResponse.Size(m)
+}
+func (m *TransferCartResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_TransferCartResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TransferCartResponse proto.InternalMessageInfo
+
+func (m *TransferCartResponse) GetCar() *Car {
+	if m != nil {
+		return m.Car
+	}
+	return nil
+}
+
+// WatchCarsRequest only watches the cars of the owner when it is set, a car
+// transferred away is DELETED.
+type WatchCarsRequest struct {
+	Owner                string   `protobuf:"bytes,1,opt,name=owner,proto3" json:"owner,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *WatchCarsRequest) Reset()         { *m = WatchCarsRequest{} }
+func (m *WatchCarsRequest) String() string { return proto.CompactTextString(m) }
+func (*WatchCarsRequest) ProtoMessage()    {}
+func (*WatchCarsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8351e959ca30fd84, []int{8}
+}
+
+func (m *WatchCarsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_WatchCarsRequest.Unmarshal(m, b)
+}
+func (m *WatchCarsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_WatchCarsRequest.Marshal(b, m, deterministic)
+}
+func (m *WatchCarsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_WatchCarsRequest.Merge(m, src)
+}
+func (m *WatchCarsRequest) XXX_Size() int {
+	return xxx_messageInfo_WatchCarsRequest.Size(m)
+}
+func (m *WatchCarsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_WatchCarsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_WatchCarsRequest proto.InternalMessageInfo
+
+func (m *WatchCarsRequest) GetOwner() string {
+	if m != nil {
+		return m.Owner
+	}
+	return ""
+}
+
+// CarEvent is a change of a car, the car is the last known version of a
+// DELETED one.
+type CarEvent struct {
+	Type                 CarEvent_Type `protobuf:"varint,1,opt,name=type,proto3,enum=cars.CarEvent_Type" json:"type,omitempty"`
+	Car                  *Car          `protobuf:"bytes,2,opt,name=car,proto3" json:"car,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
+	XXX_unrecognized     []byte        `json:"-"`
+	XXX_sizecache        int32         `json:"-"`
+}
+
+func (m *CarEvent) Reset()         { *m = CarEvent{} }
+func (m *CarEvent) String() string { return proto.CompactTextString(m) }
+func (*CarEvent) ProtoMessage()    {}
+func (*CarEvent) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8351e959ca30fd84, []int{9}
+}
+
+func (m *CarEvent) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CarEvent.Unmarshal(m, b)
+}
+func (m *CarEvent) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CarEvent.Marshal(b, m, deterministic)
+}
+func (m *CarEvent) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CarEvent.Merge(m, src)
+}
+func (m *CarEvent) XXX_Size() int {
+	return xxx_messageInfo_CarEvent.Size(m)
+}
+func (m *CarEvent) XXX_DiscardUnknown() {
+	xxx_messageInfo_CarEvent.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CarEvent proto.InternalMessageInfo
+
+func (m *CarEvent) GetType() CarEvent_Type {
+	if m != nil {
+		return m.Type
+	}
+	return CarEvent_TYPE_UNSPECIFIED
+}
+
+func (m *CarEvent) GetCar() *Car {
+	if m != nil {
+		return m.Car
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterEnum("cars.CarEvent_Type", CarEvent_Type_name, CarEvent_Type_value)
+	proto.RegisterType((*Share)(nil), "cars.Share")
+	proto.RegisterType((*Car)(nil), "cars.Car")
+	proto.RegisterType((*GetCarsRequest)(nil), "cars.GetCarsRequest")
+	proto.RegisterType((*GetCarsResponse)(nil), "cars.GetCarsResponse")
+	proto.RegisterType((*GetCarsByOwnerRequest)(nil), "cars.GetCarsByOwnerRequest")
+	proto.RegisterType((*GetCarRequest)(nil), "cars.GetCarRequest")
+	proto.RegisterType((*TransferCartRequest)(nil), "cars.TransferCartRequest")
+	proto.RegisterType((*TransferCartResponse)(nil), "cars.TransferCartResponse")
+	proto.RegisterType((*WatchCarsRequest)(nil), "cars.WatchCarsRequest")
+	proto.RegisterType((*CarEvent)(nil), "cars.CarEvent")
+}
+
+func init() { proto.RegisterFile("cars.proto", fileDescriptor_8351e959ca30fd84) }
+
+var fileDescriptor_8351e959ca30fd84 = []byte{
+	// 651 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x74, 0x54, 0xcd, 0x4e, 0xdb, 0x4a,
+	0x14, 0xbe, 0xb6, 0xf3, 0xe7, 0x93, 0x60, 0xa2, 0x21, 0xa0, 0xb9, 0x41, 0xe8, 0xe6, 0x9a, 0x05,
+	0xd9, 0x90, 0x20, 0x50, 0xdb, 0x45, 0x57, 0xc5, 0x76, 0x2b, 0x54, 0x5a, 0x90, 0x49, 0x85, 0xda,
+	0x4d, 0x34, 0x4c, 0xa6, 0x89, 0xd5, 0x30, 0x76, 0xc7, 0x03, 0xad, 0x1f, 0xa0, 0x6f, 0xd0, 0x37,
+	0xe9, 0x0b, 0x56, 0x9e, 0x71, 0x42, 0x1c, 0x85, 0xdd, 0x39, 0xdf, 0xf9, 0xe6, 0xfc, 0x7d, 0x47,
+	0x03, 0x40, 0x89, 0x48, 0x07, 0x89, 0x88, 0x65, 0x8c, 0x2a, 0xb9, 0xed, 0xbe, 0x82, 0xea, 0xcd,
+	0x8c, 0x08, 0x86, 0x3a, 0x50, 0x8d, 0x7f, 0x70, 0x26, 0xb0, 0xd1, 0x33, 0xfa, 0x76, 0xa8, 0x1d,
+	0x84, 0xa1, 0x9e, 0x30, 0x41, 0x19, 0x97, 0xd8, 0xec, 0x19, 0xfd, 0x6a, 0xb8, 0x70, 0xdd, 0x5f,
+	0x16, 0x58, 0x1e, 0x11, 0xc8, 0x01, 0x33, 0x9a, 0x14, 0x8f, 0xcc, 0x68, 0x92, 0xe7, 0xb9, 0x13,
+	0x84, 0x4f, 0x14, 0xdf, 0x0e, 0xb5, 0xf3, 0x94, 0xdd, 0x5a, 0xcd, 0x7e, 0x08, 0x35, 0x65, 0xa4,
+	0xb8, 0xd2, 0xb3, 0xfa, 0xcd, 0xd3, 0xe6, 0x40, 0xf5, 0xa7, 0x1a, 0x0a, 0x8b, 0x10, 0x3a, 0x82,
+	0x6d, 0x29, 0x08, 0x4f, 0xbf, 0x32, 0x91, 0x8e, 0x69, 0xfc, 0xc0, 0x25, 0xae, 0xaa, 0x56, 0x9c,
+	0x25, 0xec, 0xe5, 0x28, 0x42, 0x50, 0xc9, 0x18, 0x11, 0xb8, 0xa6, 0xa2, 0xca, 0xce, 0x31, 0x99,
+	0x25, 0x0c, 0xd7, 0x55, 0x59, 0x65, 0xa3, 0x7d, 0xb0, 0x19, 0x9f, 0x46, 0x9c, 0x8d, 0x29, 0xc5,
+	0x0d, 0x45, 0x6e, 0x68, 0xc0, 0xa3, 0x79, 0xa3, 0xe4, 0xe7, 0x9c, 0xa5, 0xd8, 0x56, 0x01, 0xed,
+	0xa0, 0x03, 0x80, 0x84, 0x64, 0xf3, 0x98, 0x4c, 0xc6, 0xdf, 0xa6, 0x18, 0x54, 0xc8, 0x2e, 0x90,
+	0xf7, 0x53, 0xf4, 0x3f, 0xb4, 0x08, 0x95, 0xd1, 0x23, 0x1b, 0xcf, 0x23, 0xc6, 0x53, 0xdc, 0x54,
+	0x84, 0xa6, 0xc6, 0x2e, 0x73, 0x08, 0xed, 0x41, 0x2d, 0x95, 0xf1, 0x9c, 0x71, 0xdc, 0xea, 0x19,
+	0xfd, 0x46, 0x58, 0x78, 0xe8, 0x10, 0xb6, 0xb4, 0x35, 0x16, 0x2c, 0x89, 0x85, 0xc4, 0x5b, 0xaa,
+	0xd3, 0x96, 0x06, 0x43, 0x85, 0xa1, 0x2e, 0x34, 0x04, 0x7b, 0x8c, 0xd2, 0x28, 0xe6, 0xd8, 0xd1,
+	0x0d, 0x2f, 0x7c, 0xb7, 0x0d, 0xce, 0x3b, 0x26, 0x3d, 0x22, 0xd2, 0x90, 0x7d, 0x7f, 0x60, 0xa9,
+	0x74, 0x4f, 0x60, 0x7b, 0x89, 0xa4, 0x49, 0xcc, 0x53, 0x86, 0x0e, 0x40, 0xa9, 0x8d, 0x0d, 0xb5,
+	0x66, 0x5b, 0xaf, 0xd9, 0x23, 0x22, 0xd4, 0x47, 0x70, 0x0c, 0xbb, 0xc5, 0x8b, 0xf3, 0xec, 0x2a,
+	0xdf, 0x7a, 0x91, 0x6a, 0xf3, 0x51, 0xb8, 0xff, 0xc1, 0x96, 0xa6, 0x2f, 0x68, 0x6b, 0x37, 0xe0,
+	0xde, 0xc2, 0xce, 0xa8, 0xd0, 0xc6, 0x23, 0x42, 0x3e, 0x43, 0x7b, 0xca, 0x6e, 0xae, 0x1e, 0xc5,
+	0xea, 0xb0, 0xd6, 0xda, 0xb0, 0x67, 0xd0, 0x29, 0x27, 0x2e, 0xe6, 0xdb, 0x07, 0x8b, 0x12, 0xdd,
+	0x65, 0x69, 0xbc, 0x1c, 0x75, 0xfb, 0xd0, 0xbe, 0x25, 0x92, 0xce, 0x56, 0x76, 0xf4, 0xcc, 0x60,
+	0xbf, 0x0d, 0x68, 0x78, 0x44, 0x04, 0x8f, 0x8c, 0x4b, 0x74, 0x54, 0x9c, 0x4e, 0xce, 0x70, 0x4e,
+	0x77, 0x96, 0x49, 0x55, 0x74, 0x30, 0xca, 0x12, 0xb6, 0xbc, 0x27, 0x55, 0xdc, 0xdc, 0x58, 0xfc,
+	0x1c, 0x2a, 0x39, 0x15, 0x75, 0xa0, 0x3d, 0xfa, 0x7c, 0x1d, 0x8c, 0x3f, 0x7d, 0xbc, 0xb9, 0x0e,
+	0xbc, 0x8b, 0xb7, 0x17, 0x81, 0xdf, 0xfe, 0x07, 0xd9, 0x50, 0x7d, 0xe3, 0xfb, 0x81, 0xdf, 0x36,
+	0x50, 0x0b, 0x1a, 0x1f, 0xae, 0x7c, 0x1d, 0x30, 0x51, 0x13, 0xea, 0x7e, 0x70, 0x19, 0x8c, 0x02,
+	0xbf, 0x6d, 0x9d, 0xfe, 0x31, 0xa1, 0xa9, 0xb6, 0x3d, 0x8d, 0x52, 0x29, 0x32, 0xf4, 0x12, 0xea,
+	0x85, 0x5c, 0xa8, 0xa3, 0xcb, 0x95, 0x2f, 0xa0, 0xbb, 0xbb, 0x86, 0x16, 0x5b, 0xf2, 0xc1, 0x29,
+	0xcb, 0x8c, 0xf6, 0x4b, 0xc4, 0xb2, 0xf8, 0xcf, 0x65, 0xe9, 0x43, 0x4d, 0x43, 0x68, 0x67, 0x95,
+	0xb0, 0x78, 0xf5, 0xb4, 0x00, 0x14, 0x40, 0x6b, 0x55, 0x2d, 0xf4, 0xaf, 0x0e, 0x6d, 0x38, 0x8d,
+	0x6e, 0x77, 0x53, 0xa8, 0x28, 0xf8, 0x02, 0xec, 0xa5, 0x7e, 0x68, 0x4f, 0x13, 0xd7, 0x05, 0xed,
+	0x3a, 0x65, 0x7d, 0x4e, 0x8c, 0xf3, 0xe1, 0x97, 0xe3, 0x69, 0x24, 0x67, 0x0f, 0x77, 0x03, 0x1a,
+	0xdf, 0x0f, 0xb3, 0xe8, 0x3e, 0x22, 0xf3, 0xfb, 0x98, 0x4b, 0x36, 0xa4, 0x33, 0x12, 0x71, 0x1a,
+	0x4f, 0xd8, 0x71, 0xfe, 0x66, 0x28, 0x12, 0xfa, 0x5a, 0x24, 0xf4, 0xae, 0xa6, 0xfe, 0xc5, 0xb3,
+	0xbf, 0x03, 0x00, 0xa3, 0x84, 0x4a, 0x0c, 0x25, 0x05, 0x00, 0x00,
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// CarRegistryClient is the client API for CarRegistry service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type CarRegistryClient interface {
+	// GetCars returns every car.
+	GetCars(ctx context.Context, in *GetCarsRequest, opts ...grpc.CallOption) (*GetCarsResponse, error)
+	// GetCarsByOwner returns the cars held by the owner.
+	GetCarsByOwner(ctx context.Context, in *GetCarsByOwnerRequest, opts ...grpc.CallOption) (*GetCarsResponse, error)
+	// GetCar returns the car, NOT_FOUND when it does not exist.
+	GetCar(ctx context.Context, in *GetCarRequest, opts ...grpc.CallOption) (*Car, error)
+	// TransferCart transfers the car when it is still at the revision,
+	// FAILED_PRECONDITION otherwise.
+	TransferCart(ctx context.Context, in *TransferCartRequest, opts ...grpc.CallOption) (*TransferCartResponse, error)
+	// WatchCars sends the cars as ADDED events, then an event for every car
+	// added, modified or deleted until the call is cancelled.
+	WatchCars(ctx context.Context, in *WatchCarsRequest, opts ...grpc.CallOption) (CarRegistry_WatchCarsClient, error)
+}
+
+type carRegistryClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewCarRegistryClient(cc *grpc.ClientConn) CarRegistryClient {
+	return &carRegistryClient{cc}
+}
+
+func (c *carRegistryClient) GetCars(ctx context.Context, in *GetCarsRequest, opts ...grpc.CallOption) (*GetCarsResponse, error) {
+	out := new(GetCarsResponse)
+	err := c.cc.Invoke(ctx, "/cars.CarRegistry/GetCars", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *carRegistryClient) GetCarsByOwner(ctx context.Context, in *GetCarsByOwnerRequest, opts ...grpc.CallOption) (*GetCarsResponse, error) {
+	out := new(GetCarsResponse)
+	err := c.cc.Invoke(ctx, "/cars.CarRegistry/GetCarsByOwner", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *carRegistryClient) GetCar(ctx context.Context, in *GetCarRequest, opts ...grpc.CallOption) (*Car, error) {
+	out := new(Car)
+	err := c.cc.Invoke(ctx, "/cars.CarRegistry/GetCar", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *carRegistryClient) TransferCart(ctx context.Context, in *TransferCartRequest, opts ...grpc.CallOption) (*TransferCartResponse, error) {
+	out := new(TransferCartResponse)
+	err := c.cc.Invoke(ctx, "/cars.CarRegistry/TransferCart", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *carRegistryClient) WatchCars(ctx context.Context, in *WatchCarsRequest, opts ...grpc.CallOption) (CarRegistry_WatchCarsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_CarRegistry_serviceDesc.Streams[0], "/cars.CarRegistry/WatchCars", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &carRegistryWatchCarsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type CarRegistry_WatchCarsClient interface {
+	Recv() (*CarEvent, error)
+	grpc.ClientStream
+}
+
+type carRegistryWatchCarsClient struct {
+	grpc.ClientStream
+}
+
+func (x *carRegistryWatchCarsClient) Recv() (*CarEvent, error) {
+	m := new(CarEvent)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// CarRegistryServer is the server API for CarRegistry service.
+type CarRegistryServer interface {
+	// GetCars returns every car.
+	GetCars(context.Context, *GetCarsRequest) (*GetCarsResponse, error)
+	// GetCarsByOwner returns the cars held by the owner.
+	GetCarsByOwner(context.Context, *GetCarsByOwnerRequest) (*GetCarsResponse, error)
+	// GetCar returns the car, NOT_FOUND when it does not exist.
+	GetCar(context.Context, *GetCarRequest) (*Car, error)
+	// TransferCart transfers the car when it is still at the revision,
+	// FAILED_PRECONDITION otherwise.
+	TransferCart(context.Context, *TransferCartRequest) (*TransferCartResponse, error)
+	// WatchCars sends the cars as ADDED events, then an event for every car
+	// added, modified or deleted until the call is cancelled.
+	WatchCars(*WatchCarsRequest, CarRegistry_WatchCarsServer) error
+}
+
+// UnimplementedCarRegistryServer can be embedded to have forward compatible implementations.
+type UnimplementedCarRegistryServer struct {
+}
+
+func (*UnimplementedCarRegistryServer) GetCars(ctx context.Context, req *GetCarsRequest) (*GetCarsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetCars not implemented")
+}
+func (*UnimplementedCarRegistryServer) GetCarsByOwner(ctx context.Context, req *GetCarsByOwnerRequest) (*GetCarsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetCarsByOwner not implemented")
+}
+func (*UnimplementedCarRegistryServer) GetCar(ctx context.Context, req *GetCarRequest) (*Car, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetCar not implemented")
+}
+func (*UnimplementedCarRegistryServer) TransferCart(ctx context.Context, req *TransferCartRequest) (*TransferCartResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method TransferCart not implemented")
+}
+func (*UnimplementedCarRegistryServer) WatchCars(req *WatchCarsRequest, srv CarRegistry_WatchCarsServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchCars not implemented")
+}
+
+func RegisterCarRegistryServer(s *grpc.Server, srv CarRegistryServer) {
+	s.RegisterService(&_CarRegistry_serviceDesc, srv)
+}
+
+func _CarRegistry_GetCars_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetCarsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CarRegistryServer).GetCars(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/cars.CarRegistry/GetCars",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CarRegistryServer).GetCars(ctx, req.(*GetCarsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CarRegistry_GetCarsByOwner_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetCarsByOwnerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CarRegistryServer).GetCarsByOwner(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/cars.CarRegistry/GetCarsByOwner",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CarRegistryServer).GetCarsByOwner(ctx, req.(*GetCarsByOwnerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CarRegistry_GetCar_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetCarRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CarRegistryServer).GetCar(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/cars.CarRegistry/GetCar",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CarRegistryServer).GetCar(ctx, req.(*GetCarRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CarRegistry_TransferCart_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TransferCartRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CarRegistryServer).TransferCart(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/cars.CarRegistry/TransferCart",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CarRegistryServer).TransferCart(ctx, req.(*TransferCartRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CarRegistry_WatchCars_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchCarsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(CarRegistryServer).WatchCars(m, &carRegistryWatchCarsServer{stream})
+}
+
+type CarRegistry_WatchCarsServer interface {
+	Send(*CarEvent) error
+	grpc.ServerStream
+}
+
+type carRegistryWatchCarsServer struct {
+	grpc.ServerStream
+}
+
+func (x *carRegistryWatchCarsServer) Send(m *CarEvent) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+var _CarRegistry_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "cars.CarRegistry",
+	HandlerType: (*CarRegistryServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetCars",
+			Handler:    _CarRegistry_GetCars_Handler,
+		},
+		{
+			MethodName: "GetCarsByOwner",
+			Handler:    _CarRegistry_GetCarsByOwner_Handler,
+		},
+		{
+			MethodName: "GetCar",
+			Handler:    _CarRegistry_GetCar_Handler,
+		},
+		{
+			MethodName: "TransferCart",
+			Handler:    _CarRegistry_TransferCart_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchCars",
+			Handler:       _CarRegistry_WatchCars_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "cars.proto",
+}
@@ -0,0 +1,224 @@
+// Package rpc serves the car registry over gRPC, cars.pb.go is generated
+// from cars.proto with protoc --go_out=plugins=grpc,paths=source_relative:. in
+// this directory
+package rpc
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/yimialmonte/chaincode-cars/asset"
+	"github.com/yimialmonte/chaincode-cars/rest/handler"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+)
+
+// ServiceName is the full name of the CarRegistry service
+const ServiceName = "cars.CarRegistry"
+
+// defaultPollInterval is how often WatchCars reads the cars without a
+// PollInterval
+const defaultPollInterval = 2 * time.Second
+
+// Server implements CarRegistryServer over the store of the REST API. The
+// ledger has no change feed, WatchCars reads the cars every PollInterval.
+type Server struct {
+	Store        handler.CarStore
+	PollInterval time.Duration
+}
+
+// NewGRPCServer returns a gRPC server with the registry, the health service
+// and the server reflection
+func NewGRPCServer(registry *Server, opts ...grpc.ServerOption) *grpc.Server {
+	s := grpc.NewServer(opts...)
+	RegisterCarRegistryServer(s, registry)
+
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus(ServiceName, healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(s, healthServer)
+
+	reflection.Register(s)
+	return s
+}
+
+// GetCars ...
+func (s *Server) GetCars(ctx context.Context, req *GetCarsRequest) (*GetCarsResponse, error) {
+	cars, err := s.Store.GetCars()
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	return &GetCarsResponse{Cars: toProtoCars(cars)}, nil
+}
+
+// GetCarsByOwner ...
+func (s *Server) GetCarsByOwner(ctx context.Context, req *GetCarsByOwnerRequest) (*GetCarsResponse, error) {
+	if strings.TrimSpace(req.Owner) == "" {
+		return nil, status.Error(codes.InvalidArgument, "Supply the owner")
+	}
+
+	cars, err := s.Store.GetCarsByOwner(req.Owner)
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	return &GetCarsResponse{Cars: toProtoCars(cars)}, nil
+}
+
+// GetCar ...
+func (s *Server) GetCar(ctx context.Context, req *GetCarRequest) (*Car, error) {
+	car, err := s.Store.GetCar(req.Id)
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	return toProtoCar(car), nil
+}
+
+// TransferCart transfers the car and returns its new version
+func (s *Server) TransferCart(ctx context.Context, req *TransferCartRequest) (*TransferCartResponse, error) {
+	if strings.TrimSpace(req.Id) == "" || strings.TrimSpace(req.Owner) == "" {
+		return nil, status.Error(codes.InvalidArgument, "Supply Car ID and Owner")
+	}
+
+	if req.Revision < 0 {
+		return nil, status.Error(codes.InvalidArgument, "invalid revision")
+	}
+
+	if err := s.Store.TransferCart(req.Id, req.Owner, int(req.Revision)); err != nil {
+		return nil, statusError(err)
+	}
+
+	car, err := s.Store.GetCar(req.Id)
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	return &TransferCartResponse{Car: toProtoCar(car)}, nil
+}
+
+// WatchCars sends the cars, then the changes found at every poll
+func (s *Server) WatchCars(req *WatchCarsRequest, stream CarRegistry_WatchCarsServer) error {
+	interval := s.PollInterval
+	if interval <= 0 {
+		interval = defaultPollInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	known := map[string]*asset.Car{}
+	for {
+		var cars []*asset.Car
+		var err error
+		if req.Owner != "" {
+			cars, err = s.Store.GetCarsByOwner(req.Owner)
+		} else {
+			cars, err = s.Store.GetCars()
+		}
+		if err != nil {
+			return statusError(err)
+		}
+
+		for _, event := range carEvents(known, cars) {
+			if err := stream.Send(event); err != nil {
+				return err
+			}
+		}
+
+		select {
+		case <-stream.Context().Done():
+			return status.FromContextError(stream.Context().Err()).Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// carEvents compares the cars with the known ones, known is updated to the
+// cars
+func carEvents(known map[string]*asset.Car, cars []*asset.Car) []*CarEvent {
+	var events []*CarEvent
+	seen := map[string]bool{}
+	for _, car := range cars {
+		seen[car.ID] = true
+		previous, ok := known[car.ID]
+		switch {
+		case !ok:
+			events = append(events, &CarEvent{Type: CarEvent_ADDED, Car: toProtoCar(car)})
+		case !reflect.DeepEqual(previous, car):
+			events = append(events, &CarEvent{Type: CarEvent_MODIFIED, Car: toProtoCar(car)})
+		}
+		known[car.ID] = car
+	}
+
+	var deleted []string
+	for id := range known {
+		if !seen[id] {
+			deleted = append(deleted, id)
+		}
+	}
+	sort.Strings(deleted)
+
+	for _, id := range deleted {
+		events = append(events, &CarEvent{Type: CarEvent_DELETED, Car: toProtoCar(known[id])})
+		delete(known, id)
+	}
+
+	return events
+}
+
+// statusError maps the store errors to the status codes, like the REST API
+// maps them to the HTTP status codes
+func statusError(err error) error {
+	code := codes.Internal
+	switch {
+	case errors.Is(err, handler.ErrCarNotFound), errors.Is(err, handler.ErrOwnerNotFound):
+		code = codes.NotFound
+	case errors.Is(err, handler.ErrPreconditionFailed):
+		code = codes.FailedPrecondition
+	case errors.Is(err, handler.ErrForbidden):
+		code = codes.PermissionDenied
+	}
+
+	return status.Error(code, err.Error())
+}
+
+func toProtoCars(cars []*asset.Car) []*Car {
+	protoCars := make([]*Car, 0, len(cars))
+	for _, car := range cars {
+		protoCars = append(protoCars, toProtoCar(car))
+	}
+	return protoCars
+}
+
+func toProtoCar(car *asset.Car) *Car {
+	protoCar := &Car{
+		Id:             car.ID,
+		Brand:          car.Brand,
+		Owner:          car.Owner,
+		TransfersCount: int32(car.TransfersCount),
+		Year:           int32(car.Year),
+		Type:           car.Type,
+		EngineCc:       int32(car.EngineCC),
+		Axles:          int32(car.Axles),
+		PayloadKg:      int32(car.PayloadKg),
+		ActiveLiens:    int32(car.ActiveLiens),
+		Stolen:         car.Stolen,
+		StolenReport:   car.StolenReport,
+		Revision:       int32(car.Revision),
+	}
+
+	for _, share := range car.Owners {
+		protoCar.Owners = append(protoCar.Owners, &Share{Owner: share.Owner, Percent: int32(share.Percent)})
+	}
+
+	return protoCar
+}
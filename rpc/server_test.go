@@ -0,0 +1,188 @@
+package rpc
+
+import (
+	"context"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yimialmonte/chaincode-cars/asset"
+	"github.com/yimialmonte/chaincode-cars/chaincode/memstub"
+	"github.com/yimialmonte/chaincode-cars/rest/repository"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// dial serves the registry over an in-memory listener
+func dial(t *testing.T) (*grpc.ClientConn, *repository.Offline) {
+	creator, err := memstub.NewIdentity("Org1MSP", "api", map[string]string{"role": "registrar"})
+	require.NoError(t, err)
+
+	store, err := repository.NewOffline(filepath.Join(t.TempDir(), "cars.db"), creator)
+	require.NoError(t, err)
+	t.Cleanup(func() { store.Close() })
+
+	listener := bufconn.Listen(1 << 20)
+	server := NewGRPCServer(&Server{Store: store, PollInterval: 10 * time.Millisecond})
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.DialContext(context.Background(), "bufnet", grpc.WithInsecure(),
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return listener.Dial() }))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return conn, store
+}
+
+// seededOwner returns the pseudonymous owner of a car seeded by InitLedger
+func seededOwner(t *testing.T, store *repository.Offline, carID string) string {
+	car, err := store.GetCar(carID)
+	require.NoError(t, err)
+	return car.Owner
+}
+
+func TestCarRegistry(t *testing.T) {
+	conn, _ := dial(t)
+	c := NewCarRegistryClient(conn)
+	ctx := context.Background()
+
+	cars, err := c.GetCars(ctx, &GetCarsRequest{})
+	require.NoError(t, err)
+	assert.Len(t, cars.Cars, 2)
+
+	car, err := c.GetCar(ctx, &GetCarRequest{Id: "12"})
+	require.NoError(t, err)
+	assert.Equal(t, "Toyota", car.Brand)
+	juan := car.Owner
+	seeded, err := c.GetCar(ctx, &GetCarRequest{Id: "22"})
+	require.NoError(t, err)
+	marcos := seeded.Owner
+
+	transferred, err := c.TransferCart(ctx, &TransferCartRequest{Id: "12", Owner: marcos, Revision: car.Revision})
+	require.NoError(t, err)
+	assert.Equal(t, marcos, transferred.Car.Owner)
+	assert.Equal(t, int32(1), transferred.Car.Revision)
+	assert.Equal(t, int32(1), transferred.Car.TransfersCount)
+
+	owned, err := c.GetCarsByOwner(ctx, &GetCarsByOwnerRequest{Owner: marcos})
+	require.NoError(t, err)
+	assert.Len(t, owned.Cars, 2)
+
+	tests := []struct {
+		call            func() error
+		expectedCode    codes.Code
+		expectedMessage string
+	}{
+		{func() error {
+			_, err := c.GetCar(ctx, &GetCarRequest{Id: "99"})
+			return err
+		}, codes.NotFound, "car not found"},
+		{func() error {
+			_, err := c.GetCarsByOwner(ctx, &GetCarsByOwnerRequest{})
+			return err
+		}, codes.InvalidArgument, "Supply the owner"},
+		{func() error {
+			_, err := c.TransferCart(ctx, &TransferCartRequest{Id: "12"})
+			return err
+		}, codes.InvalidArgument, "Supply Car ID and Owner"},
+		{func() error {
+			_, err := c.TransferCart(ctx, &TransferCartRequest{Id: "12", Owner: juan, Revision: -1})
+			return err
+		}, codes.InvalidArgument, "invalid revision"},
+		{func() error {
+			_, err := c.TransferCart(ctx, &TransferCartRequest{Id: "12", Owner: juan, Revision: 0})
+			return err
+		}, codes.FailedPrecondition, "car was modified, precondition failed"},
+		{func() error {
+			_, err := c.TransferCart(ctx, &TransferCartRequest{Id: "12", Owner: "Nobody", Revision: 1})
+			return err
+		}, codes.NotFound, "owner not found"},
+	}
+
+	for _, test := range tests {
+		s, ok := status.FromError(test.call())
+		require.True(t, ok)
+		assert.Equal(t, test.expectedCode, s.Code(), s.Message())
+		assert.Contains(t, s.Message(), test.expectedMessage)
+	}
+}
+
+func TestWatchCars(t *testing.T) {
+	conn, store := dial(t)
+	c := NewCarRegistryClient(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	juan, marcos := seededOwner(t, store, "12"), seededOwner(t, store, "22")
+	all, err := c.WatchCars(ctx, &WatchCarsRequest{})
+	require.NoError(t, err)
+	juans, err := c.WatchCars(ctx, &WatchCarsRequest{Owner: juan})
+	require.NoError(t, err)
+
+	expectEvent := func(stream CarRegistry_WatchCarsClient, eventType CarEvent_Type, id, owner string) {
+		event, err := stream.Recv()
+		require.NoError(t, err)
+		assert.Equal(t, eventType, event.Type)
+		assert.Equal(t, id, event.Car.Id)
+		assert.Equal(t, owner, event.Car.Owner)
+	}
+
+	expectEvent(all, CarEvent_ADDED, "12", juan)
+	expectEvent(all, CarEvent_ADDED, "22", marcos)
+	expectEvent(juans, CarEvent_ADDED, "12", juan)
+
+	require.NoError(t, store.TransferCart("12", marcos, 0))
+	expectEvent(all, CarEvent_MODIFIED, "12", marcos)
+	expectEvent(juans, CarEvent_DELETED, "12", juan)
+
+	report, err := store.CreateCars([]asset.Car{{ID: "31", Brand: "Kia", Owner: juan, Year: 2018}})
+	require.NoError(t, err)
+	require.True(t, report.Created)
+	expectEvent(all, CarEvent_ADDED, "31", juan)
+	expectEvent(juans, CarEvent_ADDED, "31", juan)
+
+	cancel()
+	_, err = all.Recv()
+	assert.Equal(t, codes.Canceled, status.Code(err))
+}
+
+func TestHealthAndReflection(t *testing.T) {
+	conn, _ := dial(t)
+	ctx := context.Background()
+
+	health, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{Service: ServiceName})
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, health.Status)
+
+	stream, err := reflectionpb.NewServerReflectionClient(conn).ServerReflectionInfo(ctx)
+	require.NoError(t, err)
+	defer stream.CloseSend()
+
+	require.NoError(t, stream.Send(&reflectionpb.ServerReflectionRequest{
+		MessageRequest: &reflectionpb.ServerReflectionRequest_ListServices{},
+	}))
+	response, err := stream.Recv()
+	require.NoError(t, err)
+
+	var services []string
+	for _, service := range response.GetListServicesResponse().Service {
+		services = append(services, service.Name)
+	}
+	assert.Contains(t, services, ServiceName)
+	assert.Contains(t, services, "grpc.health.v1.Health")
+
+	require.NoError(t, stream.Send(&reflectionpb.ServerReflectionRequest{
+		MessageRequest: &reflectionpb.ServerReflectionRequest_FileContainingSymbol{FileContainingSymbol: ServiceName},
+	}))
+	response, err = stream.Recv()
+	require.NoError(t, err)
+	assert.NotEmpty(t, response.GetFileDescriptorResponse().GetFileDescriptorProto(), "the service descriptor is registered")
+}
@@ -1,20 +1,145 @@
 package main
 
 import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
 	"log"
+	"net"
 	"net/http"
+	"os"
 
 	"github.com/gorilla/mux"
+	"github.com/yimialmonte/chaincode-cars/certificate"
+	"github.com/yimialmonte/chaincode-cars/rest/blob"
 	"github.com/yimialmonte/chaincode-cars/rest/handler"
 	"github.com/yimialmonte/chaincode-cars/rest/repository"
+	"github.com/yimialmonte/chaincode-cars/rpc"
 )
 
+// newIssuer loads the certificate signing key from CARS_SIGNING_KEY, without
+// it a key is generated and certificates do not survive a restart
+func newIssuer() (*certificate.Issuer, error) {
+	id := os.Getenv("CARS_CERTIFICATE_ISSUER")
+	if id == "" {
+		id = "urn:chaincode-cars:api"
+	}
+
+	path := os.Getenv("CARS_SIGNING_KEY")
+	if path == "" {
+		log.Println("CARS_SIGNING_KEY is not set, using an ephemeral signing key")
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		return certificate.NewIssuer(id, key), nil
+	}
+
+	key, err := certificate.LoadKey(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return certificate.NewIssuer(id, key), nil
+}
+
+// attachmentsDir is the directory of the attachment blobs, from
+// CARS_ATTACHMENTS_DIR
+func attachmentsDir() string {
+	if dir := os.Getenv("CARS_ATTACHMENTS_DIR"); dir != "" {
+		return dir
+	}
+	return "attachments"
+}
+
+// newAuthorizer loads the API tokens from CARS_API_TOKENS, a JSON object of
+// the roles of each token, without it no caller has a role
+func newAuthorizer() (handler.TokenAuthorizer, error) {
+	auth := handler.TokenAuthorizer{}
+	tokens := os.Getenv("CARS_API_TOKENS")
+	if tokens == "" {
+		return auth, nil
+	}
+
+	if err := json.Unmarshal([]byte(tokens), &auth); err != nil {
+		return nil, fmt.Errorf("invalid CARS_API_TOKENS, %v", err)
+	}
+
+	return auth, nil
+}
+
+// grpcAddr is the address of the gRPC API, from CARS_GRPC_ADDR
+func grpcAddr() string {
+	if addr := os.Getenv("CARS_GRPC_ADDR"); addr != "" {
+		return addr
+	}
+	return ":9090"
+}
+
 func main() {
+	store, err := repository.Open(repository.ConfigFromEnv())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	issuer, err := newIssuer()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	blobs, err := blob.NewFileStore(attachmentsDir())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	auth, err := newAuthorizer()
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	route := mux.NewRouter()
+	jobs := handler.NewImportJobs()
+
+	route.Handle("/cars", &handler.GetAllCars{Store: store}).Methods(http.MethodGet)
+	route.Handle("/cars/owner/{id}", &handler.GetCarsOwner{Store: store}).Methods(http.MethodGet)
+	route.Handle("/cars/export", &handler.ExportCars{Store: store}).Methods(http.MethodGet)
+	route.Handle("/cars/search", &handler.SearchCars{Store: store}).Methods(http.MethodGet)
+	route.Handle("/cars/stolen", &handler.GetStolenCars{Store: store}).Methods(http.MethodGet)
+	route.Handle("/cars/{id}", &handler.GetCar{Store: store}).Methods(http.MethodGet)
+	route.Handle("/cars/{id}", &handler.CreateCar{Store: store}).Methods(http.MethodPut)
+	route.Handle("/cars/{id}/history", &handler.GetCarHistory{Store: store}).Methods(http.MethodGet)
+	route.Handle("/cars/{id}/certificate", &handler.GetCertificate{Store: store, Issuer: issuer}).Methods(http.MethodGet)
+	route.Handle("/certificates/key", &handler.GetCertificateKey{Issuer: issuer}).Methods(http.MethodGet)
+	route.Handle("/cars/{id}/liens", &handler.GetLiens{Store: store}).Methods(http.MethodGet)
+	route.Handle("/cars/{id}/liens", &handler.PlaceLien{Store: store}).Methods(http.MethodPost)
+	route.Handle("/cars/{id}/liens/{lienId}", &handler.ReleaseLien{Store: store}).Methods(http.MethodDelete)
+	route.Handle("/cars/{id}/approvals", &handler.GetTransferApprovals{Store: store}).Methods(http.MethodGet)
+	route.Handle("/cars/{id}/approvals", &handler.ApproveTransfer{Store: store}).Methods(http.MethodPost)
+	route.Handle("/cars/{id}/odometer", &handler.GetOdometer{Store: store}).Methods(http.MethodGet)
+	route.Handle("/cars/{id}/records", &handler.GetServiceRecords{Store: store}).Methods(http.MethodGet)
+	route.Handle("/cars/{id}/records", &handler.AddServiceRecord{Store: store}).Methods(http.MethodPost)
+	route.Handle("/cars/{id}/attachments", &handler.GetAttachments{Store: store}).Methods(http.MethodGet)
+	route.Handle("/cars/{id}/attachments", &handler.UploadAttachment{Store: store, Blobs: blobs}).Methods(http.MethodPost)
+	route.Handle("/cars/{id}/attachments/{attachmentId}", &handler.DownloadAttachment{Store: store, Blobs: blobs}).Methods(http.MethodGet)
+	route.Handle("/owners", &handler.CreateOwner{Store: store}).Methods(http.MethodPost)
+	route.Handle("/owners/{id}", &handler.GetOwner{Store: store, Auth: auth}).Methods(http.MethodGet)
+	route.Handle("/owners/{id}", &handler.UpdateOwner{Store: store}).Methods(http.MethodPut)
+	route.Handle("/owners/{id}/personal-data", &handler.EraseOwner{Store: store, Auth: auth}).Methods(http.MethodDelete)
+	route.Handle("/audit", &handler.GetAudit{Store: store, Auth: auth}).Methods(http.MethodGet)
+	route.Handle("/verify", &handler.VerifyCertificate{Store: store, Key: issuer.Key.Public().(ed25519.PublicKey)}).Methods(http.MethodPost)
+	route.Handle("/cars", &handler.TransferCarOwner{Store: store}).Methods(http.MethodPost)
+	route.Handle("/cars:import", &handler.ImportCars{Store: store, Jobs: jobs}).Methods(http.MethodPost)
+	route.Handle("/cars/imports/{id}", &handler.GetImportJob{Jobs: jobs}).Methods(http.MethodGet)
+
+	listener, err := net.Listen("tcp", grpcAddr())
+	if err != nil {
+		log.Fatal(err)
+	}
 
-	route.Handle("/cars", &handler.GetAllCars{Store: &repository.Car{}}).Methods(http.MethodGet)
-	route.Handle("/cars/owner/{id}", &handler.GetCarsOwner{Store: &repository.Car{}}).Methods(http.MethodGet)
-	route.Handle("/cars", &handler.TransferCarOwner{Store: &repository.Car{}}).Methods(http.MethodPost)
+	go func() {
+		log.Fatal(rpc.NewGRPCServer(&rpc.Server{Store: store}).Serve(listener))
+	}()
 
 	log.Fatal(http.ListenAndServe(":8080", route))
 }